@@ -3,21 +3,27 @@ package main
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"alpha-hygiene-backend/config"
 	_ "alpha-hygiene-backend/docs"
 	"alpha-hygiene-backend/internal/aggregator"
+	"alpha-hygiene-backend/internal/apikey"
 	"alpha-hygiene-backend/internal/cache"
 	"alpha-hygiene-backend/internal/checker"
 	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
 	"alpha-hygiene-backend/internal/middleware"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
 	"alpha-hygiene-backend/pkg/logger"
 
 	"github.com/gin-gonic/gin"
@@ -42,10 +48,15 @@ import (
 // @host localhost:8080
 // @BasePath /
 func main() {
+	configFlag := flag.String("config", "", "path to config file (default: $WNS_CONFIG or config/config.yaml)")
+	flag.Parse()
+
 	// Инициализация конфигурации
-	cfg, err := config.Load()
+	configPath := config.ResolvePath(*configFlag)
+	cfg, err := config.LoadFrom(configPath)
 	if err != nil {
-		panic(err)
+		fmt.Fprintf(os.Stderr, "Failed to load configuration: %v\n", err)
+		os.Exit(1)
 	}
 
 	// Инициализация логирования
@@ -54,25 +65,95 @@ func main() {
 		panic(err)
 	}
 
+	// Реестр доверенных и заблокированных адресов (встроенный список + registry.files + whitelist.contracts)
+	addressRegistry, err := registry.New(cfg, log.WithContext(&gin.Context{}))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load address registry: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Адреса кошельков и хэши транзакций не пишутся в логи как есть
+	privacy, err := logger.UsePrivacy(log, privacyPolicy(cfg, addressRegistry))
+	if err != nil {
+		panic(err)
+	}
+
 	log.Info("Application starting up")
 
+	// Веса скоринга, whitelist и лимиты запросов перечитываются по SIGHUP и при изменении файла
+	configWatcher := config.NewWatcher(configPath, cfg,
+		time.Duration(cfg.App.ConfigReloadSeconds)*time.Second, log.WithContext(&gin.Context{}))
+	configWatcher.OnReload(func(cfg *config.Config) {
+		addressRegistry.SetExtra(cfg.WhitelistContracts())
+		if err := addressRegistry.Reload(); err != nil {
+			log.Errorf("Registry reload failed, keeping current registry: %v", err)
+		}
+	})
+	watchCtx, stopWatch := context.WithCancel(context.Background())
+	defer stopWatch()
+	go configWatcher.Run(watchCtx)
+	go addressRegistry.Run(watchCtx, time.Duration(cfg.Registry.ReloadSeconds)*time.Second)
+
 	// Инициализация провайдеров
 	goplusClient := provider.NewGoPlusClient(cfg, log.WithContext(&gin.Context{}))
 	etherscanClient := provider.NewEtherscanClient(cfg, log.WithContext(&gin.Context{}))
 	alchemyClient := provider.NewAlchemyClient(cfg, log.WithContext(&gin.Context{}))
+	priceClient := provider.NewPriceClient(cfg, log.WithContext(&gin.Context{}))
 
-	// Инициализация Redis кэша
-	var redisCache cache.Cache
-	redisCache, err = cache.NewRedisCache(cfg, log.WithContext(&gin.Context{}))
-	if err != nil {
-		log.Warnf("Failed to initialize Redis cache: %v. Cache will not be available.", err)
+	// Собственный узел необязателен - используется, если выбран в sources
+	var nodeClient *provider.NodeClient
+	if cfg.Node.URL != "" {
+		nodeClient, err = provider.NewNodeClient(cfg, log.WithContext(&gin.Context{}))
+		if err != nil {
+			log.Warnf("Failed to initialize node client: %v. Node data source will not be available.", err)
+		} else {
+			defer nodeClient.Close()
+		}
 	}
 
+	// Multicall для чтения состояния контрактов: через узел, если он подключен, иначе через Alchemy RPC
+	var multicallClient *provider.MulticallClient
+	if nodeClient != nil {
+		multicallClient = nodeClient.Multicall()
+	} else if rpcURL := alchemyClient.RPCURL(); rpcURL != "" {
+		multicallClient, err = provider.NewMulticallClient(rpcURL, cfg, log.WithContext(&gin.Context{}))
+		if err != nil {
+			log.Warnf("Failed to initialize multicall client: %v. Contract state checks will not be available.", err)
+		} else {
+			defer multicallClient.Close()
+		}
+	}
+
+	// Инициализация кэша: in-process LRU перед Redis. Если Redis недоступен,
+	// кэш работает на первом уровне и переподключается в фоне.
+	cacheStore := cache.NewTieredStore(cfg, log.WithContext(&gin.Context{}))
+	defer cacheStore.Close()
+	reportCache := cache.NewReportCache(cacheStore, cfg, log.WithContext(&gin.Context{}))
+
+	// Данные провайдеров кэшируются отдельно от отчетов и переиспользуются между кошельками
+	goplusClient.UseCache(cacheStore)
+	alchemyClient.UseCache(cacheStore)
+	priceClient.UseCache(cacheStore)
+	alchemyClient.UseRegistry(addressRegistry)
+
+	// Описания спендеров, токенов и контрагентов в отчетах (nil, если labels.enabled = false)
+	labeler := labels.New(addressRegistry, etherscanClient, cfg, log.WithContext(&gin.Context{}))
+	labeler.UseCache(cacheStore)
+
 	// Инициализация фабрики проверок
-	checkerFactory := checker.NewFactory(cfg, goplusClient, etherscanClient, alchemyClient, log.WithContext(&gin.Context{}))
+	checkerFactory := checker.NewFactory(cfg, checker.Providers{
+		GoPlus:    goplusClient,
+		Etherscan: etherscanClient,
+		Alchemy:   alchemyClient,
+		Prices:    priceClient,
+		Node:      nodeClient,
+		Multicall: multicallClient,
+		Registry:  addressRegistry,
+		Labeler:   labeler,
+	}, log.WithContext(&gin.Context{}))
 
 	// Инициализация агрегатора
-	aggregatorService := aggregator.NewService(cfg, checkerFactory, redisCache, log.WithContext(&gin.Context{}))
+	aggregatorService := aggregator.NewService(cfg, checkerFactory, reportCache, log.WithContext(&gin.Context{}))
 
 	// Настройка Gin
 	if cfg.App.LogLevel == "debug" {
@@ -88,7 +169,7 @@ func main() {
 		logEntry := map[string]interface{}{
 			"time":    param.TimeStamp.Format(time.RFC3339),
 			"method":  param.Method,
-			"path":    param.Path,
+			"path":    privacy.Sanitize(param.Path),
 			"status":  param.StatusCode,
 			"latency": param.Latency.String(),
 			"error":   param.ErrorMessage,
@@ -103,14 +184,28 @@ func main() {
 
 	// Rate Limiter middleware
 	if cfg.App.RateLimit.Enabled {
-		log.Infof("Rate limiting enabled: %d requests per %d seconds",
-			cfg.App.RateLimit.Requests, cfg.App.RateLimit.Window)
+		log.Infof("Rate limiting enabled: %d requests per %d seconds (backend: %s)",
+			cfg.App.RateLimit.Requests, cfg.App.RateLimit.Window, cfg.App.RateLimit.Backend)
+		// In-memory limiter is used directly or as a fallback while Redis is unavailable
 		rl := middleware.NewRateLimiter(cfg, log)
-		r.Use(rl.RateLimitMiddleware())
+		setLimit := rl.SetLimit
+		if cfg.App.RateLimit.Backend == config.RateLimitBackendRedis {
+			redisLimiter := middleware.NewRedisRateLimiter(cfg, rl, log)
+			defer redisLimiter.Close()
+			r.Use(redisLimiter.RateLimitMiddleware())
+			setLimit = redisLimiter.SetLimit
+		} else {
+			r.Use(rl.RateLimitMiddleware())
+		}
+		configWatcher.OnReload(func(cfg *config.Config) {
+			settings := cfg.RateLimitSettings()
+			setLimit(settings.Requests, time.Duration(settings.Window)*time.Second)
+		})
 
-		// Periodically clear expired rate limit entries (every window duration)
+		// Periodically clear expired rate limit entries (every window duration at startup)
+		cleanupInterval := rl.Window
 		go func() {
-			ticker := time.NewTicker(rl.Window)
+			ticker := time.NewTicker(cleanupInterval)
 			defer ticker.Stop()
 
 			for range ticker.C {
@@ -119,13 +214,36 @@ func main() {
 		}()
 	}
 
+	// API ключи: при включении /api/check требует X-API-Key и списывает квоту тарифа
+	var keyManager *apikey.Manager
+	if cfg.APIKeys.Enabled {
+		keyManager, err = apikey.NewManager(cfg, log.WithContext(&gin.Context{}))
+		if err != nil {
+			log.Fatalf("Failed to initialize API keys: %v", err)
+		}
+		defer keyManager.Close()
+		log.Infof("API keys enabled (backend: %s)", cfg.APIKeys.Backend)
+	}
+
 	// Swagger endpoint
 	// url := ginSwagger.URL("http://localhost:8080/swagger/doc.json")
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Обработчики
 	r.GET("/health", healthCheckHandler(log))
-	r.POST("/api/check", checkWalletHandler(aggregatorService, log))
+	if keyManager != nil {
+		r.POST("/api/check", middleware.APIKeyAuth(keyManager, log), middleware.ScanQuota(keyManager, log), checkWalletHandler(aggregatorService, log))
+	} else {
+		r.POST("/api/check", checkWalletHandler(aggregatorService, log))
+	}
+	r.DELETE("/api/cache/:address", middleware.AdminAuth(cfg, log), invalidateCacheHandler(aggregatorService, log))
+
+	if keyManager != nil {
+		admin := r.Group("/api/admin", middleware.AdminAuth(cfg, log))
+		admin.POST("/keys", issueKeyHandler(keyManager, log))
+		admin.GET("/keys", listKeysHandler(keyManager, log))
+		admin.DELETE("/keys/:id", revokeKeyHandler(keyManager, log))
+	}
 
 	// Запуск сервера
 	server := &http.Server{
@@ -161,6 +279,35 @@ func main() {
 	log.Info("Server stopped")
 }
 
+// privacyPolicy - Политика обработки адресов в логах из секции logging конфигурации;
+// доверенные адреса реестра пишутся как есть
+func privacyPolicy(cfg *config.Config, reg *registry.Registry) logger.PrivacyPolicy {
+	mode := cfg.Logging.Privacy
+	if mode == "" {
+		mode = logger.PrivacyOff
+	}
+	secret := cfg.Logging.HMACKey
+	if strings.HasPrefix(secret, "USE-") {
+		secret = ""
+	}
+
+	allowlist := make(map[string]struct{}, len(cfg.Logging.Allowlist))
+	for _, addr := range cfg.Logging.Allowlist {
+		allowlist[strings.ToLower(addr)] = struct{}{}
+	}
+
+	return logger.PrivacyPolicy{
+		Mode:   mode,
+		Secret: secret,
+		Allowed: func(address string) bool {
+			if _, ok := allowlist[strings.ToLower(address)]; ok {
+				return true
+			}
+			return reg.IsAllowed(address)
+		},
+	}
+}
+
 // healthCheckHandler - Проверка статуса сервиса
 // @Summary Health check
 // @Description Check if the service is running
@@ -180,6 +327,8 @@ func healthCheckHandler(log *logrus.Logger) gin.HandlerFunc {
 // CheckWalletRequest - Запрос на проверку кошелька
 type CheckWalletRequest struct {
 	Address string `json:"address" validate:"required,eth_addr" example:"0x0000db5c8B030ae20308ac975898E09741e70000"`
+	// ForceRefresh - Пересобрать отчет, не используя кэш (то же, что заголовок Cache-Control: no-cache)
+	ForceRefresh bool `json:"force_refresh,omitempty" example:"false"`
 }
 
 // CheckWalletResponse - Ответ с результатом проверки кошелька
@@ -194,8 +343,12 @@ type CheckWalletResponse struct {
 // @Accept  json
 // @Produce  json
 // @Param request body CheckWalletRequest true "Wallet address to check"
+// @Param Cache-Control header string false "no-cache to bypass cached report"
+// @Param X-API-Key header string false "API key (required when api_keys.enabled)"
 // @Success 200 {object} CheckWalletResponse
 // @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 429 {object} map[string]string
 // @Failure 500 {object} map[string]string
 // @Router /api/check [post]
 func checkWalletHandler(service *aggregator.Service, log *logrus.Logger) gin.HandlerFunc {
@@ -219,8 +372,12 @@ func checkWalletHandler(service *aggregator.Service, log *logrus.Logger) gin.Han
 			return
 		}
 
+		opts := aggregator.CheckOptions{
+			ForceRefresh: req.ForceRefresh || hasNoCacheDirective(c.GetHeader("Cache-Control")),
+		}
+
 		ctx := c.Request.Context()
-		report, err := service.CheckWallet(ctx, req.Address)
+		report, err := service.CheckWalletWithOptions(ctx, req.Address, opts)
 		if err != nil {
 			log.Errorf("Check wallet failed: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{
@@ -235,6 +392,164 @@ func checkWalletHandler(service *aggregator.Service, log *logrus.Logger) gin.Han
 	}
 }
 
+// invalidateCacheHandler - Удаление кэшированного отчета о кошельке и результатов его проверок
+// @Summary Invalidate cached wallet report
+// @Description Remove cached report and per-check results so the next check rebuilds it from scratch (admin only). Other replicas drop their in-process copy within cache.memory_ttl_seconds.
+// @Tags admin
+// @Produce  json
+// @Param address path string true "Wallet address"
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {object} map[string]string
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 503 {object} map[string]string
+// @Router /api/cache/{address} [delete]
+func invalidateCacheHandler(service *aggregator.Service, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		address := c.Param("address")
+		if err := validateAddress(address); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		}
+
+		if err := service.InvalidateWallet(c.Request.Context(), address); err != nil {
+			log.Errorf("Cache invalidation failed: %v", err)
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error": "failed to invalidate cache",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "invalidated",
+		})
+	}
+}
+
+// IssueKeyRequest - Запрос на выдачу API ключа
+type IssueKeyRequest struct {
+	Owner string `json:"owner" binding:"required" example:"partner-exchange"`
+	Tier  string `json:"tier" binding:"required" example:"pro"`
+}
+
+// IssueKeyResponse - Выданный API ключ. Значение api_key показывается только один раз.
+type IssueKeyResponse struct {
+	APIKey string `json:"api_key" example:"wns_3f9a..."`
+	*apikey.Key
+}
+
+// issueKeyHandler - Выдача API ключа
+// @Summary Issue API key
+// @Description Issue a new API key for the given tier (admin only). The key is returned only once.
+// @Tags admin
+// @Accept  json
+// @Produce  json
+// @Param request body IssueKeyRequest true "Key owner and tier"
+// @Param Authorization header string true "Bearer admin token"
+// @Success 201 {object} IssueKeyResponse
+// @Failure 400 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/keys [post]
+func issueKeyHandler(manager *apikey.Manager, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req IssueKeyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "invalid request format",
+			})
+			return
+		}
+
+		rawKey, key, err := manager.Issue(c.Request.Context(), req.Owner, req.Tier)
+		if errors.Is(err, apikey.ErrUnknownTier) {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": err.Error(),
+			})
+			return
+		} else if err != nil {
+			log.Errorf("Failed to issue API key: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to issue API key",
+			})
+			return
+		}
+
+		key.Hash = ""
+		c.JSON(http.StatusCreated, IssueKeyResponse{APIKey: rawKey, Key: key})
+	}
+}
+
+// listKeysHandler - Список API ключей с использованием за сутки
+// @Summary List API keys
+// @Description List issued API keys with today's usage (admin only)
+// @Tags admin
+// @Produce  json
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {array} apikey.KeyUsage
+// @Failure 401 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/keys [get]
+func listKeysHandler(manager *apikey.Manager, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		keys, err := manager.List(c.Request.Context())
+		if err != nil {
+			log.Errorf("Failed to list API keys: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to list API keys",
+			})
+			return
+		}
+		c.JSON(http.StatusOK, keys)
+	}
+}
+
+// revokeKeyHandler - Отзыв API ключа
+// @Summary Revoke API key
+// @Description Revoke an API key by id (admin only)
+// @Tags admin
+// @Produce  json
+// @Param id path string true "API key id"
+// @Param Authorization header string true "Bearer admin token"
+// @Success 200 {object} map[string]string
+// @Failure 401 {object} map[string]string
+// @Failure 404 {object} map[string]string
+// @Failure 500 {object} map[string]string
+// @Router /api/admin/keys/{id} [delete]
+func revokeKeyHandler(manager *apikey.Manager, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		err := manager.Revoke(c.Request.Context(), c.Param("id"))
+		if errors.Is(err, apikey.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{
+				"error": err.Error(),
+			})
+			return
+		} else if err != nil {
+			log.Errorf("Failed to revoke API key: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": "failed to revoke API key",
+			})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{
+			"status": "revoked",
+		})
+	}
+}
+
+// hasNoCacheDirective - Есть ли в Cache-Control директива no-cache
+func hasNoCacheDirective(header string) bool {
+	for _, directive := range strings.Split(header, ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-cache") {
+			return true
+		}
+	}
+	return false
+}
+
 // validateAddress - Валидация Ethereum адреса
 func validateAddress(address string) error {
 	validate := validator.New()
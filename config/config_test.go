@@ -0,0 +1,138 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testConfigYAML = `
+app:
+  port: 8080
+  log_level: "info"
+  rate_limit:
+    enabled: true
+    requests: 100
+    window_seconds: 60
+scoring:
+  base_score: 100
+  weights:
+    approvals: 0.4
+` + otherWeightsYAML
+
+// otherWeightsYAML - Веса всех проверок, кроме approvals
+const otherWeightsYAML = `    scam_tokens: 0.2
+    asset_ratio: 0.1
+    dead_nft: 0.1
+    stale_approvals: 0.1
+    address_poisoning: 0.1
+    eip7702_delegation: 0.3
+    sanctions_exposure: 0.3
+    safe_config: 0.3
+    lending_health: 0.3
+`
+
+func writeConfig(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func TestLoadFrom_ShippedConfigWithEnvOverrides(t *testing.T) {
+	t.Setenv("WNS_APP_RATE_LIMIT_REQUESTS", "5")
+	t.Setenv("WNS_SCORING_WEIGHTS_APPROVALS", "0.5")
+	t.Setenv("WNS_WHITELIST_CONTRACTS", "0xdac17f958d2ee523a2206206994597c13d831ec7, 0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	t.Setenv("WNS_API_KEYS_TIERS_PRO_SCANS_PER_DAY", "7")
+	t.Setenv("WNS_MULTICALL_ADDRESSES_1337", "0xcA11bde05977b3631167028862bE2a173976CA11")
+	t.Setenv("WNS_ETHERSCAN_PAGE_SIZE", "250")
+	t.Setenv("WNS_GOPLUS_TOKEN_SECURITY_BATCH_SIZE", "20")
+
+	cfg, err := LoadFrom("config.yaml")
+	require.NoError(t, err)
+
+	assert.Equal(t, 5, cfg.App.RateLimit.Requests)
+	assert.Equal(t, 0.5, cfg.Weight("approvals"))
+	assert.Equal(t, 0.1, cfg.Weight("dead_nft"))
+	assert.Len(t, cfg.WhitelistContracts(), 2)
+	assert.Equal(t, 7, cfg.APIKeys.Tiers["pro"].ScansPerDay)
+	assert.Equal(t, "0xcA11bde05977b3631167028862bE2a173976CA11", cfg.Multicall.Addresses[1337])
+	assert.Equal(t, 250, cfg.Etherscan.PageSize)
+	assert.Equal(t, 20, cfg.GoPlus.TokenSecurityBatchSize)
+
+	t.Setenv("WNS_APP_PORT", "not-a-number")
+	_, err = LoadFrom("config.yaml")
+	assert.ErrorContains(t, err, "WNS_APP_PORT")
+}
+
+func TestLoadFrom_ReportsAllValidationErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, `
+app:
+  port: 8080
+  log_level: "verbose"
+  rate_limit:
+    enabled: true
+    requests: 0
+    window_seconds: 60
+sources:
+  balances: "moralis"
+scoring:
+  base_score: 100
+  weights:
+    approvals: 0.4
+`)
+
+	_, err := LoadFrom(path)
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "app.log_level")
+	assert.ErrorContains(t, err, "app.rate_limit.requests")
+	assert.ErrorContains(t, err, `sources.balances: unknown value "moralis"`)
+	assert.ErrorContains(t, err, "scoring.weights.lending_health: weight is required")
+	assert.NotContains(t, err.Error(), "scoring.weights.approvals")
+}
+
+func TestWatcher_ReloadsOnlyValidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	writeConfig(t, path, testConfigYAML)
+	cfg, err := LoadFrom(path)
+	require.NoError(t, err)
+
+	watcher := NewWatcher(path, cfg, 0, logrus.NewEntry(logrus.New()))
+	var reloaded int
+	watcher.OnReload(func(cfg *Config) { reloaded++ })
+
+	writeConfig(t, path, `
+app:
+  port: 9090
+  log_level: "info"
+  rate_limit:
+    enabled: true
+    requests: 10
+    window_seconds: 1
+scoring:
+  base_score: 80
+  weights:
+    approvals: 0.9
+`+otherWeightsYAML)
+	require.NoError(t, watcher.Reload())
+	assert.Equal(t, 1, reloaded)
+	assert.Equal(t, 0.9, cfg.Weight("approvals"))
+	assert.Equal(t, 80.0, cfg.BaseScore())
+	assert.Equal(t, 10, cfg.RateLimitSettings().Requests)
+	// Порт применяется только при запуске
+	assert.Equal(t, 8080, cfg.App.Port)
+
+	// Некорректный файл не применяется
+	writeConfig(t, path, testConfigYAML+"    rug_pulls: 7\n")
+	assert.Error(t, watcher.Reload())
+	assert.Equal(t, 1, reloaded)
+	assert.Equal(t, 0.9, cfg.Weight("approvals"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	watcher.Run(ctx)
+}
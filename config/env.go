@@ -0,0 +1,147 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// envPrefix - Префикс общих переменных окружения: WNS_APP_RATE_LIMIT_REQUESTS -> app.rate_limit.requests
+const envPrefix = "WNS_"
+
+// applyEnvOverrides - Переопределяет любые поля переменными WNS_<ПУТЬ_ИЗ_YAML_ТЕГОВ>.
+// Списки задаются через запятую, элементы map - отдельными переменными с ключом в конце
+// (WNS_SCORING_WEIGHTS_APPROVALS=0.5).
+func applyEnvOverrides(config *Config) error {
+	environ := os.Environ()
+	return applyEnvToStruct(reflect.ValueOf(config).Elem(), strings.TrimSuffix(envPrefix, "_"), environ)
+}
+
+// applyEnvToStruct - Обходит поля структуры; имя поля берется из yaml тега,
+// а без тега - как в yaml.v3, имя поля в нижнем регистре
+func applyEnvToStruct(v reflect.Value, prefix string, environ []string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		if err := applyEnvToValue(v.Field(i), prefix+"_"+strings.ToUpper(name), environ); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyEnvToValue - Применяет переменную окружения к одному полю
+func applyEnvToValue(v reflect.Value, envName string, environ []string) error {
+	switch v.Kind() {
+	case reflect.Struct:
+		return applyEnvToStruct(v, envName, environ)
+	case reflect.Map:
+		return applyEnvToMap(v, envName, environ)
+	}
+
+	raw, ok := os.LookupEnv(envName)
+	if !ok {
+		return nil
+	}
+	if err := setFromString(v, raw); err != nil {
+		return fmt.Errorf("invalid value of %s: %w", envName, err)
+	}
+	return nil
+}
+
+// applyEnvToMap - Элементы map из переменных <ИМЯ>_<КЛЮЧ>; ключ приводится к нижнему регистру.
+// Поля элементов-структур задаются как <ИМЯ>_<КЛЮЧ>_<ПОЛЕ> (только для ключей из файла).
+func applyEnvToMap(v reflect.Value, envName string, environ []string) error {
+	keyType, elemType := v.Type().Key(), v.Type().Elem()
+
+	if elemType.Kind() == reflect.Struct {
+		for _, mapKey := range v.MapKeys() {
+			elem := reflect.New(elemType).Elem()
+			elem.Set(v.MapIndex(mapKey))
+			keyName := strings.ToUpper(fmt.Sprint(mapKey.Interface()))
+			if err := applyEnvToStruct(elem, envName+"_"+keyName, environ); err != nil {
+				return err
+			}
+			v.SetMapIndex(mapKey, elem)
+		}
+		return nil
+	}
+
+	for _, kv := range environ {
+		name, raw, _ := strings.Cut(kv, "=")
+		key, ok := strings.CutPrefix(name, envName+"_")
+		if !ok || key == "" {
+			continue
+		}
+
+		mapKey := reflect.New(keyType).Elem()
+		if err := setFromString(mapKey, strings.ToLower(key)); err != nil {
+			return fmt.Errorf("invalid key of %s: %w", name, err)
+		}
+		elem := reflect.New(elemType).Elem()
+		if err := setFromString(elem, raw); err != nil {
+			return fmt.Errorf("invalid value of %s: %w", name, err)
+		}
+
+		if v.IsNil() {
+			v.Set(reflect.MakeMap(v.Type()))
+		}
+		v.SetMapIndex(mapKey, elem)
+	}
+	return nil
+}
+
+// setFromString - Разбирает строку в значение поддерживаемого типа
+func setFromString(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported list type %s", v.Type())
+		}
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+	return nil
+}
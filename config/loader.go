@@ -4,43 +4,275 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	"github.com/joho/godotenv"
 	"gopkg.in/yaml.v3"
 )
 
+// Бэкенды rate limiter (app.rate_limit.backend)
+const (
+	RateLimitBackendMemory = "memory"
+	RateLimitBackendRedis  = "redis"
+)
+
+// Хранилища API ключей (api_keys.backend)
+const (
+	APIKeyBackendFile  = "file"
+	APIKeyBackendRedis = "redis"
+)
+
+// AppConfig - Параметры HTTP сервера
+type AppConfig struct {
+	Port       int             `yaml:"port"`
+	LogLevel   string          `yaml:"log_level"`
+	TimeoutSec int             `yaml:"timeout_sec"`
+	RateLimit  RateLimitConfig `yaml:"rate_limit"`
+	// ConfigReloadSeconds - Период проверки изменений файла конфигурации (0 - перезагрузка только по SIGHUP)
+	ConfigReloadSeconds int `yaml:"config_reload_seconds"`
+}
+
+// RateLimitConfig - Параметры ограничения частоты запросов
+type RateLimitConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	Requests int  `yaml:"requests"`
+	Window   int  `yaml:"window_seconds"`
+	// Backend - memory (счетчики в процессе) или redis (общий лимит для всех реплик)
+	Backend string `yaml:"backend"`
+}
+
+// APIKeyTier - Ограничения тарифа API ключа
+type APIKeyTier struct {
+	// ScansPerDay - Количество проверок кошельков в сутки (UTC), 0 - без ограничения
+	ScansPerDay int `yaml:"scans_per_day"`
+}
+
+// ScoredChecks - Ключи scoring.weights, по которым проверки берут вес штрафа (Config.Weight).
+// Новая проверка со штрафом добавляет сюда свой ключ, иначе отсутствующий вес не поймает валидация.
+var ScoredChecks = []string{
+	"approvals",
+	"scam_tokens",
+	"asset_ratio",
+	"dead_nft",
+	"stale_approvals",
+	"address_poisoning",
+	"eip7702_delegation",
+	"sanctions_exposure",
+	"safe_config",
+	"lending_health",
+}
+
+// ScoringConfig - Базовый балл и веса проверок
+type ScoringConfig struct {
+	BaseScore float64            `yaml:"base_score"`
+	Weights   map[string]float64 `yaml:"weights"`
+}
+
+// ChecksConfig - Пороги отдельных проверок
+type ChecksConfig struct {
+	StaleApprovals    StaleApprovalsConfig    `yaml:"stale_approvals"`
+	AddressPoisoning  AddressPoisoningConfig  `yaml:"address_poisoning"`
+	SanctionsExposure SanctionsExposureConfig `yaml:"sanctions_exposure"`
+	SafeConfig        SafeConfigConfig        `yaml:"safe_config"`
+	Assets            AssetsConfig            `yaml:"assets"`
+	ScamTokens        ScamTokensConfig        `yaml:"scam_tokens"`
+	Approvals         ApprovalsConfig         `yaml:"approvals"`
+	LendingHealth     LendingHealthConfig     `yaml:"lending_health"`
+}
+
+// LendingHealthConfig - Параметры проверки заемных позиций в протоколах кредитования
+type LendingHealthConfig struct {
+	// WarnHealthFactor, CriticalHealthFactor - Health factor, ниже которого позиция близка к ликвидации
+	// (риск MEDIUM и HIGH, 0 - значение по умолчанию)
+	WarnHealthFactor     float64 `yaml:"warn_health_factor"`
+	CriticalHealthFactor float64 `yaml:"critical_health_factor"`
+	// AavePools, CometMarkets - Адреса Aave v3 Pool и рынков Compound v3 (пусто - рынки Ethereum по умолчанию)
+	AavePools    []string `yaml:"aave_pools"`
+	CometMarkets []string `yaml:"comet_markets"`
+}
+
+// ApprovalsConfig - Параметры проверки approvals
+type ApprovalsConfig struct {
+	// FreshContractDays - Сколько дней после деплоя контракт спендера считается новым (0 - значение по умолчанию)
+	FreshContractDays int `yaml:"fresh_contract_days"`
+}
+
+// ScamTokensConfig - Пороги проверки скам-токенов по данным GoPlus, в процентах (0 - значение по умолчанию)
+type ScamTokensConfig struct {
+	// CreatorPercent - Доля предложения у создателя, выше которой токен считается потенциальным rug pull
+	CreatorPercent float64 `yaml:"creator_percent"`
+	// BuyTaxPercent, SellTaxPercent - Налог на покупку и продажу, выше которого токен считается скамом
+	BuyTaxPercent  float64 `yaml:"buy_tax_percent"`
+	SellTaxPercent float64 `yaml:"sell_tax_percent"`
+}
+
+// AssetsConfig - Пороги проверки состава активов (0 - значение по умолчанию)
+type AssetsConfig struct {
+	// VolatileRatioPercent - Доля волатильных активов, %, выше которой портфель считается рискованным
+	VolatileRatioPercent float64 `yaml:"volatile_ratio_percent"`
+	// MaxHHI - Индекс Херфиндаля-Хиршмана (0..1), выше которого портфель считается концентрированным
+	MaxHHI float64 `yaml:"max_hhi"`
+	// MaxSingleAssetPercent - Доля одного актива, %, выше которой он доминирует в портфеле
+	MaxSingleAssetPercent float64 `yaml:"max_single_asset_percent"`
+	// DepegPercent - Отклонение цены стейблкоина от $1, %, после которого он считается потерявшим привязку
+	DepegPercent float64 `yaml:"depeg_percent"`
+}
+
+// SafeConfigConfig - Параметры аудита конфигурации Safe multisig
+type SafeConfigConfig struct {
+	// RecentChangeDays - Сколько дней изменение владельцев или порога считается недавним (0 - значение по умолчанию)
+	RecentChangeDays int `yaml:"recent_change_days"`
+}
+
+// SanctionsExposureConfig - Параметры проверки связей с санкционными адресами и миксерами
+type SanctionsExposureConfig struct {
+	// OneHop - Проверять историю ближайших контрагентов кошелька (связь через одного посредника)
+	OneHop bool `yaml:"one_hop"`
+	// MaxHopCounterparties - Сколько самых частых контрагентов проверяется (0 - значение по умолчанию)
+	MaxHopCounterparties int `yaml:"max_hop_counterparties"`
+	// HopRecentTransfers - Сколько последних транзакций контрагента просматривается (0 - значение по умолчанию)
+	HopRecentTransfers int `yaml:"hop_recent_transfers"`
+}
+
+// StaleApprovalsConfig - Пороги проверки устаревших approvals (в днях, 0 - значение по умолчанию)
+type StaleApprovalsConfig struct {
+	// MediumAfterDays, HighAfterDays - Возраст approval, после которого риск MEDIUM и HIGH
+	MediumAfterDays int `yaml:"medium_after_days"`
+	HighAfterDays   int `yaml:"high_after_days"`
+	// UnusedAfterDays - Сколько дней без транзакций кошелька в адрес спендера считается неиспользуемым approval
+	UnusedAfterDays int `yaml:"unused_after_days"`
+}
+
+// AddressPoisoningConfig - Параметры поиска адресов-двойников (0 - значение по умолчанию)
+type AddressPoisoningConfig struct {
+	// PrefixChars, SuffixChars - Сколько первых и последних hex-символов должно совпасть с адресом контрагента
+	PrefixChars int `yaml:"prefix_chars"`
+	SuffixChars int `yaml:"suffix_chars"`
+}
+
 type Config struct {
-	App struct {
-		Port       int    `yaml:"port"`
-		LogLevel   string `yaml:"log_level"`
-		TimeoutSec int    `yaml:"timeout_sec"`
-		RateLimit  struct {
-			Enabled  bool `yaml:"enabled"`
-			Requests int  `yaml:"requests"`
-			Window   int  `yaml:"window_seconds"`
-		} `yaml:"rate_limit"`
-	} `yaml:"app"`
+	// reloadMu - Защищает секции, которые меняются при перезагрузке (см. ApplyReloadable)
+	reloadMu sync.RWMutex
+
+	App     AppConfig `yaml:"app"`
+	Logging struct {
+		// Privacy - Что делать с адресами и хэшами транзакций в логах: off, redact или hash
+		Privacy string `yaml:"privacy"`
+		// HMACKey - Ключ псевдонимизации для режима hash
+		HMACKey string `yaml:"hmac_key"`
+		// Allowlist - Адреса, которые пишутся в логи как есть (дополняют известные контракты)
+		Allowlist []string `yaml:"allowlist"`
+	} `yaml:"logging"`
 	GoPlus struct {
+		URL       string `yaml:"url"`
 		ApiKey    string `yaml:"key"`
 		ApiSecret string `yaml:"secret"`
-	}
+		// Размер батча и параллелизм запросов token_security
+		TokenSecurityBatchSize   int `yaml:"token_security_batch_size"`
+		TokenSecurityConcurrency int `yaml:"token_security_concurrency"`
+	} `yaml:"goplus"`
 	Etherscan struct {
 		URL    string `yaml:"url"`
 		ApiKey string `yaml:"key"`
-	}
+		// ChainID - Сеть для Etherscan V2 API (1 - Ethereum Mainnet)
+		ChainID int64 `yaml:"chain_id"`
+		// Размер страницы и общий лимит записей для списковых методов
+		PageSize   int `yaml:"page_size"`
+		MaxResults int `yaml:"max_results"`
+	} `yaml:"etherscan"`
 	Alchemy struct {
 		ApiKey string `yaml:"api_key"`
 		URL    string `yaml:"url"`
 	} `yaml:"alchemy"`
+	Prices struct {
+		// URL - DefiLlama coins API
+		URL string `yaml:"url"`
+		// Chain - Префикс сети в идентификаторах DefiLlama (ethereum, arbitrum, ...)
+		Chain string `yaml:"chain"`
+	} `yaml:"prices"`
+	Node struct {
+		// URL - JSON-RPC собственного (архивного) узла
+		URL string `yaml:"url"`
+		// StartBlock - С какого блока искать события Transfer/Approval
+		StartBlock uint64 `yaml:"start_block"`
+		// LogChunkSize - Размер диапазона блоков одного eth_getLogs
+		LogChunkSize uint64 `yaml:"log_chunk_size"`
+	} `yaml:"node"`
+	Multicall struct {
+		// Addresses - Адреса Multicall3 по chain id (дополняют и переопределяют встроенную таблицу)
+		Addresses map[uint64]string `yaml:"addresses"`
+		// Пределы одного batch: размер calldata, суммарный газ и оценка газа на вызов
+		MaxCalldataBytes int    `yaml:"max_calldata_bytes"`
+		MaxGasPerBatch   uint64 `yaml:"max_gas_per_batch"`
+		GasPerCall       uint64 `yaml:"gas_per_call"`
+	} `yaml:"multicall"`
+	Sources struct {
+		// Balances - Источник балансов: alchemy, etherscan или node
+		Balances string `yaml:"balances"`
+		// Approvals - Источник approvals: goplus или node
+		Approvals string `yaml:"approvals"`
+	} `yaml:"sources"`
+	Cache struct {
+		// MemoryMaxEntries - Размер in-process LRU (первый уровень кэша)
+		MemoryMaxEntries int `yaml:"memory_max_entries"`
+		// MemoryTTLSeconds - Сколько значение из Redis хранится в LRU: через это время реплика видит удаление,
+		// сделанное другой репликой
+		MemoryTTLSeconds int `yaml:"memory_ttl_seconds"`
+		// ReportTTLSeconds - Сколько отчет о кошельке считается свежим
+		ReportTTLSeconds int `yaml:"report_ttl_seconds"`
+		// ReportStaleSeconds - Сколько после этого отчет отдается как устаревший, пока обновляется в фоне
+		ReportStaleSeconds int `yaml:"report_stale_seconds"`
+		// RedisReconnectSeconds - Период проверки доступности Redis
+		RedisReconnectSeconds int `yaml:"redis_reconnect_seconds"`
+		// CheckTTLSeconds - Сроки жизни результатов отдельных проверок (по имени проверки, по умолчанию report_ttl_seconds)
+		CheckTTLSeconds map[string]int `yaml:"check_ttl_seconds"`
+		// Сроки жизни данных провайдеров (метаданные токенов хранятся бессрочно)
+		TokenSecurityTTLSeconds   int `yaml:"token_security_ttl_seconds"`
+		NFTSecurityTTLSeconds     int `yaml:"nft_security_ttl_seconds"`
+		AddressSecurityTTLSeconds int `yaml:"address_security_ttl_seconds"`
+		PriceTTLSeconds           int `yaml:"price_ttl_seconds"`
+		// LabelTTLSeconds - Срок хранения метаданных контрактов Etherscan для описаний адресов
+		LabelTTLSeconds int `yaml:"label_ttl_seconds"`
+	} `yaml:"cache"`
+	Admin struct {
+		// Token - Токен для административных эндпоинтов (Authorization: Bearer). Пустой - эндпоинты отключены
+		Token string `yaml:"token"`
+	} `yaml:"admin"`
+	APIKeys struct {
+		// Enabled - Требовать заголовок X-API-Key для /api/check
+		Enabled bool `yaml:"enabled"`
+		// Backend - file (ключи в YAML файле) или redis
+		Backend string `yaml:"backend"`
+		// File - Путь к файлу ключей для backend file
+		File string `yaml:"file"`
+		// Tiers - Тарифы по имени
+		Tiers map[string]APIKeyTier `yaml:"tiers"`
+	} `yaml:"api_keys"`
 	Redis struct {
 		Addr     string `yaml:"addr"`
 		Password string `yaml:"password"`
 		DB       int    `yaml:"db"`
 	} `yaml:"redis"`
-	Scoring struct {
-		BaseScore float64            `yaml:"base_score"`
-		Weights   map[string]float64 `yaml:"weights"`
-	} `yaml:"scoring"`
+	Registry struct {
+		// Files - Файлы реестра адресов (YAML/JSON), объединяются поверх встроенного по порядку
+		Files []string `yaml:"files"`
+		// ReloadSeconds - Период проверки изменений файлов реестра (0 - только при перезагрузке конфигурации)
+		ReloadSeconds int `yaml:"reload_seconds"`
+	} `yaml:"registry"`
+	Labels struct {
+		// Enabled - Добавлять описания спендеров, токенов и контрагентов в отчеты
+		Enabled bool `yaml:"enabled"`
+		// MaxLookups - Сколько неизвестных адресов за одну проверку досматривается через Etherscan
+		MaxLookups int `yaml:"max_lookups"`
+		// LookupConcurrency - Количество параллельных запросов getsourcecode
+		LookupConcurrency int `yaml:"lookup_concurrency"`
+	} `yaml:"labels"`
+	Whitelist struct {
+		// Contracts - Дополнительные доверенные контракты (попадают в реестр адресов с категорией custom)
+		Contracts []string `yaml:"contracts"`
+	} `yaml:"whitelist"`
+	Checks  ChecksConfig  `yaml:"checks"`
+	Scoring ScoringConfig `yaml:"scoring"`
 }
 
 func getEnv(key, defaultValue string) string {
@@ -50,13 +282,31 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// DefaultPath - Путь к конфигурации по умолчанию
+var DefaultPath = filepath.Join("config", "config.yaml")
+
+// ResolvePath - Путь к конфигурации: флаг --config, затем WNS_CONFIG, затем DefaultPath
+func ResolvePath(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+	_ = godotenv.Load()
+	return getEnv(envPrefix+"CONFIG", DefaultPath)
+}
+
+// Load - Загружает конфигурацию из пути по умолчанию (с учетом WNS_CONFIG)
 func Load() (*Config, error) {
+	return LoadFrom(ResolvePath(""))
+}
+
+// LoadFrom - Загружает и проверяет конфигурацию: YAML файл, затем переменные окружения
+// (именованные, например GOPLUS_API_KEY, и общие WNS_<СЕКЦИЯ>_<ПОЛЕ>)
+func LoadFrom(path string) (*Config, error) {
 	// Load environment variables from .env file (ignore error if file doesn't exist)
 	_ = godotenv.Load()
 
 	// Read YAML config file
-	configPath := filepath.Join("config", "config.yaml")
-	data, err := os.ReadFile(configPath)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
 	}
@@ -65,10 +315,25 @@ func Load() (*Config, error) {
 	var config Config
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	applyNamedEnv(&config)
+	if err := applyEnvOverrides(&config); err != nil {
 		return nil, err
 	}
 
-	// Override with environment variables if provided
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid configuration %s:\n%w", path, err)
+	}
+	return &config, nil
+}
+
+// applyNamedEnv - Переменные окружения с собственными именами (из .env.example)
+func applyNamedEnv(config *Config) {
+	if goplusURL := getEnv("GOPLUS_API_URL", ""); goplusURL != "" {
+		config.GoPlus.URL = goplusURL
+	}
 	if goplusKey := getEnv("GOPLUS_API_KEY", ""); goplusKey != "" {
 		config.GoPlus.ApiKey = goplusKey
 	}
@@ -87,6 +352,18 @@ func Load() (*Config, error) {
 	if alchemyURL := getEnv("ALCHEMY_API_URL", ""); alchemyURL != "" {
 		config.Alchemy.URL = alchemyURL
 	}
+	if pricesURL := getEnv("PRICES_API_URL", ""); pricesURL != "" {
+		config.Prices.URL = pricesURL
+	}
+	if nodeURL := getEnv("NODE_RPC_URL", ""); nodeURL != "" {
+		config.Node.URL = nodeURL
+	}
+	if adminToken := getEnv("ADMIN_TOKEN", ""); adminToken != "" {
+		config.Admin.Token = adminToken
+	}
+	if logHMACKey := getEnv("LOG_HMAC_KEY", ""); logHMACKey != "" {
+		config.Logging.HMACKey = logHMACKey
+	}
 	if redisAddr := getEnv("REDIS_ADDR", ""); redisAddr != "" {
 		config.Redis.Addr = redisAddr
 	}
@@ -95,11 +372,8 @@ func Load() (*Config, error) {
 	}
 	if redisDB := getEnv("REDIS_DB", ""); redisDB != "" {
 		var db int
-		_, err = fmt.Sscanf(redisDB, "%d", &db)
-		if err == nil {
+		if _, err := fmt.Sscanf(redisDB, "%d", &db); err == nil {
 			config.Redis.DB = db
 		}
 	}
-
-	return &config, nil
 }
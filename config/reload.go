@@ -0,0 +1,148 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Weight - Вес проверки в итоговом балле (учитывает перезагрузку конфигурации)
+func (c *Config) Weight(check string) float64 {
+	c.reloadMu.RLock()
+	defer c.reloadMu.RUnlock()
+	return c.Scoring.Weights[check]
+}
+
+// BaseScore - Базовый балл (учитывает перезагрузку конфигурации)
+func (c *Config) BaseScore() float64 {
+	c.reloadMu.RLock()
+	defer c.reloadMu.RUnlock()
+	return c.Scoring.BaseScore
+}
+
+// RateLimitSettings - Текущие параметры ограничения запросов
+func (c *Config) RateLimitSettings() RateLimitConfig {
+	c.reloadMu.RLock()
+	defer c.reloadMu.RUnlock()
+	return c.App.RateLimit
+}
+
+// WhitelistContracts - Текущий список дополнительных доверенных контрактов
+func (c *Config) WhitelistContracts() []string {
+	c.reloadMu.RLock()
+	defer c.reloadMu.RUnlock()
+	return append([]string(nil), c.Whitelist.Contracts...)
+}
+
+// ApplyReloadable - Переносит из next секции, которые можно менять без перезапуска:
+// веса скоринга, белый список и лимиты запросов (включение и backend лимитера - только при старте)
+func (c *Config) ApplyReloadable(next *Config) {
+	next.reloadMu.RLock()
+	defer next.reloadMu.RUnlock()
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	c.Scoring = next.Scoring
+	c.Whitelist = next.Whitelist
+	c.App.RateLimit.Requests = next.App.RateLimit.Requests
+	c.App.RateLimit.Window = next.App.RateLimit.Window
+}
+
+// Watcher - Перезагружает конфигурацию по SIGHUP и при изменении файла
+type Watcher struct {
+	path     string
+	cfg      *Config
+	interval time.Duration
+	modTime  time.Time
+	// mu - Сериализует перезагрузки и защищает listeners
+	mu        sync.Mutex
+	listeners []func(cfg *Config)
+	log       *logrus.Entry
+}
+
+// NewWatcher - Создает наблюдателя за файлом конфигурации.
+// interval - период проверки времени изменения файла (0 - только SIGHUP).
+func NewWatcher(path string, cfg *Config, interval time.Duration, log *logrus.Entry) *Watcher {
+	w := &Watcher{
+		path:     path,
+		cfg:      cfg,
+		interval: interval,
+		log:      log.WithFields(logrus.Fields{"component": "config"}),
+	}
+	if info, err := os.Stat(path); err == nil {
+		w.modTime = info.ModTime()
+	}
+	return w
+}
+
+// OnReload - Регистрирует обработчик, вызываемый после успешной перезагрузки
+func (w *Watcher) OnReload(fn func(cfg *Config)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.listeners = append(w.listeners, fn)
+}
+
+// Reload - Перечитывает файл; при ошибке валидации текущая конфигурация остается без изменений
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := LoadFrom(w.path)
+	if err != nil {
+		w.log.Errorf("Config reload failed, keeping current configuration: %v", err)
+		return err
+	}
+
+	w.cfg.ApplyReloadable(next)
+	for _, fn := range w.listeners {
+		fn(w.cfg)
+	}
+	w.log.Infof("Configuration reloaded from %s", w.path)
+	return nil
+}
+
+// Run - Ждет SIGHUP и следит за файлом до отмены контекста
+func (w *Watcher) Run(ctx context.Context) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var tick <-chan time.Time
+	if w.interval > 0 {
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			w.log.Info("Received SIGHUP, reloading configuration")
+			_ = w.Reload()
+		case <-tick:
+			if w.fileChanged() {
+				_ = w.Reload()
+			}
+		}
+	}
+}
+
+// fileChanged - Изменилось ли время модификации файла с прошлой проверки
+func (w *Watcher) fileChanged() bool {
+	info, err := os.Stat(w.path)
+	if err != nil {
+		return false
+	}
+	if info.ModTime().Equal(w.modTime) {
+		return false
+	}
+	w.modTime = info.ModTime()
+	return true
+}
@@ -0,0 +1,183 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// addressPattern - EVM адрес
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Validate - Проверяет конфигурацию и возвращает все найденные ошибки сразу
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+	// oneOf - Пустое значение означает значение по умолчанию и всегда допустимо
+	oneOf := func(field, value string, allowed ...string) {
+		if value == "" || slices.Contains(allowed, value) {
+			return
+		}
+		fail("%s: unknown value %q (allowed: %s)", field, value, strings.Join(allowed, ", "))
+	}
+
+	if c.App.Port <= 0 || c.App.Port > 65535 {
+		fail("app.port: must be between 1 and 65535, got %d", c.App.Port)
+	}
+	if _, err := logrus.ParseLevel(c.App.LogLevel); err != nil {
+		fail("app.log_level: %v", err)
+	}
+	if c.App.TimeoutSec < 0 {
+		fail("app.timeout_sec: must not be negative")
+	}
+	if c.App.RateLimit.Enabled {
+		if c.App.RateLimit.Requests <= 0 {
+			fail("app.rate_limit.requests: must be > 0 when rate limiting is enabled")
+		}
+		if c.App.RateLimit.Window <= 0 {
+			fail("app.rate_limit.window_seconds: must be > 0 when rate limiting is enabled")
+		}
+		oneOf("app.rate_limit.backend", c.App.RateLimit.Backend, RateLimitBackendMemory, RateLimitBackendRedis)
+	}
+
+	oneOf("logging.privacy", c.Logging.Privacy, "off", "redact", "hash")
+	if c.Logging.Privacy == "hash" && (c.Logging.HMACKey == "" || strings.HasPrefix(c.Logging.HMACKey, "USE-")) {
+		fail("logging.hmac_key: required for privacy mode hash (set LOG_HMAC_KEY)")
+	}
+	for _, addr := range c.Logging.Allowlist {
+		if !addressPattern.MatchString(addr) {
+			fail("logging.allowlist: invalid address %q", addr)
+		}
+	}
+
+	oneOf("sources.balances", c.Sources.Balances, "alchemy", "etherscan", "node")
+	oneOf("sources.approvals", c.Sources.Approvals, "goplus", "node")
+	if (c.Sources.Balances == "node" || c.Sources.Approvals == "node") && c.Node.URL == "" {
+		fail("node.url: required when a source is set to node (set NODE_RPC_URL)")
+	}
+	for chainID, addr := range c.Multicall.Addresses {
+		if !addressPattern.MatchString(addr) {
+			fail("multicall.addresses.%d: invalid address %q", chainID, addr)
+		}
+	}
+
+	if c.Cache.MemoryTTLSeconds < 0 {
+		fail("cache.memory_ttl_seconds: must not be negative")
+	}
+	for check, ttl := range c.Cache.CheckTTLSeconds {
+		if ttl < 0 {
+			fail("cache.check_ttl_seconds.%s: must not be negative", check)
+		}
+	}
+
+	if c.APIKeys.Enabled {
+		oneOf("api_keys.backend", c.APIKeys.Backend, APIKeyBackendFile, APIKeyBackendRedis)
+		if c.APIKeys.Backend != APIKeyBackendRedis && c.APIKeys.File == "" {
+			fail("api_keys.file: required for backend file")
+		}
+		if len(c.APIKeys.Tiers) == 0 {
+			fail("api_keys.tiers: at least one tier is required")
+		}
+		for name, tier := range c.APIKeys.Tiers {
+			if tier.ScansPerDay < 0 {
+				fail("api_keys.tiers.%s.scans_per_day: must not be negative", name)
+			}
+		}
+	}
+
+	usesRedis := c.App.RateLimit.Backend == RateLimitBackendRedis ||
+		(c.APIKeys.Enabled && c.APIKeys.Backend == APIKeyBackendRedis)
+	if usesRedis && c.Redis.Addr == "" {
+		fail("redis.addr: required by the redis backend")
+	}
+
+	for i, path := range c.Registry.Files {
+		if path == "" {
+			fail("registry.files.%d: path must not be empty", i)
+		}
+	}
+	if c.Labels.MaxLookups < 0 || c.Labels.LookupConcurrency < 0 {
+		fail("labels: max_lookups and lookup_concurrency must not be negative")
+	}
+	for _, addr := range c.Whitelist.Contracts {
+		if !addressPattern.MatchString(addr) {
+			fail("whitelist.contracts: invalid address %q", addr)
+		}
+	}
+
+	stale := c.Checks.StaleApprovals
+	if stale.MediumAfterDays < 0 || stale.HighAfterDays < 0 || stale.UnusedAfterDays < 0 {
+		fail("checks.stale_approvals: thresholds must not be negative")
+	}
+	if stale.MediumAfterDays > 0 && stale.HighAfterDays > 0 && stale.HighAfterDays < stale.MediumAfterDays {
+		fail("checks.stale_approvals.high_after_days: must not be less than medium_after_days")
+	}
+
+	poisoning := c.Checks.AddressPoisoning
+	if poisoning.PrefixChars < 0 || poisoning.SuffixChars < 0 || poisoning.PrefixChars+poisoning.SuffixChars > 40 {
+		fail("checks.address_poisoning: prefix_chars and suffix_chars must be between 0 and 40 in total")
+	}
+
+	if c.Checks.SanctionsExposure.MaxHopCounterparties < 0 || c.Checks.SanctionsExposure.HopRecentTransfers < 0 {
+		fail("checks.sanctions_exposure: max_hop_counterparties and hop_recent_transfers must not be negative")
+	}
+
+	assets := c.Checks.Assets
+	if assets.VolatileRatioPercent < 0 || assets.VolatileRatioPercent > 100 || assets.MaxSingleAssetPercent < 0 || assets.MaxSingleAssetPercent > 100 {
+		fail("checks.assets: volatile_ratio_percent and max_single_asset_percent must be between 0 and 100")
+	}
+	if assets.MaxHHI < 0 || assets.MaxHHI > 1 {
+		fail("checks.assets.max_hhi: must be between 0 and 1")
+	}
+	if assets.DepegPercent < 0 {
+		fail("checks.assets.depeg_percent: must not be negative")
+	}
+
+	if c.Checks.Approvals.FreshContractDays < 0 {
+		fail("checks.approvals.fresh_contract_days: must not be negative")
+	}
+
+	scam := c.Checks.ScamTokens
+	if scam.CreatorPercent < 0 || scam.CreatorPercent > 100 || scam.BuyTaxPercent < 0 || scam.BuyTaxPercent > 100 || scam.SellTaxPercent < 0 || scam.SellTaxPercent > 100 {
+		fail("checks.scam_tokens: creator_percent, buy_tax_percent and sell_tax_percent must be between 0 and 100")
+	}
+
+	if c.Checks.SafeConfig.RecentChangeDays < 0 {
+		fail("checks.safe_config.recent_change_days: must not be negative")
+	}
+
+	lending := c.Checks.LendingHealth
+	if (lending.WarnHealthFactor != 0 && lending.WarnHealthFactor < 1) || (lending.CriticalHealthFactor != 0 && lending.CriticalHealthFactor < 1) {
+		fail("checks.lending_health: warn_health_factor and critical_health_factor must be at least 1")
+	}
+	if lending.WarnHealthFactor > 0 && lending.CriticalHealthFactor > 0 && lending.CriticalHealthFactor > lending.WarnHealthFactor {
+		fail("checks.lending_health.critical_health_factor: must not be greater than warn_health_factor")
+	}
+	for _, addr := range append(append([]string(nil), lending.AavePools...), lending.CometMarkets...) {
+		if !addressPattern.MatchString(addr) {
+			fail("checks.lending_health: invalid market address %q", addr)
+		}
+	}
+
+	if c.Scoring.BaseScore <= 0 {
+		fail("scoring.base_score: must be > 0")
+	}
+	for _, check := range ScoredChecks {
+		if _, ok := c.Scoring.Weights[check]; !ok {
+			fail("scoring.weights.%s: weight is required", check)
+		}
+	}
+	for check, weight := range c.Scoring.Weights {
+		if weight < 0 || weight > 1 {
+			fail("scoring.weights.%s: must be between 0 and 1, got %g", check, weight)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -24,6 +24,254 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/admin/keys": {
+            "get": {
+                "description": "List issued API keys with today's usage (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "List API keys",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/apikey.KeyUsage"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Issue a new API key for the given tier (admin only). The key is returned only once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Issue API key",
+                "parameters": [
+                    {
+                        "description": "Key owner and tier",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/main.IssueKeyRequest"
+                        }
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/main.IssueKeyResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/admin/keys/{id}": {
+            "delete": {
+                "description": "Revoke an API key by id (admin only)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Revoke API key",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "API key id",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/api/cache/{address}": {
+            "delete": {
+                "description": "Remove cached report and per-check results so the next check rebuilds it from scratch (admin only). Other replicas drop their in-process copy within cache.memory_ttl_seconds.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "admin"
+                ],
+                "summary": "Invalidate cached wallet report",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Wallet address",
+                        "name": "address",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bearer admin token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "503": {
+                        "description": "Service Unavailable",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
         "/api/check": {
             "post": {
                 "description": "Check wallet security and get nutrition score",
@@ -46,6 +294,18 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/main.CheckWalletRequest"
                         }
+                    },
+                    {
+                        "type": "string",
+                        "description": "no-cache to bypass cached report",
+                        "name": "Cache-Control",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "API key (required when api_keys.enabled)",
+                        "name": "X-API-Key",
+                        "in": "header"
                     }
                 ],
                 "responses": {
@@ -64,6 +324,24 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "429": {
+                        "description": "Too Many Requests",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -104,12 +382,79 @@ const docTemplate = `{
         }
     },
     "definitions": {
+        "apikey.KeyUsage": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string"
+                },
+                "used_today": {
+                    "type": "integer"
+                }
+            }
+        },
+        "entity.AddressLabel": {
+            "type": "object",
+            "properties": {
+                "address": {
+                    "type": "string"
+                },
+                "category": {
+                    "description": "Category, List - Категория и список (allow/block) из реестра адресов",
+                    "type": "string"
+                },
+                "deployed_at": {
+                    "type": "string"
+                },
+                "is_contract": {
+                    "type": "boolean"
+                },
+                "label": {
+                    "type": "string"
+                },
+                "list": {
+                    "type": "string"
+                },
+                "source": {
+                    "$ref": "#/definitions/entity.LabelSource"
+                },
+                "verified": {
+                    "type": "boolean"
+                }
+            }
+        },
         "entity.CheckResult": {
             "type": "object",
             "properties": {
+                "cached_at": {
+                    "description": "CachedAt - Когда результат был сохранен в кэш (для Source = cache)",
+                    "type": "string"
+                },
                 "check_name": {
                     "type": "string"
                 },
+                "counterparties": {
+                    "description": "Counterparties - Описания адресов, упомянутых в результате проверки",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.AddressLabel"
+                    }
+                },
                 "details": {
                     "type": "string"
                 },
@@ -122,9 +467,43 @@ const docTemplate = `{
                 },
                 "score_penalty": {
                     "type": "number"
+                },
+                "source": {
+                    "description": "Source - Откуда взят результат: выполнен сейчас или из кэша проверок",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/entity.CheckSource"
+                        }
+                    ]
                 }
             }
         },
+        "entity.CheckSource": {
+            "type": "string",
+            "enum": [
+                "fresh",
+                "cache"
+            ],
+            "x-enum-varnames": [
+                "CheckSourceFresh",
+                "CheckSourceCache"
+            ]
+        },
+        "entity.LabelSource": {
+            "type": "string",
+            "enum": [
+                "registry",
+                "goplus",
+                "etherscan",
+                "derived"
+            ],
+            "x-enum-varnames": [
+                "LabelSourceRegistry",
+                "LabelSourceGoPlus",
+                "LabelSourceEtherscan",
+                "LabelSourceDerived"
+            ]
+        },
         "entity.RiskLevel": {
             "type": "string",
             "enum": [
@@ -149,6 +528,11 @@ const docTemplate = `{
                 "address": {
                     "type": "string",
                     "example": "0x0000db5c8B030ae20308ac975898E09741e70000"
+                },
+                "force_refresh": {
+                    "description": "ForceRefresh - Пересобрать отчет, не используя кэш (то же, что заголовок Cache-Control: no-cache)",
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
@@ -158,23 +542,85 @@ const docTemplate = `{
                 "address": {
                     "type": "string"
                 },
+                "cached_at": {
+                    "description": "CachedAt, ExpiresAt - Когда отчет был сохранен в кэш и до какого момента он считается свежим",
+                    "type": "string"
+                },
                 "checks": {
                     "type": "array",
                     "items": {
                         "$ref": "#/definitions/entity.CheckResult"
                     }
                 },
+                "counterparties": {
+                    "description": "Counterparties - Описания адресов, с которыми связаны результаты проверок (спендеры, токены)",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/entity.AddressLabel"
+                    }
+                },
                 "errors": {
                     "type": "array",
                     "items": {
                         "type": "string"
                     }
                 },
+                "expires_at": {
+                    "type": "string"
+                },
                 "recommendations": {
                     "type": "string"
                 },
                 "score": {
                     "type": "number"
+                },
+                "stale": {
+                    "description": "Stale - Отчет взят из кэша после истечения срока свежести и обновляется в фоне",
+                    "type": "boolean"
+                }
+            }
+        },
+        "main.IssueKeyRequest": {
+            "type": "object",
+            "required": [
+                "owner",
+                "tier"
+            ],
+            "properties": {
+                "owner": {
+                    "type": "string",
+                    "example": "partner-exchange"
+                },
+                "tier": {
+                    "type": "string",
+                    "example": "pro"
+                }
+            }
+        },
+        "main.IssueKeyResponse": {
+            "type": "object",
+            "properties": {
+                "api_key": {
+                    "type": "string",
+                    "example": "wns_3f9a..."
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "hash": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "owner": {
+                    "type": "string"
+                },
+                "revoked_at": {
+                    "type": "string"
+                },
+                "tier": {
+                    "type": "string"
                 }
             }
         }
@@ -2,6 +2,10 @@ package aggregator
 
 import (
 	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"alpha-hygiene-backend/config"
@@ -23,7 +27,9 @@ type Service struct {
 	cfg     *config.Config
 	factory CheckFactory
 	cache   cache.Cache
-	log     *logrus.Entry
+	// refreshing - Адреса, для которых уже идет фоновое обновление устаревшего отчета
+	refreshing sync.Map
+	log        *logrus.Entry
 }
 
 // NewService - Создает новый агрегатор
@@ -37,23 +43,77 @@ func NewService(cfg *config.Config, factory CheckFactory, cache cache.Cache, log
 	}
 }
 
+// CheckOptions - Параметры проверки кошелька
+type CheckOptions struct {
+	// ForceRefresh - Не использовать кэшированный отчет (например, пользователь только что отозвал approval)
+	ForceRefresh bool
+}
+
 // CheckWallet - Проверяет безопасность кошелька
 func (s *Service) CheckWallet(ctx context.Context, address string) (*entity.WalletReport, error) {
+	return s.CheckWalletWithOptions(ctx, address, CheckOptions{})
+}
+
+// CheckWalletWithOptions - Проверяет безопасность кошелька с параметрами
+func (s *Service) CheckWalletWithOptions(ctx context.Context, address string, opts CheckOptions) (*entity.WalletReport, error) {
 	// Создаем основной контекст с таймаутом. Увеличиваем для AI сервиса.
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 5*time.Minute) // 5 минут для работы с AI
 	defer cancel()
 	// Проверяем кэш
-	if s.cache != nil {
+	if s.cache != nil && !opts.ForceRefresh {
 		cachedReport, err := s.cache.GetWalletReport(ctxWithTimeout, address)
 		if err != nil {
 			s.log.Errorf("Failed to get cached report: %v", err)
 		}
 		if cachedReport != nil {
+			// Устаревший отчет отдаем сразу, а свежий строим в фоне
+			if cachedReport.Stale {
+				s.refreshInBackground(address)
+			}
 			s.log.Debugf("Returning cached report for address: %s", address)
 			return cachedReport, nil
 		}
 	}
 
+	return s.buildReport(ctxWithTimeout, address, opts), nil
+}
+
+// InvalidateWallet - Удаляет кэшированный отчет о кошельке и результаты всех его проверок,
+// чтобы следующий запрос не собрал отчет заново из устаревших результатов
+func (s *Service) InvalidateWallet(ctx context.Context, address string) error {
+	if s.cache == nil {
+		return nil
+	}
+
+	errs := []error{s.cache.DeleteWalletReport(ctx, address)}
+	for _, t := range checker.GetAllCheckTypes() {
+		errs = append(errs, s.cache.DeleteCheckResult(ctx, address, string(t)))
+	}
+	return errors.Join(errs...)
+}
+
+// refreshInBackground - Пересобирает отчет вне запроса; одновременно не более одного обновления на адрес
+func (s *Service) refreshInBackground(address string) {
+	key := strings.ToLower(address)
+	if _, loaded := s.refreshing.LoadOrStore(key, struct{}{}); loaded {
+		return
+	}
+
+	go func() {
+		defer s.refreshing.Delete(key)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+		defer cancel()
+
+		s.log.Debugf("Refreshing stale report for address: %s", address)
+		s.buildReport(ctx, address, CheckOptions{})
+	}()
+}
+
+// buildReport - Запускает все проверки, формирует отчет и сохраняет его в кэш.
+// Успешные результаты проверок кэшируются по отдельности, поэтому при сбое одного
+// провайдера следующий запрос повторит только упавшие проверки.
+func (s *Service) buildReport(ctxWithTimeout context.Context, address string, opts CheckOptions) *entity.WalletReport {
 	// Создаем группу для параллельного запуска проверок с отдельным контекстом
 	g, errGrpCtx := errgroup.WithContext(ctxWithTimeout)
 
@@ -71,6 +131,13 @@ func (s *Service) CheckWallet(ctx context.Context, address string) (*entity.Wall
 				return nil
 			}
 
+			if !opts.ForceRefresh {
+				if cached := s.getCachedCheckResult(errGrpCtx, address, check.Name()); cached != nil {
+					resultsChan <- cached
+					return nil
+				}
+			}
+
 			s.log.Debugf("Executing check: %s for address: %s", check.Name(), address)
 			result, err := check.Execute(errGrpCtx, address)
 			if err != nil {
@@ -78,6 +145,13 @@ func (s *Service) CheckWallet(ctx context.Context, address string) (*entity.Wall
 				return nil
 			}
 
+			result.Source = entity.CheckSourceFresh
+			if s.cache != nil {
+				if err := s.cache.SetCheckResult(errGrpCtx, address, result); err != nil {
+					s.log.Errorf("Failed to cache %s check result: %v", check.Name(), err)
+				}
+			}
+
 			resultsChan <- result
 			return nil
 		})
@@ -120,12 +194,13 @@ func (s *Service) CheckWallet(ctx context.Context, address string) (*entity.Wall
 	for i, res := range results {
 		report.Checks[i] = *res
 	}
+	report.Counterparties = collectCounterparties(results)
 
 	s.log.Infof("Check completed for address: %s, score: %.2f", address, score)
 
 	// предотвращаем кеширование - если были ошибки провайдеров
 	if len(errors) > 0 {
-		return report, nil
+		return report
 	}
 	// Сохраняем в кэш используя основной контекст с таймаутом
 	if s.cache != nil {
@@ -134,12 +209,50 @@ func (s *Service) CheckWallet(ctx context.Context, address string) (*entity.Wall
 		}
 	}
 
-	return report, nil
+	return report
+}
+
+// getCachedCheckResult - Возвращает кэшированный результат проверки или nil
+func (s *Service) getCachedCheckResult(ctx context.Context, address, checkName string) *entity.CheckResult {
+	if s.cache == nil {
+		return nil
+	}
+
+	result, err := s.cache.GetCheckResult(ctx, address, checkName)
+	if err != nil {
+		s.log.Errorf("Failed to get cached %s check result: %v", checkName, err)
+		return nil
+	}
+	if result == nil {
+		return nil
+	}
+
+	s.log.Debugf("Using cached %s check result for address: %s", checkName, address)
+	result.Source = entity.CheckSourceCache
+	return result
+}
+
+// collectCounterparties - Объединяет описания адресов из всех проверок без повторов
+func collectCounterparties(results []*entity.CheckResult) []entity.AddressLabel {
+	var counterparties []entity.AddressLabel
+	seen := make(map[string]bool)
+	for _, res := range results {
+		for _, label := range res.Counterparties {
+			address := strings.ToLower(label.Address)
+			if seen[address] {
+				continue
+			}
+			seen[address] = true
+			counterparties = append(counterparties, label)
+		}
+	}
+	sort.Slice(counterparties, func(i, j int) bool { return counterparties[i].Address < counterparties[j].Address })
+	return counterparties
 }
 
 // calculateScore - Рассчитывает итоговый балл безопасности
 func (s *Service) calculateScore(results []*entity.CheckResult) float64 {
-	score := s.cfg.Scoring.BaseScore
+	score := s.cfg.BaseScore()
 
 	for _, res := range results {
 		if res.RiskFound {
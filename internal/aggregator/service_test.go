@@ -2,10 +2,13 @@ package aggregator
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 
 	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
 	"alpha-hygiene-backend/internal/checker"
 	"alpha-hygiene-backend/internal/entity"
 	"alpha-hygiene-backend/pkg/logger"
@@ -20,33 +23,17 @@ func TestCheckWallet(t *testing.T) {
 
 	// Создаем тестовую конфигурацию
 	cfg := &config.Config{
-		App: struct {
-			Port       int    `yaml:"port"`
-			LogLevel   string `yaml:"log_level"`
-			TimeoutSec int    `yaml:"timeout_sec"`
-			RateLimit  struct {
-				Enabled  bool `yaml:"enabled"`
-				Requests int  `yaml:"requests"`
-				Window   int  `yaml:"window_seconds"`
-			} `yaml:"rate_limit"`
-		}{
+		App: config.AppConfig{
 			Port:       8080,
 			LogLevel:   "debug",
 			TimeoutSec: 30,
-			RateLimit: struct {
-				Enabled  bool `yaml:"enabled"`
-				Requests int  `yaml:"requests"`
-				Window   int  `yaml:"window_seconds"`
-			}{
+			RateLimit: config.RateLimitConfig{
 				Enabled:  false,
 				Requests: 100,
 				Window:   60,
 			},
 		},
-		Scoring: struct {
-			BaseScore float64            `yaml:"base_score"`
-			Weights   map[string]float64 `yaml:"weights"`
-		}{
+		Scoring: config.ScoringConfig{
 			BaseScore: 100,
 			Weights: map[string]float64{
 				"approvals":   0.4,
@@ -83,6 +70,172 @@ func TestCheckWallet(t *testing.T) {
 	assert.Empty(t, report.Errors)
 }
 
+func TestCheckWallet_StaleReportRefreshedInBackground(t *testing.T) {
+	log, err := logger.New("debug")
+	assert.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Scoring.BaseScore = 100
+
+	stale := &entity.WalletReport{Address: "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc", Score: 50, Stale: true}
+	cache := &staleCache{report: stale, stored: make(chan *entity.WalletReport, 1)}
+	service := NewService(cfg, &mockCheckerFactory{}, cache, log.WithContext(t.Context()))
+
+	// Устаревший отчет возвращается сразу
+	report, err := service.CheckWallet(context.Background(), stale.Address)
+	assert.NoError(t, err)
+	assert.Same(t, stale, report)
+
+	// Свежий отчет строится и сохраняется в фоне
+	select {
+	case fresh := <-cache.stored:
+		assert.Equal(t, 100.0, fresh.Score)
+		assert.False(t, fresh.Stale)
+	case <-time.After(5 * time.Second):
+		t.Fatal("stale report was not refreshed")
+	}
+}
+
+func TestCheckWalletWithOptions_ForceRefresh(t *testing.T) {
+	log, err := logger.New("debug")
+	assert.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Scoring.BaseScore = 100
+
+	cached := &entity.WalletReport{Address: "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc", Score: 50}
+	cache := &staleCache{report: cached, stored: make(chan *entity.WalletReport, 1)}
+	service := NewService(cfg, &mockCheckerFactory{}, cache, log.WithContext(t.Context()))
+
+	report, err := service.CheckWalletWithOptions(context.Background(), cached.Address, CheckOptions{ForceRefresh: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 100.0, report.Score)
+	assert.Same(t, report, <-cache.stored)
+}
+
+func TestCheckWallet_RetriesOnlyFailedChecks(t *testing.T) {
+	log, err := logger.New("debug")
+	assert.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Scoring.BaseScore = 100
+	entry := log.WithContext(t.Context())
+
+	factory := &flakyCheckerFactory{failing: checker.CheckApprovals, executed: make(map[string]int)}
+	reports := cache.NewReportCache(cache.NewMemoryStore(cfg, entry), cfg, entry)
+	service := NewService(cfg, factory, reports, entry)
+	address := "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc"
+
+	report, err := service.CheckWallet(context.Background(), address)
+	assert.NoError(t, err)
+	assert.Len(t, report.Errors, 1)
+
+	// Провайдер восстановился: повторяется только упавшая проверка
+	factory.failing = ""
+	report, err = service.CheckWallet(context.Background(), address)
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors)
+	assert.Len(t, report.Checks, len(checker.GetAllCheckTypes()))
+
+	for _, check := range report.Checks {
+		if check.CheckName == string(checker.CheckApprovals) {
+			assert.Equal(t, entity.CheckSourceFresh, check.Source)
+			assert.Equal(t, 2, factory.executed[check.CheckName])
+		} else {
+			assert.Equal(t, entity.CheckSourceCache, check.Source)
+			assert.NotNil(t, check.CachedAt)
+			assert.Equal(t, 1, factory.executed[check.CheckName])
+		}
+	}
+}
+
+func TestInvalidateWallet_DropsCachedCheckResults(t *testing.T) {
+	log, err := logger.New("debug")
+	assert.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Scoring.BaseScore = 100
+	entry := log.WithContext(t.Context())
+
+	factory := &flakyCheckerFactory{executed: make(map[string]int)}
+	reports := cache.NewReportCache(cache.NewMemoryStore(cfg, entry), cfg, entry)
+	service := NewService(cfg, factory, reports, entry)
+	address := "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc"
+
+	_, err = service.CheckWallet(context.Background(), address)
+	assert.NoError(t, err)
+	assert.NoError(t, service.InvalidateWallet(context.Background(), "0x742D35CC6634C0532925A3B88650D7241EFF5CBC"))
+
+	report, err := service.CheckWallet(context.Background(), address)
+	assert.NoError(t, err)
+	assert.Len(t, report.Checks, len(checker.GetAllCheckTypes()))
+	for _, check := range report.Checks {
+		assert.NotEqual(t, entity.CheckSourceCache, check.Source, check.CheckName)
+		assert.Equal(t, 2, factory.executed[check.CheckName], check.CheckName)
+	}
+}
+
+func TestCollectCounterparties(t *testing.T) {
+	results := []*entity.CheckResult{
+		{CheckName: "approvals", Counterparties: []entity.AddressLabel{
+			{Address: "0xbb", Label: "unverified contract deployed 3 days ago"},
+			{Address: "0xaa", Label: "Uniswap V3 Router"},
+		}},
+		{CheckName: "scam_tokens", Counterparties: []entity.AddressLabel{
+			{Address: "0xBB", Label: "Fake token"},
+		}},
+		{CheckName: "assets"},
+	}
+
+	counterparties := collectCounterparties(results)
+	assert.Len(t, counterparties, 2)
+	assert.Equal(t, "0xaa", counterparties[0].Address)
+	assert.Equal(t, "unverified contract deployed 3 days ago", counterparties[1].Label)
+}
+
+// flakyCheckerFactory - Фабрика, в которой проверка failing завершается ошибкой
+type flakyCheckerFactory struct {
+	mu       sync.Mutex
+	failing  checker.CheckType
+	executed map[string]int
+}
+
+func (f *flakyCheckerFactory) CreateCheck(t checker.CheckType) checker.IHealthCheck {
+	return &flakyHealthCheck{mockHealthCheck: mockHealthCheck{t}, factory: f}
+}
+
+type flakyHealthCheck struct {
+	mockHealthCheck
+	factory *flakyCheckerFactory
+}
+
+func (c *flakyHealthCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.factory.mu.Lock()
+	defer c.factory.mu.Unlock()
+
+	c.factory.executed[c.Name()]++
+	if c.checkType == c.factory.failing {
+		return nil, errors.New("provider unavailable")
+	}
+	return c.mockHealthCheck.Execute(ctx, address)
+}
+
+// staleCache - Кэш, всегда возвращающий заданный отчет
+type staleCache struct {
+	mockCache
+	report *entity.WalletReport
+	stored chan *entity.WalletReport
+}
+
+func (m *staleCache) GetWalletReport(ctx context.Context, address string) (*entity.WalletReport, error) {
+	return m.report, nil
+}
+
+func (m *staleCache) SetWalletReport(ctx context.Context, address string, report *entity.WalletReport) error {
+	m.stored <- report
+	return nil
+}
+
 // mockCheckerFactory - Мок для фабрики проверок
 type mockCache struct{}
 
@@ -94,6 +247,22 @@ func (m *mockCache) SetWalletReport(ctx context.Context, address string, report
 	return nil
 }
 
+func (m *mockCache) DeleteWalletReport(ctx context.Context, address string) error {
+	return nil
+}
+
+func (m *mockCache) GetCheckResult(ctx context.Context, address, checkName string) (*entity.CheckResult, error) {
+	return nil, nil
+}
+
+func (m *mockCache) SetCheckResult(ctx context.Context, address string, result *entity.CheckResult) error {
+	return nil
+}
+
+func (m *mockCache) DeleteCheckResult(ctx context.Context, address, checkName string) error {
+	return nil
+}
+
 func (m *mockCache) Close() error {
 	return nil
 }
@@ -0,0 +1,254 @@
+package apikey
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// keyPrefix - Префикс выдаваемых ключей, чтобы их было легко узнать в логах и секретах
+	keyPrefix = "wns_"
+	// usageRetention - Сколько хранятся суточные счетчики использования
+	usageRetention = 48 * time.Hour
+)
+
+var (
+	// ErrNotFound - Ключ не найден
+	ErrNotFound = errors.New("api key not found")
+	// ErrUnknownTier - Тариф не описан в конфигурации
+	ErrUnknownTier = errors.New("unknown tier")
+)
+
+// Key - Выданный API ключ. Сам ключ не хранится, только его SHA-256.
+type Key struct {
+	ID        string     `json:"id" yaml:"id"`
+	Hash      string     `json:"hash" yaml:"hash"`
+	Owner     string     `json:"owner" yaml:"owner"`
+	Tier      string     `json:"tier" yaml:"tier"`
+	CreatedAt time.Time  `json:"created_at" yaml:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty" yaml:"revoked_at,omitempty"`
+}
+
+// Revoked - Отозван ли ключ
+func (k *Key) Revoked() bool {
+	return k.RevokedAt != nil
+}
+
+// Tier - Тариф с ограничениями
+type Tier struct {
+	Name string `json:"name"`
+	config.APIKeyTier
+}
+
+// Store - Хранилище ключей и счетчиков использования
+type Store interface {
+	// FindByHash - Ищет ключ по хэшу (nil, nil - не найден)
+	FindByHash(ctx context.Context, hash string) (*Key, error)
+	// Save - Сохраняет новый ключ
+	Save(ctx context.Context, key *Key) error
+	// Revoke - Помечает ключ отозванным (ErrNotFound - нет такого ключа)
+	Revoke(ctx context.Context, id string, at time.Time) error
+	// List - Возвращает все ключи
+	List(ctx context.Context) ([]Key, error)
+	// IncrUsage - Увеличивает счетчик за день и возвращает новое значение
+	IncrUsage(ctx context.Context, id, day string) (int64, error)
+	// Usage - Значение счетчика за день
+	Usage(ctx context.Context, id, day string) (int64, error)
+	// Close - Освобождает ресурсы
+	Close() error
+}
+
+// Quota - Результат списания одной проверки
+type Quota struct {
+	Allowed   bool
+	Limit     int
+	Used      int64
+	Remaining int64
+	ResetAt   time.Time
+}
+
+// KeyUsage - Ключ с тарифом и использованием за текущие сутки
+type KeyUsage struct {
+	Key
+	UsedToday int64 `json:"used_today"`
+}
+
+// Manager - Выдача, проверка и учет использования API ключей
+type Manager struct {
+	store Store
+	tiers map[string]Tier
+	now   func() time.Time
+	log   *logrus.Entry
+}
+
+// NewManager - Создает менеджер ключей с хранилищем из конфигурации
+func NewManager(cfg *config.Config, log *logrus.Entry) (*Manager, error) {
+	var store Store
+	switch cfg.APIKeys.Backend {
+	case config.APIKeyBackendRedis:
+		store = NewRedisStore(cfg, log)
+	case config.APIKeyBackendFile, "":
+		fileStore, err := NewFileStore(cfg.APIKeys.File, log)
+		if err != nil {
+			return nil, err
+		}
+		store = fileStore
+	default:
+		return nil, fmt.Errorf("unknown api key backend: %s", cfg.APIKeys.Backend)
+	}
+	return NewManagerWithStore(store, cfg, log), nil
+}
+
+// NewManagerWithStore - Создает менеджер ключей поверх готового хранилища
+func NewManagerWithStore(store Store, cfg *config.Config, log *logrus.Entry) *Manager {
+	tiers := make(map[string]Tier, len(cfg.APIKeys.Tiers))
+	for name, limits := range cfg.APIKeys.Tiers {
+		tiers[name] = Tier{Name: name, APIKeyTier: limits}
+	}
+	return &Manager{
+		store: store,
+		tiers: tiers,
+		now:   time.Now,
+		log:   log.WithFields(logrus.Fields{"component": "api_keys"}),
+	}
+}
+
+// Authenticate - Проверяет ключ и возвращает его вместе с тарифом.
+// Неизвестный или отозванный ключ - (nil, nil, nil).
+func (m *Manager) Authenticate(ctx context.Context, rawKey string) (*Key, *Tier, error) {
+	if rawKey == "" {
+		return nil, nil, nil
+	}
+
+	key, err := m.store.FindByHash(ctx, hashKey(rawKey))
+	if err != nil {
+		return nil, nil, err
+	}
+	if key == nil || key.Revoked() {
+		return nil, nil, nil
+	}
+
+	tier, ok := m.tiers[key.Tier]
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %s", ErrUnknownTier, key.Tier)
+	}
+	return key, &tier, nil
+}
+
+// ConsumeScan - Списывает одну проверку из суточной квоты ключа
+func (m *Manager) ConsumeScan(ctx context.Context, key *Key, tier *Tier) (Quota, error) {
+	now := m.now().UTC()
+	quota := Quota{
+		Allowed: true,
+		Limit:   tier.ScansPerDay,
+		ResetAt: time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+	}
+
+	used, err := m.store.IncrUsage(ctx, key.ID, usageDay(now))
+	if err != nil {
+		return quota, err
+	}
+	quota.Used = used
+
+	if tier.ScansPerDay > 0 {
+		quota.Remaining = max(int64(tier.ScansPerDay)-used, 0)
+		quota.Allowed = used <= int64(tier.ScansPerDay)
+	}
+	return quota, nil
+}
+
+// Issue - Выдает новый ключ. Сам ключ возвращается только здесь.
+func (m *Manager) Issue(ctx context.Context, owner, tier string) (string, *Key, error) {
+	if _, ok := m.tiers[tier]; !ok {
+		return "", nil, fmt.Errorf("%w: %s", ErrUnknownTier, tier)
+	}
+
+	rawKey, err := randomHex(24)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := randomHex(8)
+	if err != nil {
+		return "", nil, err
+	}
+	rawKey = keyPrefix + rawKey
+
+	key := &Key{
+		ID:        id,
+		Hash:      hashKey(rawKey),
+		Owner:     owner,
+		Tier:      tier,
+		CreatedAt: m.now().UTC(),
+	}
+	if err := m.store.Save(ctx, key); err != nil {
+		return "", nil, err
+	}
+
+	m.log.Infof("Issued API key %s (tier %s) for %s", key.ID, key.Tier, key.Owner)
+	return rawKey, key, nil
+}
+
+// Revoke - Отзывает ключ по идентификатору
+func (m *Manager) Revoke(ctx context.Context, id string) error {
+	if err := m.store.Revoke(ctx, id, m.now().UTC()); err != nil {
+		return err
+	}
+	m.log.Infof("Revoked API key %s", id)
+	return nil
+}
+
+// List - Возвращает ключи с использованием за текущие сутки
+func (m *Manager) List(ctx context.Context) ([]KeyUsage, error) {
+	keys, err := m.store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].CreatedAt.Before(keys[j].CreatedAt) })
+
+	day := usageDay(m.now().UTC())
+	result := make([]KeyUsage, 0, len(keys))
+	for _, key := range keys {
+		used, err := m.store.Usage(ctx, key.ID, day)
+		if err != nil {
+			return nil, err
+		}
+		key.Hash = ""
+		result = append(result, KeyUsage{Key: key, UsedToday: used})
+	}
+	return result, nil
+}
+
+// Close - Закрывает хранилище ключей
+func (m *Manager) Close() error {
+	return m.store.Close()
+}
+
+// hashKey - SHA-256 ключа в hex
+func hashKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// usageDay - Сутки (UTC), к которым относится счетчик
+func usageDay(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// randomHex - Случайная строка из n байт в hex
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,117 @@
+package apikey
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLog(t *testing.T) *logrus.Entry {
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+	return log.WithContext(context.Background())
+}
+
+func testConfig() *config.Config {
+	cfg := &config.Config{}
+	cfg.APIKeys.Tiers = map[string]config.APIKeyTier{
+		"free": {ScansPerDay: 2},
+		"pro":  {ScansPerDay: 0},
+	}
+	return cfg
+}
+
+func TestManager_IssueAuthenticateRevoke(t *testing.T) {
+	cfg := testConfig()
+	log := testLog(t)
+	path := filepath.Join(t.TempDir(), "api_keys.yaml")
+	store, err := NewFileStore(path, log)
+	require.NoError(t, err)
+	manager := NewManagerWithStore(store, cfg, log)
+	ctx := context.Background()
+
+	_, _, err = manager.Issue(ctx, "someone", "enterprise")
+	assert.ErrorIs(t, err, ErrUnknownTier)
+
+	rawKey, issued, err := manager.Issue(ctx, "wallet-app", "free")
+	require.NoError(t, err)
+	assert.Contains(t, rawKey, keyPrefix)
+	assert.NotEqual(t, rawKey, issued.Hash)
+
+	key, tier, err := manager.Authenticate(ctx, rawKey)
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, issued.ID, key.ID)
+	assert.Equal(t, "free", tier.Name)
+
+	key, _, err = manager.Authenticate(ctx, "wns_unknown")
+	require.NoError(t, err)
+	assert.Nil(t, key)
+
+	// Ключи переживают перезапуск: файл перечитывается
+	reloaded, err := NewFileStore(path, log)
+	require.NoError(t, err)
+	found, err := reloaded.FindByHash(ctx, hashKey(rawKey))
+	require.NoError(t, err)
+	require.NotNil(t, found)
+
+	require.NoError(t, manager.Revoke(ctx, issued.ID))
+	key, _, err = manager.Authenticate(ctx, rawKey)
+	require.NoError(t, err)
+	assert.Nil(t, key)
+	assert.ErrorIs(t, manager.Revoke(ctx, "missing"), ErrNotFound)
+}
+
+func TestManager_DailyQuotaWithRedis(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := testConfig()
+	cfg.Redis.Addr = mr.Addr()
+	log := testLog(t)
+
+	store := NewRedisStore(cfg, log)
+	manager := NewManagerWithStore(store, cfg, log)
+	t.Cleanup(func() { _ = manager.Close() })
+	now := time.Date(2026, 1, 10, 23, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	rawKey, _, err := manager.Issue(ctx, "wallet-app", "free")
+	require.NoError(t, err)
+	key, tier, err := manager.Authenticate(ctx, rawKey)
+	require.NoError(t, err)
+	require.NotNil(t, key)
+
+	for i := 0; i < 2; i++ {
+		quota, err := manager.ConsumeScan(ctx, key, tier)
+		require.NoError(t, err)
+		assert.True(t, quota.Allowed)
+	}
+	quota, err := manager.ConsumeScan(ctx, key, tier)
+	require.NoError(t, err)
+	assert.False(t, quota.Allowed)
+	assert.Equal(t, int64(0), quota.Remaining)
+	assert.Equal(t, time.Date(2026, 1, 11, 0, 0, 0, 0, time.UTC), quota.ResetAt)
+	assert.Equal(t, usageRetention, mr.TTL(redisUsagePrefix+key.ID+":2026-01-10"))
+
+	keys, err := manager.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, keys, 1)
+	assert.Equal(t, int64(3), keys[0].UsedToday)
+	assert.Empty(t, keys[0].Hash)
+
+	// Новые сутки - новая квота
+	now = now.Add(2 * time.Hour)
+	quota, err = manager.ConsumeScan(ctx, key, tier)
+	require.NoError(t, err)
+	assert.True(t, quota.Allowed)
+	assert.Equal(t, int64(1), quota.Remaining)
+}
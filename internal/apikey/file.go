@@ -0,0 +1,164 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// fileContents - Формат файла ключей
+type fileContents struct {
+	Keys []Key `yaml:"keys"`
+}
+
+// FileStore - Ключи в YAML файле. Выдача и отзыв переписывают файл,
+// счетчики использования хранятся в памяти процесса.
+type FileStore struct {
+	mu    sync.Mutex
+	path  string
+	keys  []Key
+	usage map[string]int64
+	log   *logrus.Entry
+}
+
+// NewFileStore - Загружает ключи из файла (отсутствующий файл - пустой список)
+func NewFileStore(path string, log *logrus.Entry) (*FileStore, error) {
+	store := &FileStore{
+		path:  path,
+		usage: make(map[string]int64),
+		log:   log.WithFields(logrus.Fields{"component": "api_keys_file"}),
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		store.log.Warnf("API keys file %s not found, starting with no keys", path)
+		return store, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var contents fileContents
+	if err := yaml.Unmarshal(data, &contents); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys file %s: %w", path, err)
+	}
+	store.keys = contents.Keys
+	store.log.Infof("Loaded %d API keys from %s", len(store.keys), path)
+	return store, nil
+}
+
+// FindByHash - Ищет ключ по хэшу
+func (s *FileStore) FindByHash(ctx context.Context, hash string) (*Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range s.keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+// Save - Добавляет ключ и переписывает файл
+func (s *FileStore) Save(ctx context.Context, key *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := append(append([]Key(nil), s.keys...), *key)
+	if err := s.write(keys); err != nil {
+		return err
+	}
+	s.keys = keys
+	return nil
+}
+
+// Revoke - Помечает ключ отозванным и переписывает файл
+func (s *FileStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keys := append([]Key(nil), s.keys...)
+	for i := range keys {
+		if keys[i].ID != id {
+			continue
+		}
+		if keys[i].Revoked() {
+			return nil
+		}
+		keys[i].RevokedAt = &at
+		if err := s.write(keys); err != nil {
+			return err
+		}
+		s.keys = keys
+		return nil
+	}
+	return ErrNotFound
+}
+
+// List - Возвращает копию списка ключей
+func (s *FileStore) List(ctx context.Context) ([]Key, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]Key(nil), s.keys...), nil
+}
+
+// IncrUsage - Увеличивает счетчик за день
+func (s *FileStore) IncrUsage(ctx context.Context, id, day string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Счетчики за прошлые дни больше не нужны
+	for k := range s.usage {
+		if !strings.HasSuffix(k, ":"+day) {
+			delete(s.usage, k)
+		}
+	}
+
+	s.usage[id+":"+day]++
+	return s.usage[id+":"+day], nil
+}
+
+// Usage - Значение счетчика за день
+func (s *FileStore) Usage(ctx context.Context, id, day string) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usage[id+":"+day], nil
+}
+
+// Close - Ничего не делает: файл переписывается при каждом изменении
+func (s *FileStore) Close() error {
+	return nil
+}
+
+// write - Атомарно переписывает файл ключей (через временный файл)
+func (s *FileStore) write(keys []Key) error {
+	data, err := yaml.Marshal(fileContents{Keys: keys})
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), ".api_keys-*.yaml")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
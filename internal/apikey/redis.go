@@ -0,0 +1,152 @@
+package apikey
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// redisKeyByHash - Ключ по хэшу: JSON Key
+	redisKeyByHash = "api_key:hash:"
+	// redisKeyIDs - Hash id -> хэш ключа
+	redisKeyIDs = "api_key:ids"
+	// redisUsagePrefix - Суточные счетчики: api_key_usage:<id>:<day>
+	redisUsagePrefix = "api_key_usage:"
+)
+
+// RedisStore - Ключи и счетчики использования в Redis (общие для всех реплик)
+type RedisStore struct {
+	client *redis.Client
+	log    *logrus.Entry
+}
+
+// NewRedisStore - Создает хранилище ключей в Redis
+func NewRedisStore(cfg *config.Config, log *logrus.Entry) *RedisStore {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+	return &RedisStore{
+		client: client,
+		log:    log.WithFields(logrus.Fields{"component": "api_keys_redis"}),
+	}
+}
+
+// FindByHash - Ищет ключ по хэшу
+func (s *RedisStore) FindByHash(ctx context.Context, hash string) (*Key, error) {
+	data, err := s.client.Get(ctx, redisKeyByHash+hash).Bytes()
+	if err == redis.Nil {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var key Key
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// Save - Сохраняет ключ и индекс по идентификатору
+func (s *RedisStore) Save(ctx context.Context, key *Key) error {
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Set(ctx, redisKeyByHash+key.Hash, data, 0)
+		pipe.HSet(ctx, redisKeyIDs, key.ID, key.Hash)
+		return nil
+	})
+	return err
+}
+
+// Revoke - Помечает ключ отозванным
+func (s *RedisStore) Revoke(ctx context.Context, id string, at time.Time) error {
+	hash, err := s.client.HGet(ctx, redisKeyIDs, id).Result()
+	if err == redis.Nil {
+		return ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	key, err := s.FindByHash(ctx, hash)
+	if err != nil {
+		return err
+	}
+	if key == nil {
+		return ErrNotFound
+	}
+	if key.Revoked() {
+		return nil
+	}
+
+	key.RevokedAt = &at
+	data, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	return s.client.Set(ctx, redisKeyByHash+hash, data, 0).Err()
+}
+
+// List - Возвращает все ключи
+func (s *RedisStore) List(ctx context.Context) ([]Key, error) {
+	hashes, err := s.client.HVals(ctx, redisKeyIDs).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]Key, 0, len(hashes))
+	for _, hash := range hashes {
+		key, err := s.FindByHash(ctx, hash)
+		if err != nil {
+			return nil, err
+		}
+		if key != nil {
+			keys = append(keys, *key)
+		}
+	}
+	return keys, nil
+}
+
+// IncrUsage - Увеличивает счетчик за день; счетчик живет usageRetention
+func (s *RedisStore) IncrUsage(ctx context.Context, id, day string) (int64, error) {
+	counter := redisUsagePrefix + id + ":" + day
+
+	var incr *redis.IntCmd
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, counter)
+		pipe.Expire(ctx, counter, usageRetention)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Usage - Значение счетчика за день
+func (s *RedisStore) Usage(ctx context.Context, id, day string) (int64, error) {
+	val, err := s.client.Get(ctx, redisUsagePrefix+id+":"+day).Result()
+	if err == redis.Nil {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// Close - Закрывает соединение с Redis
+func (s *RedisStore) Close() error {
+	return s.client.Close()
+}
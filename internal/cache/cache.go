@@ -0,0 +1,80 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"alpha-hygiene-backend/internal/entity"
+)
+
+const (
+	// CacheExpiration - Время, в течение которого отчет считается свежим (по умолчанию)
+	CacheExpiration = 5 * time.Minute
+	// defaultStaleExpiration - Сколько отчет отдается устаревшим после CacheExpiration (по умолчанию)
+	defaultStaleExpiration = 30 * time.Minute
+	// NoExpiration - Значение хранится бессрочно (вытесняется только из LRU)
+	NoExpiration time.Duration = 0
+)
+
+// neverExpires - Срок для бессрочных значений (максимум, представимый в unix nano)
+var neverExpires = time.Unix(0, math.MaxInt64)
+
+// ErrUnavailable - Хранилище временно недоступно (например, Redis упал)
+var ErrUnavailable = errors.New("cache store is unavailable")
+
+// Cache - Интерфейс для кэша
+type Cache interface {
+	GetWalletReport(ctx context.Context, address string) (*entity.WalletReport, error)
+	SetWalletReport(ctx context.Context, address string, report *entity.WalletReport) error
+	DeleteWalletReport(ctx context.Context, address string) error
+	GetCheckResult(ctx context.Context, address, checkName string) (*entity.CheckResult, error)
+	SetCheckResult(ctx context.Context, address string, result *entity.CheckResult) error
+	DeleteCheckResult(ctx context.Context, address, checkName string) error
+	Close() error
+}
+
+// Store - Байтовое хранилище с поддержкой stale-while-revalidate.
+// Get возвращает nil, nil при промахе.
+type Store interface {
+	Get(ctx context.Context, key string) (*Entry, error)
+	// Set - Сохраняет значение: freshFor - сколько оно свежее, keepFor - сколько хранится всего (keepFor >= freshFor)
+	Set(ctx context.Context, key string, value []byte, freshFor, keepFor time.Duration) error
+	Delete(ctx context.Context, key string) error
+	Close() error
+}
+
+// Entry - Значение в хранилище вместе со сроками жизни
+type Entry struct {
+	Value      []byte
+	StoredAt   time.Time
+	FreshUntil time.Time
+	ExpiresAt  time.Time
+}
+
+// IsStale - Значение устарело, но еще может быть отдано, пока обновляется
+func (e *Entry) IsStale(now time.Time) bool {
+	return !now.Before(e.FreshUntil)
+}
+
+// IsPermanent - Значение хранится бессрочно
+func (e *Entry) IsPermanent() bool {
+	return e.ExpiresAt.Equal(neverExpires)
+}
+
+// newEntry - Создает Entry со сроками относительно now; freshFor = NoExpiration - бессрочное значение
+func newEntry(value []byte, now time.Time, freshFor, keepFor time.Duration) *Entry {
+	if freshFor == NoExpiration {
+		return &Entry{Value: value, StoredAt: now, FreshUntil: neverExpires, ExpiresAt: neverExpires}
+	}
+	if keepFor < freshFor {
+		keepFor = freshFor
+	}
+	return &Entry{
+		Value:      value,
+		StoredAt:   now,
+		FreshUntil: now.Add(freshFor),
+		ExpiresAt:  now.Add(keepFor),
+	}
+}
@@ -0,0 +1,235 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testLog(t *testing.T) *logrus.Entry {
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+	return log.WithContext(context.Background())
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cache.MemoryMaxEntries = 2
+	store := NewMemoryStore(cfg, testLog(t))
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "a", []byte("1"), time.Minute, time.Minute))
+	require.NoError(t, store.Set(ctx, "b", []byte("2"), time.Minute, time.Minute))
+
+	// Обращение к "a" делает самым давним "b"
+	entry, _ := store.Get(ctx, "a")
+	require.NotNil(t, entry)
+	require.NoError(t, store.Set(ctx, "c", []byte("3"), time.Minute, time.Minute))
+
+	entry, _ = store.Get(ctx, "b")
+	assert.Nil(t, entry)
+	assert.Equal(t, 2, store.Len())
+}
+
+func TestMemoryStore_StaleAndExpired(t *testing.T) {
+	store := NewMemoryStore(&config.Config{}, testLog(t))
+	now := time.Now()
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "k", []byte("v"), time.Minute, 10*time.Minute))
+
+	now = now.Add(2 * time.Minute)
+	entry, _ := store.Get(ctx, "k")
+	require.NotNil(t, entry)
+	assert.True(t, entry.IsStale(now))
+
+	now = now.Add(10 * time.Minute)
+	entry, _ = store.Get(ctx, "k")
+	assert.Nil(t, entry)
+}
+
+func TestTieredStore_DegradesAndReconnects(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	cfg := &config.Config{}
+	cfg.Redis.Addr = mr.Addr()
+	cfg.Cache.RedisReconnectSeconds = 1
+	log := testLog(t)
+
+	remote := NewRedisStore(cfg, log)
+	store := NewTieredStoreWith(NewMemoryStore(cfg, log), remote, log)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	require.NoError(t, store.Set(ctx, "k", []byte("v"), time.Minute, time.Minute))
+	assert.True(t, mr.Exists("k"))
+
+	// Значение из Redis поднимается в LRU
+	_ = store.local.Delete(ctx, "k")
+	entry, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, []byte("v"), entry.Value)
+	assert.Equal(t, 1, store.local.Len())
+
+	// Redis упал - кэш продолжает работать на первом уровне
+	addr := mr.Addr()
+	mr.Close()
+	assert.NoError(t, store.Set(ctx, "down", []byte("x"), time.Minute, time.Minute))
+	assert.False(t, remote.Available())
+	entry, err = store.Get(ctx, "down")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+
+	// Удаление без Redis уходит из LRU, но сообщает, что значение могло остаться в Redis
+	assert.ErrorIs(t, store.Delete(ctx, "down"), ErrUnavailable)
+	entry, err = store.Get(ctx, "down")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+
+	// Для данных провайдеров удаление best-effort
+	typed := NewTyped[string](store, NamespacePrice, time.Minute, log)
+	assert.NoError(t, typed.Delete(ctx, "down"))
+
+	// Redis вернулся - фоновая проверка восстанавливает второй уровень
+	require.NoError(t, mr.StartAddr(addr))
+	require.Eventually(t, remote.Available, 5*time.Second, 100*time.Millisecond)
+	require.NoError(t, store.Set(ctx, "up", []byte("y"), time.Minute, time.Minute))
+	assert.True(t, mr.Exists("up"))
+}
+
+func TestTieredStore_ReplicasSeeDeletesAfterLocalTTL(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	cfg := &config.Config{}
+	cfg.Redis.Addr = mr.Addr()
+	log := testLog(t)
+
+	newReplica := func() *TieredStore {
+		store := NewTieredStoreWith(NewMemoryStore(cfg, log), NewRedisStore(cfg, log), log)
+		t.Cleanup(func() { _ = store.Close() })
+		return store
+	}
+	writer, reader := newReplica(), newReplica()
+	now := time.Now()
+	reader.local.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	require.NoError(t, writer.Set(ctx, "report", []byte("old"), time.Hour, time.Hour))
+	entry, err := reader.Get(ctx, "report")
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+
+	// Удаление на одной реплике не трогает LRU другой, пока не истечет localTTL
+	require.NoError(t, writer.Delete(ctx, "report"))
+	entry, _ = reader.Get(ctx, "report")
+	assert.NotNil(t, entry)
+
+	now = now.Add(defaultLocalTTL)
+	entry, err = reader.Get(ctx, "report")
+	require.NoError(t, err)
+	assert.Nil(t, entry)
+}
+
+func TestReportCache_MarksStaleReports(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cache.ReportTTLSeconds = 60
+	cfg.Cache.ReportStaleSeconds = 600
+	log := testLog(t)
+
+	memory := NewMemoryStore(cfg, log)
+	now := time.Now()
+	memory.now = func() time.Time { return now }
+
+	reports := NewReportCache(memory, cfg, log)
+	reports.now = memory.now
+	ctx := context.Background()
+
+	require.NoError(t, reports.SetWalletReport(ctx, "0xabc", &entity.WalletReport{Address: "0xabc", Score: 90}))
+
+	report, err := reports.GetWalletReport(ctx, "0xabc")
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.False(t, report.Stale)
+
+	now = now.Add(2 * time.Minute)
+	report, err = reports.GetWalletReport(ctx, "0xabc")
+	require.NoError(t, err)
+	require.NotNil(t, report)
+	assert.True(t, report.Stale)
+	assert.Equal(t, 90.0, report.Score)
+}
+
+func TestTyped_NamespacesAndPermanentValues(t *testing.T) {
+	mr := miniredis.RunT(t)
+
+	cfg := &config.Config{}
+	cfg.Redis.Addr = mr.Addr()
+	log := testLog(t)
+
+	store := NewTieredStoreWith(NewMemoryStore(cfg, log), NewRedisStore(cfg, log), log)
+	t.Cleanup(func() { _ = store.Close() })
+	ctx := context.Background()
+
+	type metadata struct {
+		Symbol string `json:"symbol"`
+	}
+	permanent := NewTyped[metadata](store, NamespaceTokenMetadata, NoExpiration, log)
+	prices := NewTyped[float64](store, NamespacePrice, time.Minute, log)
+
+	permanent.Set(ctx, "0xAbC", metadata{Symbol: "TKN"})
+	prices.Set(ctx, "0xabc", 1.5)
+
+	// Ключи нормализуются, пространства не пересекаются
+	value, ok := permanent.Get(ctx, "0xabc")
+	require.True(t, ok)
+	assert.Equal(t, "TKN", value.Symbol)
+	price, ok := prices.Get(ctx, "0xABC")
+	require.True(t, ok)
+	assert.Equal(t, 1.5, price)
+
+	assert.Equal(t, time.Duration(0), mr.TTL(NamespaceTokenMetadata+":0xabc"))
+	assert.Equal(t, time.Minute, mr.TTL(NamespacePrice+":0xabc"))
+
+	found, missing := prices.GetMany(ctx, []string{"0xabc", "0xdef"})
+	assert.Len(t, found, 1)
+	assert.Equal(t, []string{"0xdef"}, missing)
+
+	// Без подключенного кэша - всегда промах
+	var disabled *Typed[float64]
+	_, ok = disabled.Get(ctx, "0xabc")
+	assert.False(t, ok)
+}
+
+func TestReportCache_NormalizesKeysAndSetsTimestamps(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Cache.ReportTTLSeconds = 60
+	log := testLog(t)
+	reports := NewReportCache(NewMemoryStore(cfg, log), cfg, log)
+	ctx := context.Background()
+
+	report := &entity.WalletReport{Address: "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc"}
+	require.NoError(t, reports.SetWalletReport(ctx, report.Address, report))
+	require.NotNil(t, report.CachedAt)
+	assert.Equal(t, time.Minute, report.ExpiresAt.Sub(*report.CachedAt))
+
+	cached, err := reports.GetWalletReport(ctx, "0x742d35cc6634c0532925a3b88650d7241eff5cbc")
+	require.NoError(t, err)
+	require.NotNil(t, cached)
+	assert.WithinDuration(t, *report.CachedAt, *cached.CachedAt, time.Second)
+
+	require.NoError(t, reports.DeleteWalletReport(ctx, "0x742D35CC6634C0532925A3B88650D7241EFF5CBC"))
+	cached, err = reports.GetWalletReport(ctx, report.Address)
+	require.NoError(t, err)
+	assert.Nil(t, cached)
+}
@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultMemoryMaxEntries - Размер LRU по умолчанию
+	defaultMemoryMaxEntries = 10000
+)
+
+// MemoryStore - Ограниченный по числу записей in-process LRU
+type MemoryStore struct {
+	mu         sync.Mutex
+	maxEntries int
+	items      map[string]*list.Element
+	order      *list.List
+	now        func() time.Time
+	log        *logrus.Entry
+}
+
+// memoryItem - Элемент списка LRU
+type memoryItem struct {
+	key   string
+	entry *Entry
+	// evictAt - Когда копия удаляется из LRU раньше entry.ExpiresAt (нулевое - не раньше)
+	evictAt time.Time
+}
+
+// NewMemoryStore - Создает LRU размером cfg.Cache.MemoryMaxEntries
+func NewMemoryStore(cfg *config.Config, log *logrus.Entry) *MemoryStore {
+	maxEntries := cfg.Cache.MemoryMaxEntries
+	if maxEntries <= 0 {
+		maxEntries = defaultMemoryMaxEntries
+	}
+
+	return &MemoryStore{
+		maxEntries: maxEntries,
+		items:      make(map[string]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+		log:        log.WithFields(logrus.Fields{"component": "memory_cache"}),
+	}
+}
+
+// Get - Получает значение; просроченные записи удаляются
+func (s *MemoryStore) Get(ctx context.Context, key string) (*Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	elem, ok := s.items[key]
+	if !ok {
+		return nil, nil
+	}
+
+	item := elem.Value.(*memoryItem)
+	now := s.now()
+	if !now.Before(item.entry.ExpiresAt) || (!item.evictAt.IsZero() && !now.Before(item.evictAt)) {
+		s.removeElement(elem)
+		return nil, nil
+	}
+
+	s.order.MoveToFront(elem)
+	return item.entry, nil
+}
+
+// Set - Сохраняет значение, вытесняя самые давно использованные записи
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, freshFor, keepFor time.Duration) error {
+	s.setEntry(key, newEntry(value, s.now(), freshFor, keepFor))
+	return nil
+}
+
+// setEntry - Сохраняет готовую запись
+func (s *MemoryStore) setEntry(key string, entry *Entry) {
+	s.setEntryFor(key, entry, 0)
+}
+
+// setEntryFor - Сохраняет копию записи не дольше maxAge (0 - на весь срок записи).
+// Используется для значений, которые хранятся в Redis и могут быть удалены другой репликой.
+func (s *MemoryStore) setEntryFor(key string, entry *Entry, maxAge time.Duration) {
+	var evictAt time.Time
+	if maxAge > 0 && !entry.IsPermanent() {
+		evictAt = s.now().Add(maxAge)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		item := elem.Value.(*memoryItem)
+		item.entry, item.evictAt = entry, evictAt
+		s.order.MoveToFront(elem)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&memoryItem{key: key, entry: entry, evictAt: evictAt})
+	for s.order.Len() > s.maxEntries {
+		s.removeElement(s.order.Back())
+	}
+}
+
+// Delete - Удаляет значение
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.items[key]; ok {
+		s.removeElement(elem)
+	}
+	return nil
+}
+
+// Len - Количество записей в LRU
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.order.Len()
+}
+
+// Close - Очищает LRU
+func (s *MemoryStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items = make(map[string]*list.Element)
+	s.order.Init()
+	return nil
+}
+
+// removeElement - Удаляет элемент из списка и индекса (под мьютексом)
+func (s *MemoryStore) removeElement(elem *list.Element) {
+	s.order.Remove(elem)
+	delete(s.items, elem.Value.(*memoryItem).key)
+}
@@ -2,36 +2,41 @@ package cache
 
 import (
 	"context"
-	"encoding/json"
+	"encoding/binary"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"alpha-hygiene-backend/config"
-	"alpha-hygiene-backend/internal/entity"
 
 	"github.com/go-redis/redis/v8"
 	"github.com/sirupsen/logrus"
 )
 
 const (
-	// Cache expiration time
-	CacheExpiration = 5 * time.Minute
+	// defaultRedisReconnectInterval - Период проверки доступности Redis по умолчанию
+	defaultRedisReconnectInterval = 10 * time.Second
+	// redisPingTimeout - Таймаут одной проверки соединения
+	redisPingTimeout = 2 * time.Second
+	// redisEntryHeaderSize - Заголовок значения: StoredAt, FreshUntil, ExpiresAt (unix nano)
+	redisEntryHeaderSize = 24
 )
 
-// Cache - Интерфейс для кэша
-type Cache interface {
-	GetWalletReport(ctx context.Context, address string) (*entity.WalletReport, error)
-	SetWalletReport(ctx context.Context, address string, report *entity.WalletReport) error
-	Close() error
+// RedisStore - Хранилище в Redis (второй уровень кэша).
+// Недоступность Redis не считается фатальной: операции сразу возвращают ErrUnavailable,
+// а фоновая горутина проверяет соединение и возвращает хранилище в работу.
+type RedisStore struct {
+	client            *redis.Client
+	available         atomic.Bool
+	reconnectInterval time.Duration
+	done              chan struct{}
+	closeOnce         sync.Once
+	log               *logrus.Entry
 }
 
-// RedisCache - Реализация кэша с помощью Redis
-type RedisCache struct {
-	client *redis.Client
-	log    *logrus.Entry
-}
-
-// NewRedisCache - Создает новый Redis кэш
-func NewRedisCache(cfg *config.Config, log *logrus.Entry) (*RedisCache, error) {
+// NewRedisStore - Создает Redis хранилище и запускает фоновую проверку соединения
+func NewRedisStore(cfg *config.Config, log *logrus.Entry) *RedisStore {
 	logger := log.WithFields(logrus.Fields{"component": "redis"})
 	client := redis.NewClient(&redis.Options{
 		Addr:     cfg.Redis.Addr,
@@ -39,79 +44,157 @@ func NewRedisCache(cfg *config.Config, log *logrus.Entry) (*RedisCache, error) {
 		DB:       cfg.Redis.DB,
 	})
 
-	// Проверка соединения с Redis
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+	interval := time.Duration(cfg.Cache.RedisReconnectSeconds) * time.Second
+	if interval <= 0 {
+		interval = defaultRedisReconnectInterval
+	}
 
-	_, err := client.Ping(ctx).Result()
-	if err != nil {
-		log.Errorf("Failed to connect to Redis: %v", err)
-		return nil, err
+	store := &RedisStore{
+		client:            client,
+		reconnectInterval: interval,
+		done:              make(chan struct{}),
+		log:               logger,
 	}
 
-	logger.Info("Successfully connected to Redis")
+	if err := store.ping(); err != nil {
+		logger.Warnf("Failed to connect to Redis: %v. Will retry every %s", err, interval)
+	} else {
+		store.available.Store(true)
+		logger.Info("Successfully connected to Redis")
+	}
 
-	return &RedisCache{
-		client: client,
-		log:    logger,
-	}, nil
+	go store.monitor()
+
+	return store
 }
 
-// GetWalletReport - Получает отчет о кошельке из кэша
-func (c *RedisCache) GetWalletReport(ctx context.Context, address string) (*entity.WalletReport, error) {
-	key := c.getCacheKey(address)
+// Available - Доступен ли Redis в данный момент
+func (s *RedisStore) Available() bool {
+	return s.available.Load()
+}
 
-	val, err := c.client.Get(ctx, key).Result()
+// Get - Получает значение из Redis
+func (s *RedisStore) Get(ctx context.Context, key string) (*Entry, error) {
+	if !s.Available() {
+		return nil, ErrUnavailable
+	}
+
+	val, err := s.client.Get(ctx, key).Bytes()
 	if err == redis.Nil {
-		c.log.Debugf("Cache miss for address: %s", address)
 		return nil, nil
 	} else if err != nil {
-		c.log.Errorf("Failed to get from cache: %v", err)
+		s.markUnavailable(err)
 		return nil, err
 	}
 
-	var report entity.WalletReport
-	err = json.Unmarshal([]byte(val), &report)
+	entry, err := decodeRedisEntry(val)
 	if err != nil {
-		c.log.Errorf("Failed to unmarshal cache value: %v", err)
+		s.log.Errorf("Failed to decode cache value for key %s: %v", key, err)
 		return nil, err
 	}
-
-	c.log.Debugf("Cache hit for address: %s", address)
-	return &report, nil
+	return entry, nil
 }
 
-// SetWalletReport - Сохраняет отчет о кошельке в кэш
-func (c *RedisCache) SetWalletReport(ctx context.Context, address string, report *entity.WalletReport) error {
-	key := c.getCacheKey(address)
-
-	data, err := json.Marshal(report)
-	if err != nil {
-		c.log.Errorf("Failed to marshal report: %v", err)
-		return err
+// Set - Сохраняет значение в Redis с TTL = keepFor (без TTL для NoExpiration)
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, freshFor, keepFor time.Duration) error {
+	if !s.Available() {
+		return ErrUnavailable
 	}
 
-	err = c.client.Set(ctx, key, string(data), CacheExpiration).Err()
-	if err != nil {
-		c.log.Errorf("Failed to set cache: %v", err)
+	entry := newEntry(value, time.Now(), freshFor, keepFor)
+	ttl := entry.ExpiresAt.Sub(entry.StoredAt)
+	if entry.IsPermanent() {
+		ttl = 0
+	}
+	if err := s.client.Set(ctx, key, encodeRedisEntry(entry), ttl).Err(); err != nil {
+		s.markUnavailable(err)
 		return err
 	}
-
-	c.log.Debugf("Cache set for address: %s", address)
 	return nil
 }
 
-// Close - Закрывает соединение с Redis
-func (c *RedisCache) Close() error {
-	if err := c.client.Close(); err != nil {
-		c.log.Errorf("Failed to close Redis connection: %v", err)
+// Delete - Удаляет значение из Redis
+func (s *RedisStore) Delete(ctx context.Context, key string) error {
+	if !s.Available() {
+		return ErrUnavailable
+	}
+
+	if err := s.client.Del(ctx, key).Err(); err != nil {
+		s.markUnavailable(err)
 		return err
 	}
-	c.log.Info("Redis connection closed")
 	return nil
 }
 
-// getCacheKey - Генерирует ключ для кэша на основе адреса кошелька
-func (c *RedisCache) getCacheKey(address string) string {
-	return "wallet_report:" + address
+// Close - Останавливает фоновую проверку и закрывает соединение с Redis
+func (s *RedisStore) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.done)
+		if err = s.client.Close(); err != nil {
+			s.log.Errorf("Failed to close Redis connection: %v", err)
+			return
+		}
+		s.log.Info("Redis connection closed")
+	})
+	return err
+}
+
+// monitor - Периодически проверяет соединение и переключает доступность
+func (s *RedisStore) monitor() {
+	ticker := time.NewTicker(s.reconnectInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			err := s.ping()
+			switch {
+			case err == nil && !s.Available():
+				s.available.Store(true)
+				s.log.Info("Redis connection restored")
+			case err != nil:
+				s.markUnavailable(err)
+			}
+		}
+	}
+}
+
+// markUnavailable - Помечает Redis недоступным (логируется только переход)
+func (s *RedisStore) markUnavailable(err error) {
+	if s.available.Swap(false) {
+		s.log.Warnf("Redis is unavailable, serving from memory cache: %v", err)
+	}
+}
+
+// ping - Проверяет соединение с Redis
+func (s *RedisStore) ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), redisPingTimeout)
+	defer cancel()
+	return s.client.Ping(ctx).Err()
+}
+
+// encodeRedisEntry - Кодирует Entry: заголовок со сроками и значение
+func encodeRedisEntry(entry *Entry) []byte {
+	data := make([]byte, redisEntryHeaderSize+len(entry.Value))
+	binary.BigEndian.PutUint64(data[0:], uint64(entry.StoredAt.UnixNano()))
+	binary.BigEndian.PutUint64(data[8:], uint64(entry.FreshUntil.UnixNano()))
+	binary.BigEndian.PutUint64(data[16:], uint64(entry.ExpiresAt.UnixNano()))
+	copy(data[redisEntryHeaderSize:], entry.Value)
+	return data
+}
+
+// decodeRedisEntry - Декодирует значение, сохраненное encodeRedisEntry
+func decodeRedisEntry(data []byte) (*Entry, error) {
+	if len(data) < redisEntryHeaderSize {
+		return nil, fmt.Errorf("cache value is too short: %d bytes", len(data))
+	}
+	return &Entry{
+		StoredAt:   time.Unix(0, int64(binary.BigEndian.Uint64(data[0:]))),
+		FreshUntil: time.Unix(0, int64(binary.BigEndian.Uint64(data[8:]))),
+		ExpiresAt:  time.Unix(0, int64(binary.BigEndian.Uint64(data[16:]))),
+		Value:      data[redisEntryHeaderSize:],
+	}, nil
 }
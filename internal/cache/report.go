@@ -0,0 +1,179 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ReportCache - Кэш отчетов о кошельках и результатов отдельных проверок поверх Store.
+// Отчет, у которого истек срок свежести, возвращается с флагом Stale.
+type ReportCache struct {
+	store     Store
+	ttl       time.Duration
+	staleTTL  time.Duration
+	checkTTLs map[string]time.Duration
+	now       func() time.Time
+	log       *logrus.Entry
+}
+
+// NewReportCache - Создает кэш отчетов; сроки берутся из секции cache конфигурации
+func NewReportCache(store Store, cfg *config.Config, log *logrus.Entry) *ReportCache {
+	ttl := time.Duration(cfg.Cache.ReportTTLSeconds) * time.Second
+	if ttl <= 0 {
+		ttl = CacheExpiration
+	}
+	staleTTL := time.Duration(cfg.Cache.ReportStaleSeconds) * time.Second
+	if staleTTL <= 0 {
+		staleTTL = defaultStaleExpiration
+	}
+
+	checkTTLs := make(map[string]time.Duration, len(cfg.Cache.CheckTTLSeconds))
+	for name, seconds := range cfg.Cache.CheckTTLSeconds {
+		if seconds > 0 {
+			checkTTLs[name] = time.Duration(seconds) * time.Second
+		}
+	}
+
+	return &ReportCache{
+		store:     store,
+		ttl:       ttl,
+		staleTTL:  staleTTL,
+		checkTTLs: checkTTLs,
+		now:       time.Now,
+		log:       log.WithFields(logrus.Fields{"component": "report_cache"}),
+	}
+}
+
+// GetWalletReport - Получает отчет о кошельке из кэша
+func (c *ReportCache) GetWalletReport(ctx context.Context, address string) (*entity.WalletReport, error) {
+	entry, err := c.store.Get(ctx, c.getCacheKey(address))
+	if err != nil {
+		c.log.Errorf("Failed to get from cache: %v", err)
+		return nil, err
+	}
+	if entry == nil {
+		c.log.Debugf("Cache miss for address: %s", address)
+		return nil, nil
+	}
+
+	var report entity.WalletReport
+	if err := json.Unmarshal(entry.Value, &report); err != nil {
+		c.log.Errorf("Failed to unmarshal cache value: %v", err)
+		return nil, err
+	}
+	report.Stale = entry.IsStale(c.now())
+	cachedAt, expiresAt := entry.StoredAt, entry.FreshUntil
+	report.CachedAt = &cachedAt
+	report.ExpiresAt = &expiresAt
+
+	c.log.Debugf("Cache hit for address: %s (stale: %t)", address, report.Stale)
+	return &report, nil
+}
+
+// SetWalletReport - Сохраняет отчет о кошельке в кэш и проставляет в нем CachedAt/ExpiresAt
+func (c *ReportCache) SetWalletReport(ctx context.Context, address string, report *entity.WalletReport) error {
+	cachedAt := c.now()
+	expiresAt := cachedAt.Add(c.ttl)
+
+	stored := *report
+	stored.Stale = false
+	stored.CachedAt = nil
+	stored.ExpiresAt = nil
+
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		c.log.Errorf("Failed to marshal report: %v", err)
+		return err
+	}
+
+	if err := c.store.Set(ctx, c.getCacheKey(address), data, c.ttl, c.ttl+c.staleTTL); err != nil {
+		c.log.Errorf("Failed to set cache: %v", err)
+		return err
+	}
+
+	report.CachedAt = &cachedAt
+	report.ExpiresAt = &expiresAt
+
+	c.log.Debugf("Cache set for address: %s", address)
+	return nil
+}
+
+// DeleteWalletReport - Удаляет отчет о кошельке из кэша
+func (c *ReportCache) DeleteWalletReport(ctx context.Context, address string) error {
+	if err := c.store.Delete(ctx, c.getCacheKey(address)); err != nil {
+		c.log.Errorf("Failed to delete from cache: %v", err)
+		return err
+	}
+
+	c.log.Debugf("Cache deleted for address: %s", address)
+	return nil
+}
+
+// GetCheckResult - Получает кэшированный результат одной проверки; CachedAt берется из хранилища
+func (c *ReportCache) GetCheckResult(ctx context.Context, address, checkName string) (*entity.CheckResult, error) {
+	entry, err := c.store.Get(ctx, c.getCheckKey(address, checkName))
+	if err != nil || entry == nil {
+		return nil, err
+	}
+
+	var result entity.CheckResult
+	if err := json.Unmarshal(entry.Value, &result); err != nil {
+		c.log.Errorf("Failed to unmarshal cached check result: %v", err)
+		return nil, err
+	}
+	cachedAt := entry.StoredAt
+	result.CachedAt = &cachedAt
+
+	return &result, nil
+}
+
+// SetCheckResult - Сохраняет результат проверки со сроком жизни этой проверки
+func (c *ReportCache) SetCheckResult(ctx context.Context, address string, result *entity.CheckResult) error {
+	stored := *result
+	stored.Source = ""
+	stored.CachedAt = nil
+
+	data, err := json.Marshal(&stored)
+	if err != nil {
+		c.log.Errorf("Failed to marshal check result: %v", err)
+		return err
+	}
+
+	ttl, ok := c.checkTTLs[result.CheckName]
+	if !ok {
+		ttl = c.ttl
+	}
+	return c.store.Set(ctx, c.getCheckKey(address, result.CheckName), data, ttl, ttl)
+}
+
+// DeleteCheckResult - Удаляет кэшированный результат одной проверки
+func (c *ReportCache) DeleteCheckResult(ctx context.Context, address, checkName string) error {
+	if err := c.store.Delete(ctx, c.getCheckKey(address, checkName)); err != nil {
+		c.log.Errorf("Failed to delete check result from cache: %v", err)
+		return err
+	}
+	return nil
+}
+
+// Close - Хранилище принадлежит вызывающему и закрывается им
+func (c *ReportCache) Close() error {
+	return nil
+}
+
+// getCacheKey - Генерирует ключ для кэша на основе адреса кошелька.
+// Адрес приводится к нижнему регистру: checksum и lower-case написания - один кошелек.
+func (c *ReportCache) getCacheKey(address string) string {
+	return "wallet_report:" + strings.ToLower(address)
+}
+
+// getCheckKey - Генерирует ключ для кэша результата одной проверки
+func (c *ReportCache) getCheckKey(address, checkName string) string {
+	return "check_result:" + checkName + ":" + strings.ToLower(address)
+}
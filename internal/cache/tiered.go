@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/sirupsen/logrus"
+)
+
+// defaultLocalTTL - Сколько значение из Redis хранится в LRU по умолчанию
+const defaultLocalTTL = 30 * time.Second
+
+// TieredStore - Двухуровневое хранилище: in-process LRU перед Redis.
+// Ошибки Redis при чтении и записи не пробрасываются наружу - сервис продолжает работать на первом уровне.
+// Delete не затрагивает LRU других реплик, поэтому значения, записанные в Redis, хранятся в LRU
+// не дольше localTTL: после этого реплика перечитывает их из Redis и видит удаление.
+type TieredStore struct {
+	local    *MemoryStore
+	remote   Store
+	localTTL time.Duration
+	log      *logrus.Entry
+}
+
+// NewTieredStore - Создает LRU и Redis хранилище по конфигурации
+func NewTieredStore(cfg *config.Config, log *logrus.Entry) *TieredStore {
+	store := NewTieredStoreWith(NewMemoryStore(cfg, log), NewRedisStore(cfg, log), log)
+	if cfg.Cache.MemoryTTLSeconds > 0 {
+		store.localTTL = time.Duration(cfg.Cache.MemoryTTLSeconds) * time.Second
+	}
+	return store
+}
+
+// NewTieredStoreWith - Создает хранилище из готовых уровней; remote может быть nil
+func NewTieredStoreWith(local *MemoryStore, remote Store, log *logrus.Entry) *TieredStore {
+	return &TieredStore{
+		local:    local,
+		remote:   remote,
+		localTTL: defaultLocalTTL,
+		log:      log.WithFields(logrus.Fields{"component": "tiered_cache"}),
+	}
+}
+
+// Get - Ищет значение в LRU, затем в Redis; найденное в Redis поднимается в LRU
+func (s *TieredStore) Get(ctx context.Context, key string) (*Entry, error) {
+	if entry, _ := s.local.Get(ctx, key); entry != nil {
+		return entry, nil
+	}
+	if s.remote == nil {
+		return nil, nil
+	}
+
+	entry, err := s.remote.Get(ctx, key)
+	if err != nil {
+		s.logRemoteError("get", key, err)
+		return nil, nil
+	}
+	if entry != nil {
+		s.local.setEntryFor(key, entry, s.localTTL)
+	}
+	return entry, nil
+}
+
+// Set - Сохраняет значение на обоих уровнях. Если Redis недоступен, LRU хранит значение весь срок:
+// других копий нет, а явная инвалидация в это время завершается ошибкой.
+func (s *TieredStore) Set(ctx context.Context, key string, value []byte, freshFor, keepFor time.Duration) error {
+	if s.remote == nil {
+		return s.local.Set(ctx, key, value, freshFor, keepFor)
+	}
+	entry := newEntry(value, s.local.now(), freshFor, keepFor)
+	if err := s.remote.Set(ctx, key, value, freshFor, keepFor); err != nil {
+		s.logRemoteError("set", key, err)
+		s.local.setEntry(key, entry)
+		return nil
+	}
+	s.local.setEntryFor(key, entry, s.localTTL)
+	return nil
+}
+
+// Delete - Удаляет значение с обоих уровней. Ошибка Redis, в том числе ErrUnavailable, возвращается:
+// при явной инвалидации вызывающий должен знать, что значение могло остаться.
+func (s *TieredStore) Delete(ctx context.Context, key string) error {
+	_ = s.local.Delete(ctx, key)
+	if s.remote == nil {
+		return nil
+	}
+	return s.remote.Delete(ctx, key)
+}
+
+// Close - Закрывает оба уровня
+func (s *TieredStore) Close() error {
+	_ = s.local.Close()
+	if s.remote == nil {
+		return nil
+	}
+	return s.remote.Close()
+}
+
+// logRemoteError - Логирует ошибку Redis; недоступность уже залогирована RedisStore
+func (s *TieredStore) logRemoteError(op, key string, err error) {
+	if errors.Is(err, ErrUnavailable) {
+		return
+	}
+	s.log.Warnf("Redis %s failed for key %s: %v", op, key, err)
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Пространства ключей кэшей данных провайдеров
+const (
+	NamespaceTokenSecurity   = "goplus:token_security"
+	NamespaceNFTSecurity     = "goplus:nft_security"
+	NamespaceAddressSecurity = "goplus:address_security"
+	NamespaceTokenMetadata   = "token_metadata"
+	NamespacePrice           = "price"
+	NamespaceAddressInfo     = "etherscan:address_info"
+)
+
+// Typed - Типизированный кэш со своим пространством ключей и TTL поверх общего Store.
+// Ключи приводятся к нижнему регистру, поэтому адреса в разном регистре совпадают.
+// Ошибки хранилища не возвращаются: промах кэша всегда означает запрос к провайдеру.
+type Typed[T any] struct {
+	store     Store
+	namespace string
+	ttl       time.Duration
+	log       *logrus.Entry
+}
+
+// NewTyped - Создает типизированный кэш; ttl = NoExpiration - значения хранятся бессрочно
+func NewTyped[T any](store Store, namespace string, ttl time.Duration, log *logrus.Entry) *Typed[T] {
+	return &Typed[T]{
+		store:     store,
+		namespace: namespace,
+		ttl:       ttl,
+		log:       log.WithFields(logrus.Fields{"component": "cache", "namespace": namespace}),
+	}
+}
+
+// Get - Получает значение по ключу; ok = false при промахе
+func (c *Typed[T]) Get(ctx context.Context, key string) (value T, ok bool) {
+	if c == nil {
+		return value, false
+	}
+
+	entry, err := c.store.Get(ctx, c.key(key))
+	if err != nil || entry == nil {
+		return value, false
+	}
+	if err := json.Unmarshal(entry.Value, &value); err != nil {
+		c.log.Warnf("Failed to unmarshal cached value for key %s: %v", key, err)
+		return value, false
+	}
+	return value, true
+}
+
+// GetMany - Получает значения по нескольким ключам; возвращает найденные и список промахов
+func (c *Typed[T]) GetMany(ctx context.Context, keys []string) (map[string]T, []string) {
+	found := make(map[string]T, len(keys))
+	var missing []string
+	for _, key := range keys {
+		if value, ok := c.Get(ctx, key); ok {
+			found[key] = value
+		} else {
+			missing = append(missing, key)
+		}
+	}
+	return found, missing
+}
+
+// Set - Сохраняет значение по ключу
+func (c *Typed[T]) Set(ctx context.Context, key string, value T) {
+	if c == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.log.Warnf("Failed to marshal value for key %s: %v", key, err)
+		return
+	}
+	if err := c.store.Set(ctx, c.key(key), data, c.ttl, c.ttl); err != nil {
+		c.log.Warnf("Failed to cache value for key %s: %v", key, err)
+	}
+}
+
+// Delete - Удаляет значение по ключу. Данные провайдеров не инвалидируются явно,
+// поэтому недоступность Redis только логируется: значение истечет само.
+func (c *Typed[T]) Delete(ctx context.Context, key string) error {
+	if c == nil {
+		return nil
+	}
+	err := c.store.Delete(ctx, c.key(key))
+	if errors.Is(err, ErrUnavailable) {
+		c.log.Warnf("Redis is unavailable, key %s was deleted only from the local cache", key)
+		return nil
+	}
+	return err
+}
+
+// key - Полный ключ в хранилище
+func (c *Typed[T]) key(key string) string {
+	return c.namespace + ":" + strings.ToLower(key)
+}
@@ -3,27 +3,57 @@ package checker
 import (
 	"alpha-hygiene-backend/config"
 	"alpha-hygiene-backend/internal/checker/internal/checks"
+	"alpha-hygiene-backend/internal/labels"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
 
 	"github.com/sirupsen/logrus"
 )
 
+// Providers - Клиенты внешних API, реестр адресов и сервис меток, доступные проверкам. Node, Multicall и Labeler необязательны.
+type Providers struct {
+	GoPlus    *provider.GoPlusClient
+	Etherscan *provider.EtherscanClient
+	Alchemy   *provider.AlchemyClient
+	Prices    *provider.PriceClient
+	Node      *provider.NodeClient
+	Multicall *provider.MulticallClient
+	Registry  *registry.Registry
+	Labeler   *labels.Labeler
+}
+
 // Factory - Фабрика для создания проверок
 type Factory struct {
 	cfg            *config.Config
 	goplusProvider *provider.GoPlusClient
 	etherscan      *provider.EtherscanClient
 	alchemy        *provider.AlchemyClient
+	prices         *provider.PriceClient
+	registry       *registry.Registry
+	labeler        *labels.Labeler
+	balances       provider.BalanceProvider
+	approvals      provider.ApprovalProvider
+	permit2        provider.Permit2Provider
+	code           provider.CodeProvider
+	multicall      *provider.MulticallClient
 	log            *logrus.Entry
 }
 
 // NewFactory - Создает новую фабрику проверок
-func NewFactory(cfg *config.Config, goplusProvider *provider.GoPlusClient, etherscan *provider.EtherscanClient, alchemy *provider.AlchemyClient, log *logrus.Entry) *Factory {
+func NewFactory(cfg *config.Config, providers Providers, log *logrus.Entry) *Factory {
 	return &Factory{
 		cfg:            cfg,
-		goplusProvider: goplusProvider,
-		etherscan:      etherscan,
-		alchemy:        alchemy,
+		goplusProvider: providers.GoPlus,
+		etherscan:      providers.Etherscan,
+		alchemy:        providers.Alchemy,
+		prices:         providers.Prices,
+		registry:       providers.Registry,
+		labeler:        providers.Labeler,
+		balances:       selectBalanceProvider(cfg.Sources.Balances, providers, log),
+		approvals:      selectApprovalProvider(cfg.Sources.Approvals, providers, log),
+		permit2:        selectPermit2Provider(providers),
+		code:           selectCodeProvider(providers),
+		multicall:      providers.Multicall,
 		log:            log,
 	}
 }
@@ -32,13 +62,25 @@ func NewFactory(cfg *config.Config, goplusProvider *provider.GoPlusClient, ether
 func (f *Factory) CreateCheck(t CheckType) IHealthCheck {
 	switch t {
 	case CheckApprovals:
-		return checks.NewApprovalsCheck(f.goplusProvider, f.etherscan, f.cfg, f.log)
+		return checks.NewApprovalsCheck(f.approvals, f.permit2, f.etherscan, f.registry, f.labeler, f.cfg, f.log)
 	case CheckScamTokens:
-		return checks.NewScamTokensCheck(f.goplusProvider, f.alchemy, f.cfg, f.log)
+		return checks.NewScamTokensCheck(f.goplusProvider, f.balances, f.registry, f.labeler, f.cfg, f.log)
 	case CheckAssets:
-		return checks.NewAssetCompositionCheck(f.goplusProvider, f.alchemy, f.cfg, f.log)
+		return checks.NewAssetCompositionCheck(f.goplusProvider, f.balances, f.prices, f.registry, f.cfg, f.log)
 	case CheckNFT:
-		return checks.NewDeadNFTCheck(f.goplusProvider, f.alchemy, f.cfg, f.log)
+		return checks.NewDeadNFTCheck(f.goplusProvider, f.alchemy, f.registry, f.cfg, f.log)
+	case CheckStaleApprovals:
+		return checks.NewStaleApprovalsCheck(f.approvals, f.etherscan, f.labeler, f.cfg, f.log)
+	case CheckAddressPoisoning:
+		return checks.NewAddressPoisoningCheck(f.etherscan, f.registry, f.cfg, f.log)
+	case CheckEIP7702Delegation:
+		return checks.NewDelegationCheck(f.code, f.goplusProvider, f.registry, f.labeler, f.cfg, f.log)
+	case CheckSanctionsExposure:
+		return checks.NewSanctionsExposureCheck(f.etherscan, f.registry, f.cfg, f.log)
+	case CheckSafeConfig:
+		return checks.NewSafeConfigCheck(f.multicall, f.etherscan, f.goplusProvider, f.registry, f.cfg, f.log)
+	case CheckLendingHealth:
+		return checks.NewLendingHealthCheck(f.multicall, f.prices, f.cfg, f.log)
 	default:
 		return nil
 	}
@@ -51,5 +93,66 @@ func GetAllCheckTypes() []CheckType {
 		CheckScamTokens,
 		CheckAssets,
 		CheckNFT,
+		CheckStaleApprovals,
+		CheckAddressPoisoning,
+		CheckEIP7702Delegation,
+		CheckSanctionsExposure,
+		CheckSafeConfig,
+		CheckLendingHealth,
+	}
+}
+
+// selectBalanceProvider - Выбирает источник балансов по config sources.balances (по умолчанию Alchemy)
+func selectBalanceProvider(source string, providers Providers, log *logrus.Entry) provider.BalanceProvider {
+	switch source {
+	case provider.SourceNode:
+		if providers.Node != nil {
+			return providers.Node
+		}
+		log.Warnf("Balance source %q is not available, falling back to %q", source, provider.SourceAlchemy)
+	case provider.SourceEtherscan:
+		return providers.Etherscan
+	case "", provider.SourceAlchemy:
+	default:
+		log.Warnf("Unknown balance source %q, using %q", source, provider.SourceAlchemy)
+	}
+	return providers.Alchemy
+}
+
+// selectApprovalProvider - Выбирает источник approvals по config sources.approvals (по умолчанию GoPlus)
+func selectApprovalProvider(source string, providers Providers, log *logrus.Entry) provider.ApprovalProvider {
+	switch source {
+	case provider.SourceNode:
+		if providers.Node != nil {
+			return providers.Node
+		}
+		log.Warnf("Approval source %q is not available, falling back to %q", source, provider.SourceGoPlus)
+	case "", provider.SourceGoPlus:
+	default:
+		log.Warnf("Unknown approval source %q, using %q", source, provider.SourceGoPlus)
+	}
+	return providers.GoPlus
+}
+
+// selectPermit2Provider - Источник allowance внутри Permit2: собственный узел, если он подключен
+// (остатки сверяются с контрактом), иначе события Permit2 из Etherscan
+func selectPermit2Provider(providers Providers) provider.Permit2Provider {
+	if providers.Node != nil {
+		return providers.Node
+	}
+	if providers.Etherscan != nil {
+		return providers.Etherscan
+	}
+	return nil
+}
+
+// selectCodeProvider - Источник кода аккаунтов: собственный узел, если он подключен, иначе Alchemy
+func selectCodeProvider(providers Providers) provider.CodeProvider {
+	if providers.Node != nil {
+		return providers.Node
+	}
+	if providers.Alchemy != nil {
+		return providers.Alchemy
 	}
+	return nil
 }
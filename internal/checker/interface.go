@@ -23,4 +23,16 @@ const (
 	CheckAssets     CheckType = "assets"
 	CheckScamTokens CheckType = "scam_tokens"
 	CheckNFT        CheckType = "dead_nft"
+	// CheckStaleApprovals - Approvals, выданные давно или не используемые
+	CheckStaleApprovals CheckType = "stale_approvals"
+	// CheckAddressPoisoning - Переводы с адресов-двойников и поддельных токенов
+	CheckAddressPoisoning CheckType = "address_poisoning"
+	// CheckEIP7702Delegation - Делегирование кода кошелька по EIP-7702
+	CheckEIP7702Delegation CheckType = "eip7702_delegation"
+	// CheckSanctionsExposure - Переводы с санкционными адресами и миксерами
+	CheckSanctionsExposure CheckType = "sanctions_exposure"
+	// CheckSafeConfig - Конфигурация Safe multisig: порог, модули, владельцы
+	CheckSafeConfig CheckType = "safe_config"
+	// CheckLendingHealth - Заемные позиции Aave v3 и Compound v3, близкие к ликвидации
+	CheckLendingHealth CheckType = "lending_health"
 )
@@ -0,0 +1,399 @@
+package checks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Совпадение с адресом контрагента по умолчанию, hex-символов
+	defaultPoisoningPrefixChars = 4
+	defaultPoisoningSuffixChars = 4
+
+	// Признаки отравления истории
+	PoisoningLookalikeAddress  = "lookalike_address"
+	PoisoningZeroValueTransfer = "zero_value_transfer"
+	PoisoningFakeToken         = "fake_token"
+	// PoisoningFundsSent - Кошелек уже отправил средства на двойника, скопировав адрес из истории
+	PoisoningFundsSent = "funds_sent"
+
+	zeroAddress = "0x0000000000000000000000000000000000000000"
+)
+
+// homoglyphs - Кириллические и греческие буквы, которые выглядят как латинские (в верхнем регистре)
+var homoglyphs = map[rune]rune{
+	'А': 'A', 'В': 'B', 'Е': 'E', 'К': 'K', 'М': 'M', 'Н': 'H', 'О': 'O', 'Р': 'P', 'С': 'C', 'Т': 'T', 'У': 'Y', 'Х': 'X', 'Ѕ': 'S', 'І': 'I',
+	'Α': 'A', 'Β': 'B', 'Ε': 'E', 'Ζ': 'Z', 'Η': 'H', 'Ι': 'I', 'Κ': 'K', 'Μ': 'M', 'Ν': 'N', 'Ο': 'O', 'Ρ': 'P', 'Τ': 'T', 'Υ': 'Y', 'Χ': 'X',
+}
+
+// AddressPoisoningCheck - Проверка отравления истории кошелька: переводы с адресов-двойников реальных контрагентов,
+// нулевые transferFrom от имени кошелька и поддельные токены с символами известных токенов (USDT, USDC, ...)
+type AddressPoisoningCheck struct {
+	etherscan *provider.EtherscanClient
+	registry  *registry.Registry
+	cfg       *config.Config
+	log       *logrus.Entry
+}
+
+// NewAddressPoisoningCheck - Создает новую проверку отравления истории
+func NewAddressPoisoningCheck(etherscan *provider.EtherscanClient, reg *registry.Registry, cfg *config.Config, log *logrus.Entry) *AddressPoisoningCheck {
+	logger := log.WithFields(logrus.Fields{"component": "address_poisoning"})
+	return &AddressPoisoningCheck{
+		etherscan: etherscan,
+		registry:  reg,
+		cfg:       cfg,
+		log:       logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *AddressPoisoningCheck) Name() string {
+	return "address_poisoning"
+}
+
+// Execute - Выполняет проверку
+func (c *AddressPoisoningCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking address poisoning for address: %s", address)
+
+	var truncated bool
+	txs, err := c.etherscan.GetTransactions(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	tokenTxs, err := c.etherscan.GetTokenTransfers(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, fmt.Errorf("failed to get token transfers: %w", err)
+	}
+
+	poisoned := c.newDetector(address).detect(txs, tokenTxs)
+
+	riskFound := len(poisoned) > 0
+	riskLevel := entity.RiskLevelLow
+	var scorePenalty float64
+	var details string
+
+	if riskFound {
+		riskLevel = entity.RiskLevelMedium
+		var fundsSent int
+		for _, info := range poisoned {
+			if info.SentTx != "" {
+				fundsSent++
+				riskLevel = entity.RiskLevelCritical
+			} else if info.ImitatedAddress != "" && riskLevel != entity.RiskLevelCritical {
+				riskLevel = entity.RiskLevelHigh
+			}
+		}
+		scorePenalty = c.cfg.Weight("address_poisoning") * 100
+		details = fmt.Sprintf("Found %d poisoned addresses in transaction history, do not copy addresses from history", len(poisoned))
+		if fundsSent > 0 {
+			details += fmt.Sprintf("; funds were already sent to %d of them", fundsSent)
+		}
+	} else {
+		details = "No address poisoning found"
+	}
+	if truncated {
+		details += truncatedHistoryNote
+	}
+
+	return &entity.CheckResult{
+		CheckName:    c.Name(),
+		RiskFound:    riskFound,
+		RiskLevel:    riskLevel,
+		ScorePenalty: scorePenalty,
+		Details:      details,
+		RawData:      poisoned,
+	}, nil
+}
+
+// newDetector - Детектор с порогами из конфигурации и символами токенов из реестра
+func (c *AddressPoisoningCheck) newDetector(wallet string) *poisoningDetector {
+	cfg := c.cfg.Checks.AddressPoisoning
+	d := &poisoningDetector{
+		wallet:    strings.ToLower(wallet),
+		prefix:    cfg.PrefixChars,
+		suffix:    cfg.SuffixChars,
+		protected: make(map[string]map[string]bool),
+		trusted:   c.registry.IsAllowed,
+	}
+	if d.prefix <= 0 {
+		d.prefix = defaultPoisoningPrefixChars
+	}
+	if d.suffix <= 0 {
+		d.suffix = defaultPoisoningSuffixChars
+	}
+	for _, entry := range c.registry.Entries(registry.CategoryStablecoin, registry.CategoryInfrastructure) {
+		d.protect(entry.Label, entry.Address)
+	}
+	return d
+}
+
+// poisoningDetector - Поиск отравления истории по транзакциям кошелька
+type poisoningDetector struct {
+	wallet string
+	prefix int
+	suffix int
+	// protected - Нормализованный символ токена -> настоящие контракты
+	protected map[string]map[string]bool
+	// trusted - Доверенные адреса не считаются двойниками
+	trusted func(string) bool
+}
+
+// protect - Добавляет символ токена и его настоящий контракт
+func (d *poisoningDetector) protect(symbol, contract string) {
+	symbol = normalizeSymbol(symbol)
+	if symbol == "" {
+		return
+	}
+	if d.protected[symbol] == nil {
+		d.protected[symbol] = make(map[string]bool)
+	}
+	d.protected[symbol][strings.ToLower(contract)] = true
+}
+
+// poisoningSend - Первый перевод кошелька получателю
+type poisoningSend struct {
+	at   time.Time
+	hash string
+}
+
+// detect - Находит адреса-двойники и отправителей поддельных токенов.
+// Реальные контрагенты - получатели транзакций, подписанных кошельком. Переводы в транзакциях,
+// которые кошелек не подписывал (чужие transferFrom, входящие переводы), проверяются на сходство с ними.
+// Получатель, который похож на более раннего контрагента и до первого перевода кошелька появлялся только
+// в неподписанных переводах, считается скопированным из истории двойником (funds_sent), а не контрагентом.
+func (d *poisoningDetector) detect(txs []provider.Transaction, tokenTxs []provider.TokenTransaction) []entity.PoisonedAddressInfo {
+	signed := make(map[string]bool)
+	sends := make(map[string]poisoningSend)
+	send := func(to, hash, timestamp string) {
+		to = strings.ToLower(to)
+		at := parseUnixTime(timestamp)
+		if first, ok := sends[to]; !ok || (!at.IsZero() && (first.at.IsZero() || at.Before(first.at))) {
+			sends[to] = poisoningSend{at: at, hash: hash}
+		}
+	}
+	for _, tx := range txs {
+		if strings.ToLower(tx.From) == d.wallet {
+			signed[tx.Hash] = true
+			if tx.To != "" {
+				send(tx.To, tx.Hash, tx.TimeStamp)
+			}
+		}
+	}
+	for _, tx := range tokenTxs {
+		if signed[tx.Hash] && strings.ToLower(tx.From) == d.wallet && tx.Value != "0" && !d.isFakeToken(tx) {
+			send(tx.To, tx.Hash, tx.TimeStamp)
+		}
+	}
+
+	// Первое появление адресов в переводах, которые кошелек не подписывал
+	unsigned := make(map[string]time.Time)
+	seenUnsigned := func(other, timestamp string) {
+		at := parseUnixTime(timestamp)
+		if first, ok := unsigned[other]; other != "" && !at.IsZero() && (!ok || at.Before(first)) {
+			unsigned[other] = at
+		}
+	}
+	for _, tx := range tokenTxs {
+		if !signed[tx.Hash] {
+			seenUnsigned(d.otherParty(tx.From, tx.To), tx.TimeStamp)
+		}
+	}
+	for _, tx := range txs {
+		if strings.ToLower(tx.To) == d.wallet && strings.ToLower(tx.From) != d.wallet {
+			seenUnsigned(strings.ToLower(tx.From), tx.TimeStamp)
+		}
+	}
+
+	// Получатели в порядке первого перевода: двойник сравнивается только с уже признанными контрагентами,
+	// а имитируемым считается самый ранний из похожих
+	recipients := make([]string, 0, len(sends))
+	for addr := range sends {
+		recipients = append(recipients, addr)
+	}
+	sort.Slice(recipients, func(i, j int) bool {
+		a, b := sends[recipients[i]], sends[recipients[j]]
+		if !a.at.Equal(b.at) {
+			return a.at.Before(b.at)
+		}
+		return recipients[i] < recipients[j]
+	})
+	counterparties := make(map[string]bool)
+	copied := make(map[string]poisoningSend)
+	var known []string
+	for _, addr := range recipients {
+		first := sends[addr]
+		if baited, ok := unsigned[addr]; ok && !first.at.IsZero() && baited.Before(first.at) && !d.trusted(addr) {
+			if d.imitated(addr, known) != "" {
+				copied[addr] = first
+				continue
+			}
+		}
+		counterparties[addr] = true
+		known = append(known, addr)
+	}
+
+	findings := make(map[string]*entity.PoisonedAddressInfo)
+	record := func(other, hash, timestamp, fakeToken string, zeroValue bool) {
+		if other == "" || other == d.wallet || other == zeroAddress || counterparties[other] || d.trusted(other) {
+			return
+		}
+		imitated := d.imitated(other, known)
+		if imitated == "" && fakeToken == "" {
+			return
+		}
+
+		info, ok := findings[other]
+		if !ok {
+			info = &entity.PoisonedAddressInfo{Address: other, AddressURL: util.GetAdressURL(other), ImitatedAddress: imitated}
+			findings[other] = info
+		}
+		if imitated != "" {
+			info.Kinds = appendUnique(info.Kinds, PoisoningLookalikeAddress)
+		}
+		if zeroValue {
+			info.Kinds = appendUnique(info.Kinds, PoisoningZeroValueTransfer)
+		}
+		if fakeToken != "" {
+			info.Kinds = appendUnique(info.Kinds, PoisoningFakeToken)
+			info.FakeTokens = appendUnique(info.FakeTokens, fakeToken)
+		}
+		info.Transfers++
+		if seen := parseUnixTime(timestamp); !seen.IsZero() && (info.LastSeenAt == nil || seen.After(*info.LastSeenAt)) {
+			info.LastSeenAt = &seen
+			info.ExampleTx = hash
+		} else if info.ExampleTx == "" {
+			info.ExampleTx = hash
+		}
+	}
+
+	for _, tx := range tokenTxs {
+		if signed[tx.Hash] {
+			continue
+		}
+		var fakeToken string
+		if d.isFakeToken(tx) {
+			fakeToken = strings.ToLower(tx.ContractAddress)
+		}
+		record(d.otherParty(tx.From, tx.To), tx.Hash, tx.TimeStamp, fakeToken, tx.Value == "0")
+	}
+	for _, tx := range txs {
+		if strings.ToLower(tx.To) == d.wallet && strings.ToLower(tx.From) != d.wallet {
+			record(strings.ToLower(tx.From), tx.Hash, tx.TimeStamp, "", tx.Value == "0")
+		}
+	}
+
+	for addr, first := range copied {
+		if info, ok := findings[addr]; ok {
+			info.Kinds = appendUnique(info.Kinds, PoisoningFundsSent)
+			info.SentTx = first.hash
+		}
+	}
+
+	result := make([]entity.PoisonedAddressInfo, 0, len(findings))
+	for _, info := range findings {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+	return result
+}
+
+// otherParty - Вторая сторона перевода, в котором участвует кошелек
+func (d *poisoningDetector) otherParty(from, to string) string {
+	from, to = strings.ToLower(from), strings.ToLower(to)
+	switch d.wallet {
+	case from:
+		return to
+	case to:
+		return from
+	}
+	return ""
+}
+
+// imitated - Реальный контрагент, на которого похож адрес
+func (d *poisoningDetector) imitated(address string, counterparties []string) string {
+	for _, counterparty := range counterparties {
+		if isLookalike(address, counterparty, d.prefix, d.suffix) {
+			return counterparty
+		}
+	}
+	return ""
+}
+
+// isFakeToken - Токен с символом известного токена, но не его настоящий контракт
+func (d *poisoningDetector) isFakeToken(tx provider.TokenTransaction) bool {
+	genuine, ok := d.protected[normalizeSymbol(tx.TokenSymbol)]
+	return ok && !genuine[strings.ToLower(tx.ContractAddress)]
+}
+
+// isLookalike - Разные адреса совпадают по первым prefix и последним suffix hex-символам
+func isLookalike(a, b string, prefix, suffix int) bool {
+	a = strings.TrimPrefix(strings.ToLower(a), "0x")
+	b = strings.TrimPrefix(strings.ToLower(b), "0x")
+	if a == b || len(a) != len(b) || len(a) < prefix+suffix {
+		return false
+	}
+	return a[:prefix] == b[:prefix] && a[len(a)-suffix:] == b[len(b)-suffix:]
+}
+
+// normalizeSymbol - Символ токена в верхнем регистре без пробелов и знаков, с заменой похожих букв
+// других алфавитов и полноширинных символов на латинские
+func normalizeSymbol(symbol string) string {
+	var b strings.Builder
+	for _, r := range strings.ToUpper(symbol) {
+		if mapped, ok := homoglyphs[r]; ok {
+			r = mapped
+		}
+		if r >= 'Ａ' && r <= 'Ｚ' {
+			r = 'A' + (r - 'Ａ')
+		}
+		if (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// appendUnique - Добавляет значение, если его еще нет
+func appendUnique(values []string, value string) []string {
+	for _, v := range values {
+		if v == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
+
+// truncatedHistoryNote - Пояснение к Details, когда история кошелька длиннее etherscan.max_results
+const truncatedHistoryNote = "; history is truncated, only the newest records were checked"
+
+// partialHistory - Отделяет обрезанную историю от ошибки: при ErrHistoryTruncated Etherscan
+// вернул самые новые записи, их достаточно для поиска недавних событий
+func partialHistory(err error, truncated *bool) error {
+	if errors.Is(err, provider.ErrHistoryTruncated) {
+		*truncated = true
+		return nil
+	}
+	return err
+}
+
+// parseUnixTime - Время из unix timestamp Etherscan (нулевое при ошибке)
+func parseUnixTime(value string) time.Time {
+	ts, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).UTC()
+}
@@ -0,0 +1,110 @@
+package checks
+
+import (
+	"testing"
+
+	"alpha-hygiene-backend/internal/provider"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	poisonWallet   = "0x9999999999999999999999999999999999999999"
+	realRecipient  = "0xa1b2c3d4e5f60718293a4b5c6d7e8f9012345678"
+	lookalike      = "0xa1b2000000000000000000000000000000005678"
+	realUSDT       = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	fakeUSDT       = "0x1234567890123456789012345678901234567890"
+	fakeUSDTSender = "0x4444444444444444444444444444444444444444"
+)
+
+func TestPoisoningDetector(t *testing.T) {
+	d := &poisoningDetector{
+		wallet:    poisonWallet,
+		prefix:    4,
+		suffix:    4,
+		protected: make(map[string]map[string]bool),
+		trusted:   func(string) bool { return false },
+	}
+	d.protect("USDT", realUSDT)
+
+	txs := []provider.Transaction{
+		// Кошелек сам отправил USDT реальному получателю
+		{Hash: "0x01", From: poisonWallet, To: realUSDT, Value: "0", TimeStamp: "1700000000"},
+		// Пыль от двойника
+		{Hash: "0x02", From: lookalike, To: poisonWallet, Value: "100000000000000", TimeStamp: "1700000100"},
+		// Обычный входящий перевод
+		{Hash: "0x03", From: "0x5555555555555555555555555555555555555555", To: poisonWallet, Value: "1", TimeStamp: "1700000200"},
+	}
+	tokenTxs := []provider.TokenTransaction{
+		{Hash: "0x01", From: poisonWallet, To: realRecipient, ContractAddress: realUSDT, TokenSymbol: "USDT", Value: "5000000", TimeStamp: "1700000000"},
+		// Нулевой transferFrom от имени кошелька на двойника
+		{Hash: "0x04", From: poisonWallet, To: lookalike, ContractAddress: realUSDT, TokenSymbol: "USDT", Value: "0", TimeStamp: "1700000300"},
+		// Поддельный USDT (кириллическая Т)
+		{Hash: "0x05", From: fakeUSDTSender, To: poisonWallet, ContractAddress: fakeUSDT, TokenSymbol: "USDТ", Value: "5000000", TimeStamp: "1700000400"},
+	}
+
+	result := d.detect(txs, tokenTxs)
+	require.Len(t, result, 2)
+
+	poisoned := result[1]
+	assert.Equal(t, lookalike, poisoned.Address)
+	assert.Equal(t, realRecipient, poisoned.ImitatedAddress)
+	assert.Equal(t, []string{PoisoningLookalikeAddress, PoisoningZeroValueTransfer}, poisoned.Kinds)
+	assert.Equal(t, 2, poisoned.Transfers)
+	assert.Equal(t, "0x04", poisoned.ExampleTx)
+
+	fake := result[0]
+	assert.Equal(t, fakeUSDTSender, fake.Address)
+	assert.Empty(t, fake.ImitatedAddress)
+	assert.Equal(t, []string{PoisoningFakeToken}, fake.Kinds)
+	assert.Equal(t, []string{fakeUSDT}, fake.FakeTokens)
+}
+
+func TestNormalizeSymbolAndLookalike(t *testing.T) {
+	assert.Equal(t, "USDT", normalizeSymbol("usdt"))
+	assert.Equal(t, "USDT", normalizeSymbol("USDТ"))
+	assert.Equal(t, "USDC", normalizeSymbol("ＵＳＤＣ"))
+	assert.Equal(t, "USDC", normalizeSymbol(" $USDC "))
+
+	assert.True(t, isLookalike(realRecipient, lookalike, 4, 4))
+	assert.False(t, isLookalike(realRecipient, realRecipient, 4, 4))
+	assert.False(t, isLookalike(realRecipient, lookalike, 5, 4))
+}
+
+func TestPoisoningDetector_FundsSentToCopiedLookalike(t *testing.T) {
+	const knownPeer = "0xa1b2111111111111111111111111111111115678"
+	d := &poisoningDetector{
+		wallet:    poisonWallet,
+		prefix:    4,
+		suffix:    4,
+		protected: make(map[string]map[string]bool),
+		trusted:   func(string) bool { return false },
+	}
+	d.protect("USDT", realUSDT)
+
+	txs := []provider.Transaction{
+		// Кошелек отправил USDT реальному получателю
+		{Hash: "0x01", From: poisonWallet, To: realUSDT, Value: "0", TimeStamp: "1700000000"},
+		// Пыль от двойника, затем кошелек копирует его адрес из истории и отправляет ETH
+		{Hash: "0x02", From: lookalike, To: poisonWallet, Value: "100000000000000", TimeStamp: "1700000100"},
+		{Hash: "0x04", From: poisonWallet, To: lookalike, Value: "2000000000000000000", TimeStamp: "1700000300"},
+		// Похожий адрес, которому кошелек платил раньше любых неподписанных переводов, остается контрагентом
+		{Hash: "0x05", From: poisonWallet, To: knownPeer, Value: "1", TimeStamp: "1700000050"},
+		{Hash: "0x06", From: knownPeer, To: poisonWallet, Value: "1", TimeStamp: "1700000400"},
+	}
+	tokenTxs := []provider.TokenTransaction{
+		{Hash: "0x01", From: poisonWallet, To: realRecipient, ContractAddress: realUSDT, TokenSymbol: "USDT", Value: "5000000", TimeStamp: "1700000000"},
+		{Hash: "0x03", From: poisonWallet, To: lookalike, ContractAddress: realUSDT, TokenSymbol: "USDT", Value: "0", TimeStamp: "1700000200"},
+	}
+
+	result := d.detect(txs, tokenTxs)
+	require.Len(t, result, 1)
+
+	poisoned := result[0]
+	assert.Equal(t, lookalike, poisoned.Address)
+	assert.Equal(t, realRecipient, poisoned.ImitatedAddress)
+	assert.Equal(t, []string{PoisoningLookalikeAddress, PoisoningZeroValueTransfer, PoisoningFundsSent}, poisoned.Kinds)
+	assert.Equal(t, "0x04", poisoned.SentTx)
+	assert.Equal(t, 2, poisoned.Transfers)
+}
@@ -0,0 +1,123 @@
+package checks
+
+import (
+	"time"
+
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
+	"alpha-hygiene-backend/internal/provider"
+)
+
+// defaultFreshContractDays - Сколько дней после деплоя контракт спендера считается новым по умолчанию
+const defaultFreshContractDays = 30
+
+// Коды причин, по которым approval считается рискованным (ApprovalInfo.Reasons)
+const (
+	ApprovalReasonBlockedSpender   = "blocked_spender"
+	ApprovalReasonBlockedToken     = "blocked_token"
+	ApprovalReasonMaliciousToken   = "malicious_token"
+	ApprovalReasonMaliciousSpender = "malicious_spender"
+	ApprovalReasonSpenderEOA       = "spender_eoa"
+	ApprovalReasonFreshUnverified  = "fresh_unverified_contract"
+	ApprovalReasonFreshContract    = "fresh_contract"
+	ApprovalReasonDoubtList        = "doubt_list"
+	ApprovalReasonUnlimited        = "unlimited"
+)
+
+// approvalFacts - Сведения об одном approval, по которым срабатывают правила
+type approvalFacts struct {
+	blockedSpender bool
+	blockedToken   bool
+	allowedSpender bool
+	// maliciousToken, maliciousSpender, doubtList - Вердикты GoPlus
+	maliciousToken   bool
+	maliciousSpender bool
+	doubtList        bool
+	unlimited        bool
+	// spenderKnown - Известно, контракт ли спендер (из address_info GoPlus или метки спендера)
+	spenderKnown      bool
+	spenderIsContract bool
+	// spenderVerified - Исходный код контракта опубликован (nil - неизвестно)
+	spenderVerified *bool
+	// spenderAge - Возраст контракта спендера (0 - дата деплоя неизвестна)
+	spenderAge time.Duration
+	freshAge   time.Duration
+}
+
+// fresh - Контракт спендера задеплоен недавно
+func (f approvalFacts) fresh() bool {
+	return f.spenderIsContract && f.spenderAge > 0 && f.spenderAge < f.freshAge
+}
+
+// approvalRule - Правило оценки approval: код причины, уровень риска и условие
+type approvalRule struct {
+	code      string
+	level     entity.RiskLevel
+	malicious bool
+	match     func(f approvalFacts) bool
+}
+
+// approvalRules - Правила оценки approvals. Approval рискованный, если сработало хотя бы одно правило;
+// уровень риска - максимальный среди сработавших. Approval на EOA или на новый контракт с закрытым кодом -
+// типичная схема дрейнеров, доверенные спендеры реестра по этим правилам не проверяются.
+var approvalRules = []approvalRule{
+	{ApprovalReasonBlockedSpender, entity.RiskLevelCritical, true, func(f approvalFacts) bool { return f.blockedSpender }},
+	{ApprovalReasonBlockedToken, entity.RiskLevelCritical, true, func(f approvalFacts) bool { return f.blockedToken }},
+	{ApprovalReasonMaliciousToken, entity.RiskLevelCritical, true, func(f approvalFacts) bool { return f.maliciousToken }},
+	{ApprovalReasonMaliciousSpender, entity.RiskLevelCritical, true, func(f approvalFacts) bool { return f.maliciousSpender }},
+	{ApprovalReasonSpenderEOA, entity.RiskLevelCritical, false, func(f approvalFacts) bool {
+		return !f.allowedSpender && f.spenderKnown && !f.spenderIsContract
+	}},
+	{ApprovalReasonFreshUnverified, entity.RiskLevelHigh, false, func(f approvalFacts) bool {
+		return !f.allowedSpender && f.fresh() && f.spenderVerified != nil && !*f.spenderVerified
+	}},
+	{ApprovalReasonFreshContract, entity.RiskLevelMedium, false, func(f approvalFacts) bool {
+		return !f.allowedSpender && f.fresh() && (f.spenderVerified == nil || *f.spenderVerified)
+	}},
+	{ApprovalReasonDoubtList, entity.RiskLevelHigh, false, func(f approvalFacts) bool { return f.doubtList }},
+	{ApprovalReasonUnlimited, entity.RiskLevelHigh, false, func(f approvalFacts) bool { return f.unlimited }},
+}
+
+// evaluateApproval - Применяет правила к approval: коды сработавших правил, максимальный уровень риска
+// и признак вредоносности. Без сработавших правил уровень пустой.
+func evaluateApproval(f approvalFacts) (reasons []string, level entity.RiskLevel, malicious bool) {
+	for _, rule := range approvalRules {
+		if !rule.match(f) {
+			continue
+		}
+		reasons = append(reasons, rule.code)
+		if riskRank(rule.level) > riskRank(level) {
+			level = rule.level
+		}
+		malicious = malicious || rule.malicious
+	}
+	return reasons, level, malicious
+}
+
+// withSpenderProfile - Дополняет факты сведениями о спендере: метка (реестр, GoPlus, Etherscan)
+// приоритетнее address_info из ответа GoPlus
+func (f approvalFacts) withSpenderProfile(label *entity.AddressLabel, info provider.AddressInfo, now time.Time) approvalFacts {
+	var deployedAt time.Time
+	switch {
+	case label != nil && label.IsContract != nil:
+		f.spenderKnown = true
+		f.spenderIsContract = *label.IsContract
+		f.spenderVerified = label.Verified
+		if label.DeployedAt != nil {
+			deployedAt = *label.DeployedAt
+		}
+	default:
+		hint := labels.HintFromAddressInfo(info)
+		if hint == nil {
+			break
+		}
+		f.spenderKnown = true
+		f.spenderIsContract = *hint.IsContract
+		f.spenderVerified = hint.Verified
+		deployedAt = hint.DeployedAt
+	}
+	if !deployedAt.IsZero() {
+		f.spenderAge = now.Sub(deployedAt)
+	}
+	return f
+}
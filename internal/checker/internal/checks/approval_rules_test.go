@@ -0,0 +1,112 @@
+package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateApproval(t *testing.T) {
+	yes, no := true, false
+	const freshAge = 30 * 24 * time.Hour
+	contract := func(age time.Duration, verified *bool) approvalFacts {
+		return approvalFacts{spenderKnown: true, spenderIsContract: true, spenderVerified: verified, spenderAge: age, freshAge: freshAge}
+	}
+
+	tests := []struct {
+		name      string
+		facts     approvalFacts
+		reasons   []string
+		level     entity.RiskLevel
+		malicious bool
+	}{
+		{"old verified contract", contract(400*24*time.Hour, &yes), nil, "", false},
+		{"unknown spender", approvalFacts{freshAge: freshAge}, nil, "", false},
+		{"EOA spender", approvalFacts{spenderKnown: true, freshAge: freshAge}, []string{ApprovalReasonSpenderEOA}, entity.RiskLevelCritical, false},
+		{"allowed EOA spender", approvalFacts{spenderKnown: true, allowedSpender: true, freshAge: freshAge}, nil, "", false},
+		{"fresh unverified contract", contract(3*24*time.Hour, &no), []string{ApprovalReasonFreshUnverified}, entity.RiskLevelHigh, false},
+		{"fresh verified contract", contract(3*24*time.Hour, &yes), []string{ApprovalReasonFreshContract}, entity.RiskLevelMedium, false},
+		{"fresh contract, verification unknown", contract(3*24*time.Hour, nil), []string{ApprovalReasonFreshContract}, entity.RiskLevelMedium, false},
+		{"old unverified contract", contract(400*24*time.Hour, &no), nil, "", false},
+		{"unlimited to fresh unverified contract", func() approvalFacts {
+			f := contract(time.Hour, &no)
+			f.unlimited = true
+			return f
+		}(), []string{ApprovalReasonFreshUnverified, ApprovalReasonUnlimited}, entity.RiskLevelHigh, false},
+		{"blocked spender", approvalFacts{blockedSpender: true, doubtList: true}, []string{ApprovalReasonBlockedSpender, ApprovalReasonDoubtList}, entity.RiskLevelCritical, true},
+		{"malicious token", approvalFacts{maliciousToken: true}, []string{ApprovalReasonMaliciousToken}, entity.RiskLevelCritical, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reasons, level, malicious := evaluateApproval(tt.facts)
+			assert.Equal(t, tt.reasons, reasons)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.malicious, malicious)
+		})
+	}
+}
+
+func TestApprovalFacts_WithSpenderProfile(t *testing.T) {
+	now := time.Unix(1750000000, 0)
+	isContract, verified := false, true
+
+	// Метка спендера приоритетнее address_info
+	facts := approvalFacts{}.withSpenderProfile(&entity.AddressLabel{IsContract: &isContract}, provider.AddressInfo{IsContract: 1}, now)
+	assert.True(t, facts.spenderKnown)
+	assert.False(t, facts.spenderIsContract)
+
+	facts = approvalFacts{}.withSpenderProfile(nil, provider.AddressInfo{IsContract: 1, IsOpenSource: 1, DeployedTime: now.Unix() - 3600}, now)
+	assert.True(t, facts.spenderIsContract)
+	assert.Equal(t, &verified, facts.spenderVerified)
+	assert.Equal(t, time.Hour, facts.spenderAge)
+
+	// Пустой address_info (approvals из собственного узла) ничего не говорит о спендере
+	facts = approvalFacts{}.withSpenderProfile(nil, provider.AddressInfo{}, now)
+	assert.False(t, facts.spenderKnown)
+}
+
+func TestApprovalsCheck_FreshUnverifiedSpender(t *testing.T) {
+	const (
+		usdc   = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		fresh  = "0x5555555555555555555555555555555555555555"
+		router = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+	)
+	now := time.Now()
+
+	approvals := fakeApprovals{resp: &provider.TokenApprovalResponse{Result: []provider.TokenApproval{{
+		TokenAddress: usdc,
+		TokenName:    "USD Coin",
+		Decimals:     6,
+		Balance:      "2500000",
+		ApprovedList: []provider.ApprovedSpender{
+			{ApprovedContract: fresh, ApprovedAmount: "1000000", AddressInfo: provider.AddressInfo{IsContract: 1, DeployedTime: now.AddDate(0, 0, -2).Unix()}},
+			{ApprovedContract: router, ApprovedAmount: "1000000", AddressInfo: provider.AddressInfo{IsContract: 1, IsOpenSource: 1, DeployedTime: now.AddDate(-5, 0, 0).Unix()}},
+		},
+	}}}}
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	result, err := NewApprovalsCheck(approvals, nil, nil, reg, nil, cfg, log).Execute(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	require.NoError(t, err)
+
+	infos, ok := result.RawData.([]entity.ApprovalInfo)
+	require.True(t, ok)
+	require.Len(t, infos, 1)
+	assert.Equal(t, fresh, infos[0].SpenderAddress)
+	assert.Equal(t, []string{ApprovalReasonFreshUnverified}, infos[0].Reasons)
+	assert.Equal(t, entity.RiskLevelCritical, infos[0].RiskLevel, "balance at stake escalates HIGH to CRITICAL")
+	assert.Equal(t, entity.RiskLevelCritical, result.RiskLevel)
+}
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"strings"
+	"time"
 
 	"alpha-hygiene-backend/config"
 	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
 	"alpha-hygiene-backend/pkg/util"
 
 	"github.com/sirupsen/logrus"
@@ -29,22 +33,41 @@ import (
 // 		Посмотрите входящие транзакции на адреса, помеченные как Exploit или Heist.
 // Поищите "Orbit Bridge Exploiter" или "Multichain Exploiter" на Etherscan. Входящие переводы шли от пострадавших пользователей.
 
-// ApprovalsCheck - Проверка токен approvals
+// ApprovalViaPermit2 - Значение ApprovalInfo.Via для allowance внутри Permit2
+const ApprovalViaPermit2 = "permit2"
+
+// ApprovalReasonPermit2 - Причина для каждого allowance внутри Permit2 (approval второго уровня)
+const ApprovalReasonPermit2 = "permit2_allowance"
+
+// permit2Grant - ERC-20 approval токена на сам Permit2
+type permit2Grant struct {
+	token  provider.TokenApproval
+	amount string
+}
+
+// ApprovalsCheck - Проверка токен approvals. Если кошелек выдал approval на Uniswap Permit2,
+// allowance внутри Permit2 добавляются в отчет как approvals второго уровня (Via = "permit2").
 type ApprovalsCheck struct {
-	goplusProvider *provider.GoPlusClient
-	etherscan      *provider.EtherscanClient
-	cfg            *config.Config
-	log            *logrus.Entry
+	approvals provider.ApprovalProvider
+	permit2   provider.Permit2Provider
+	etherscan *provider.EtherscanClient
+	registry  *registry.Registry
+	labeler   *labels.Labeler
+	cfg       *config.Config
+	log       *logrus.Entry
 }
 
 // NewApprovalsCheck - Создает новую проверку approvals
-func NewApprovalsCheck(goplusProvider *provider.GoPlusClient, etherscan *provider.EtherscanClient, cfg *config.Config, log *logrus.Entry) *ApprovalsCheck {
+func NewApprovalsCheck(approvals provider.ApprovalProvider, permit2 provider.Permit2Provider, etherscan *provider.EtherscanClient, reg *registry.Registry, labeler *labels.Labeler, cfg *config.Config, log *logrus.Entry) *ApprovalsCheck {
 	logger := log.WithFields(logrus.Fields{"component": "approvals"})
 	return &ApprovalsCheck{
-		goplusProvider: goplusProvider,
-		etherscan:      etherscan,
-		cfg:            cfg,
-		log:            logger,
+		approvals: approvals,
+		permit2:   permit2,
+		etherscan: etherscan,
+		registry:  reg,
+		labeler:   labeler,
+		cfg:       cfg,
+		log:       logger,
 	}
 }
 
@@ -57,107 +80,218 @@ func (c *ApprovalsCheck) Name() string {
 func (c *ApprovalsCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
 	c.log.Debugf("Checking approvals for address: %s", address)
 
-	// Получаем данные из GoPlus API
-	resp, err := c.goplusProvider.GetTokenApprovals(ctx, address)
+	// Получаем данные из источника approvals (GoPlus API или собственный узел)
+	resp, err := c.approvals.GetTokenApprovals(ctx, address)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get token approvals: %w", err)
 	}
 
-	// Анализируем результаты
-	var riskyApprovals []entity.ApprovalInfo
+	// Описания всех спендеров (реестр, address_info GoPlus, Etherscan) нужны правилам оценки:
+	// approval на EOA или на новый контракт с закрытым кодом считается рискованным
+	type candidate struct {
+		token    provider.TokenApproval
+		approval provider.ApprovedSpender
+	}
+	var candidates []candidate
+	spenderHints := make(map[string]*labels.Hint)
+	permit2Grants := make(map[string]permit2Grant)
 	for _, tokenApproval := range resp.Result {
 		for _, approval := range tokenApproval.ApprovedList {
-			var isRisky bool
-
-			// Определяем, является ли approval рискованным
-			switch {
-			case tokenApproval.MaliciousAddress > 0:
-				isRisky = true
-			case approval.ApprovedAmount == "Unlimited":
-				isRisky = true
-			case approval.AddressInfo.DoubtList > 0:
-				isRisky = true
-			case len(approval.AddressInfo.MaliciousBehavior) > 0:
-				isRisky = true
+			if strings.EqualFold(approval.ApprovedContract, provider.Permit2Address) && approval.ApprovedAmount != "0" {
+				permit2Grants[strings.ToLower(tokenApproval.TokenAddress)] = permit2Grant{token: tokenApproval, amount: approval.ApprovedAmount}
 			}
+			spenderHints[strings.ToLower(approval.ApprovedContract)] = labels.HintFromAddressInfo(approval.AddressInfo)
+			candidates = append(candidates, candidate{token: tokenApproval, approval: approval})
+		}
+	}
 
-			if isRisky {
-				// Calculate exposure balance
-				exposureBalance := calculateExposureBalance(
-					approval.ApprovedAmount,
-					tokenApproval.Balance,
-					tokenApproval.Decimals,
-				)
-
-				riskyApprovals = append(riskyApprovals, entity.ApprovalInfo{
-					TokenAddress:    tokenApproval.TokenAddress,
-					TokenURL:        util.GetAdressURL(tokenApproval.TokenAddress),
-					TokenName:       tokenApproval.TokenName,
-					SpenderAddress:  approval.ApprovedContract,
-					SpenderURL:      util.GetAdressURL(approval.ApprovedContract),
-					ExposureBalance: exposureBalance,
-					IsUnlimited:     approval.ApprovedAmount == "Unlimited",
-					IsMalicious:     tokenApproval.MaliciousAddress > 0 || len(approval.AddressInfo.MaliciousBehavior) > 0,
-				})
+	// Approvals второго уровня: allowance внутри Permit2 по токенам, разрешенным Permit2
+	var permit2Allowances []provider.Permit2Allowance
+	var permit2Err error
+	if len(permit2Grants) > 0 && c.permit2 != nil {
+		permit2Allowances, permit2Err = c.permit2.GetPermit2Allowances(ctx, address)
+		if permit2Err != nil {
+			c.log.Warnf("Failed to get Permit2 allowances for address %s: %v", address, permit2Err)
+		}
+		for _, allowance := range permit2Allowances {
+			if _, ok := spenderHints[allowance.Spender]; !ok {
+				spenderHints[allowance.Spender] = nil
 			}
 		}
 	}
 
+	spenderLabels := c.labeler.LabelAll(ctx, spenderHints)
+	now := time.Now()
+
+	// Анализируем результаты по таблице правил (approval_rules.go)
+	var riskyApprovals []entity.ApprovalInfo
+	for _, item := range candidates {
+		tokenApproval, approval := item.token, item.approval
+		facts := c.facts(tokenApproval.TokenAddress, approval.ApprovedContract, approval.ApprovedAmount, now)
+		facts.maliciousToken = tokenApproval.MaliciousAddress > 0
+		facts.maliciousSpender = len(approval.AddressInfo.MaliciousBehavior) > 0
+		facts.doubtList = approval.AddressInfo.DoubtList > 0
+		facts = facts.withSpenderProfile(labelFor(spenderLabels, approval.ApprovedContract), approval.AddressInfo, now)
+
+		reasons, level, malicious := evaluateApproval(facts)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		// Calculate exposure balance
+		exposureBalance := calculateExposureBalance(
+			approval.ApprovedAmount,
+			tokenApproval.Balance,
+			tokenApproval.Decimals,
+		)
+
+		riskyApprovals = append(riskyApprovals, entity.ApprovalInfo{
+			TokenAddress:    tokenApproval.TokenAddress,
+			TokenURL:        util.GetAdressURL(tokenApproval.TokenAddress),
+			TokenName:       tokenApproval.TokenName,
+			SpenderAddress:  approval.ApprovedContract,
+			SpenderURL:      util.GetAdressURL(approval.ApprovedContract),
+			ApprovedAmount:  approval.ApprovedAmount,
+			ExposureBalance: exposureBalance,
+			IsUnlimited:     approval.ApprovedAmount == "Unlimited",
+			IsMalicious:     malicious,
+			RiskLevel:       approvalRiskLevel(level, exposureBalance),
+			Reasons:         reasons,
+		})
+	}
+
+	var viaPermit2 int
+	if len(permit2Allowances) > 0 {
+		second := c.permit2Approvals(permit2Allowances, permit2Grants, spenderLabels, now)
+		viaPermit2 = len(second)
+		riskyApprovals = append(riskyApprovals, second...)
+	}
+
+	// Описания спендеров в отчете - только для рискованных approvals
+	reported := make(map[string]entity.AddressLabel)
+	for i := range riskyApprovals {
+		spender := strings.ToLower(riskyApprovals[i].SpenderAddress)
+		if label, ok := spenderLabels[spender]; ok {
+			riskyApprovals[i].SpenderLabel = &label
+			reported[spender] = label
+		}
+	}
+
 	// Рассчитываем штраф
 	var scorePenalty float64
 	var details string
 	riskFound := len(riskyApprovals) > 0
 
 	if riskFound {
-		scorePenalty = c.cfg.Scoring.Weights["approvals"] * 100
+		scorePenalty = c.cfg.Weight("approvals") * 100
 		details = fmt.Sprintf("Found %d risky approvals", len(riskyApprovals))
+		if viaPermit2 > 0 {
+			details += fmt.Sprintf(" (%d via Permit2)", viaPermit2)
+		}
 	} else {
 		details = "No risky approvals found"
 	}
+	if permit2Err != nil {
+		details += "; Permit2 allowances are unavailable"
+	}
 
 	return &entity.CheckResult{
-		CheckName:    c.Name(),
-		RiskFound:    riskFound,
-		RiskLevel:    c.determineMaxRiskLevel(riskyApprovals),
-		ScorePenalty: scorePenalty,
-		Details:      details,
-		RawData:      riskyApprovals,
+		CheckName:      c.Name(),
+		RiskFound:      riskFound,
+		RiskLevel:      c.determineMaxRiskLevel(riskyApprovals),
+		ScorePenalty:   scorePenalty,
+		Details:        details,
+		RawData:        riskyApprovals,
+		Counterparties: labels.Sorted(reported),
 	}, nil
 }
 
-// determineMaxRiskLevel - Определяет максимальный уровень риска
-func (c *ApprovalsCheck) determineMaxRiskLevel(approvals []entity.ApprovalInfo) entity.RiskLevel {
-	maxLevel := entity.RiskLevelLow
-
-	for _, approval := range approvals {
-		var level entity.RiskLevel
-		switch {
-		case approval.IsMalicious:
-			level = entity.RiskLevelCritical
-		case approval.ExposureBalance > 0:
-			level = entity.RiskLevelCritical
-		case approval.IsUnlimited:
-			level = entity.RiskLevelHigh
+// permit2Approvals - Действующие allowance внутри Permit2 по токенам, на которые у Permit2 есть ERC-20 approval.
+// Учитывается любой ненулевой approval на Permit2, не только неограниченный: списать через Permit2 можно
+// не больше, чем разрешено самому Permit2, поэтому экспозиция ограничена обоими approvals и балансом.
+// Истекшие allowance пропускаются; в отчет, как и approvals первого уровня, попадают только allowance,
+// на которых сработали правила, с дополнительной причиной permit2_allowance.
+func (c *ApprovalsCheck) permit2Approvals(allowances []provider.Permit2Allowance, grants map[string]permit2Grant, spenderLabels map[string]entity.AddressLabel, now time.Time) []entity.ApprovalInfo {
+	var result []entity.ApprovalInfo
+	for _, allowance := range allowances {
+		grant, ok := grants[allowance.Token]
+		if !ok || allowance.Amount == "0" || allowance.IsExpired(now.Unix()) {
+			continue
 		}
 
-		if c.isHigherRisk(level, maxLevel) {
-			maxLevel = level
+		facts := c.facts(grant.token.TokenAddress, allowance.Spender, allowance.Amount, now)
+		facts.maliciousToken = grant.token.MaliciousAddress > 0
+		facts = facts.withSpenderProfile(labelFor(spenderLabels, allowance.Spender), provider.AddressInfo{}, now)
+		reasons, level, malicious := evaluateApproval(facts)
+		if len(reasons) == 0 {
+			continue
 		}
+
+		exposure := min(
+			calculateExposureBalance(allowance.Amount, grant.token.Balance, grant.token.Decimals),
+			calculateExposureBalance(grant.amount, grant.token.Balance, grant.token.Decimals),
+		)
+		expiresAt := time.Unix(allowance.Expiration, 0).UTC()
+
+		result = append(result, entity.ApprovalInfo{
+			TokenAddress:    grant.token.TokenAddress,
+			TokenURL:        util.GetAdressURL(grant.token.TokenAddress),
+			TokenName:       grant.token.TokenName,
+			SpenderAddress:  allowance.Spender,
+			SpenderURL:      util.GetAdressURL(allowance.Spender),
+			ApprovedAmount:  allowance.Amount,
+			ExposureBalance: exposure,
+			IsUnlimited:     allowance.Amount == "Unlimited",
+			IsMalicious:     malicious,
+			Via:             ApprovalViaPermit2,
+			ExpiresAt:       &expiresAt,
+			RiskLevel:       approvalRiskLevel(level, exposure),
+			Reasons:         append([]string{ApprovalReasonPermit2}, reasons...),
+		})
 	}
+	return result
+}
 
-	return maxLevel
+// facts - Общие для всех approvals факты: реестр, неограниченная сумма, порог "нового" контракта
+func (c *ApprovalsCheck) facts(token, spender, amount string, now time.Time) approvalFacts {
+	freshDays := c.cfg.Checks.Approvals.FreshContractDays
+	if freshDays <= 0 {
+		freshDays = defaultFreshContractDays
+	}
+	return approvalFacts{
+		blockedSpender: c.registry.IsBlocked(spender),
+		blockedToken:   c.registry.IsBlocked(token),
+		allowedSpender: c.registry.IsAllowed(spender),
+		unlimited:      amount == "Unlimited",
+		freshAge:       time.Duration(freshDays) * 24 * time.Hour,
+	}
 }
 
-// isHigherRisk - Сравнивает уровни риска
-func (c *ApprovalsCheck) isHigherRisk(a, b entity.RiskLevel) bool {
-	levels := map[entity.RiskLevel]int{
-		entity.RiskLevelLow:      1,
-		entity.RiskLevelMedium:   2,
-		entity.RiskLevelHigh:     3,
-		entity.RiskLevelCritical: 4,
+// labelFor - Описание спендера из результата LabelAll (nil - нет описания)
+func labelFor(spenderLabels map[string]entity.AddressLabel, spender string) *entity.AddressLabel {
+	if label, ok := spenderLabels[strings.ToLower(spender)]; ok {
+		return &label
 	}
+	return nil
+}
+
+// approvalRiskLevel - Уровень риска approval: при HIGH и выше и ненулевой экспозиции риск CRITICAL
+func approvalRiskLevel(level entity.RiskLevel, exposure float64) entity.RiskLevel {
+	if exposure > 0 && riskRank(level) >= riskRank(entity.RiskLevelHigh) {
+		return entity.RiskLevelCritical
+	}
+	return level
+}
 
-	return levels[a] > levels[b]
+// determineMaxRiskLevel - Определяет максимальный уровень риска
+func (c *ApprovalsCheck) determineMaxRiskLevel(approvals []entity.ApprovalInfo) entity.RiskLevel {
+	maxLevel := entity.RiskLevelLow
+	for _, approval := range approvals {
+		if riskRank(approval.RiskLevel) > riskRank(maxLevel) {
+			maxLevel = approval.RiskLevel
+		}
+	}
+	return maxLevel
 }
 
 // calculateExposureBalance - Calculates exposure balance
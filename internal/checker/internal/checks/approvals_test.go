@@ -0,0 +1,118 @@
+package checks
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeApprovals struct {
+	resp *provider.TokenApprovalResponse
+}
+
+func (f fakeApprovals) GetTokenApprovals(context.Context, string) (*provider.TokenApprovalResponse, error) {
+	return f.resp, nil
+}
+
+type fakePermit2 struct {
+	allowances []provider.Permit2Allowance
+}
+
+func (f fakePermit2) GetPermit2Allowances(context.Context, string) ([]provider.Permit2Allowance, error) {
+	return f.allowances, nil
+}
+
+func TestApprovalsCheck_ReportsPermit2Allowances(t *testing.T) {
+	const (
+		usdc    = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		dai     = "0x6b175474e89094c44da98b954eedeac495271d0f"
+		router  = "0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad"
+		drainer = "0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220"
+	)
+	now := time.Now().Unix()
+
+	approvals := fakeApprovals{resp: &provider.TokenApprovalResponse{Result: []provider.TokenApproval{{
+		TokenAddress: usdc,
+		TokenName:    "USD Coin",
+		Decimals:     6,
+		Balance:      "2500000",
+		ApprovedList: []provider.ApprovedSpender{{ApprovedContract: provider.Permit2Address, ApprovedAmount: "Unlimited"}},
+	}}}}
+	permit2 := fakePermit2{allowances: []provider.Permit2Allowance{
+		{Token: usdc, Spender: router, Amount: "Unlimited", Expiration: now + 3600},
+		{Token: usdc, Spender: drainer, Amount: "1000000", Expiration: now + 3600},
+		// Истекший allowance и allowance по токену без approval на Permit2 не опасны
+		{Token: usdc, Spender: router, Amount: "Unlimited", Expiration: now - 3600},
+		{Token: dai, Spender: drainer, Amount: "Unlimited", Expiration: now + 3600},
+	}}
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	check := NewApprovalsCheck(approvals, permit2, nil, reg, nil, cfg, log)
+	result, err := check.Execute(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	require.NoError(t, err)
+
+	infos, ok := result.RawData.([]entity.ApprovalInfo)
+	require.True(t, ok)
+	require.Len(t, infos, 3)
+
+	assert.Equal(t, provider.Permit2Address, infos[0].SpenderAddress)
+	assert.Empty(t, infos[0].Via)
+
+	assert.Equal(t, router, infos[1].SpenderAddress)
+	assert.Equal(t, ApprovalViaPermit2, infos[1].Via)
+	assert.True(t, infos[1].IsUnlimited)
+	assert.Equal(t, 2.5, infos[1].ExposureBalance)
+	assert.Equal(t, []string{ApprovalReasonPermit2, ApprovalReasonUnlimited}, infos[1].Reasons)
+	require.NotNil(t, infos[1].ExpiresAt)
+
+	assert.Equal(t, drainer, infos[2].SpenderAddress)
+	assert.True(t, infos[2].IsMalicious)
+	assert.Equal(t, []string{ApprovalReasonPermit2, ApprovalReasonBlockedSpender}, infos[2].Reasons)
+	assert.Equal(t, 1.0, infos[2].ExposureBalance)
+
+	assert.Equal(t, entity.RiskLevelCritical, result.RiskLevel)
+	assert.Equal(t, "Found 3 risky approvals (2 via Permit2)", result.Details)
+}
+
+func TestApprovalsCheck_LimitedPermit2AllowanceIsNotRisky(t *testing.T) {
+	const (
+		usdc   = "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+		router = "0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad"
+	)
+
+	approvals := fakeApprovals{resp: &provider.TokenApprovalResponse{Result: []provider.TokenApproval{{
+		TokenAddress: usdc,
+		Decimals:     6,
+		Balance:      "2500000",
+		ApprovedList: []provider.ApprovedSpender{{ApprovedContract: provider.Permit2Address, ApprovedAmount: "5000000"}},
+	}}}}
+	permit2 := fakePermit2{allowances: []provider.Permit2Allowance{
+		{Token: usdc, Spender: router, Amount: "1000000", Expiration: time.Now().Unix() + 3600},
+	}}
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Scoring.Weights = map[string]float64{"approvals": 0.4}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	result, err := NewApprovalsCheck(approvals, permit2, nil, reg, nil, cfg, log).Execute(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	require.NoError(t, err)
+	assert.False(t, result.RiskFound)
+	assert.Zero(t, result.ScorePenalty)
+	assert.Empty(t, result.RawData)
+	assert.Equal(t, "No risky approvals found", result.Details)
+}
@@ -4,30 +4,59 @@ import (
 	"alpha-hygiene-backend/config"
 	"context"
 	"fmt"
+	"math"
 	"math/big"
+	"sort"
 	"strconv"
+	"strings"
 
 	"alpha-hygiene-backend/internal/entity"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
 	"alpha-hygiene-backend/pkg/util"
 
 	"github.com/sirupsen/logrus"
 )
 
-// AssetCompositionCheck - Проверка состава активов
+const (
+	// Пороги по умолчанию (см. config checks.assets)
+	defaultVolatileRatioPercent  = 90
+	defaultMaxHHI                = 0.5
+	defaultMaxSingleAssetPercent = 80
+	defaultDepegPercent          = 2
+
+	// assetCategoryNative, assetCategoryOther - Категории ETH и токенов вне реестра
+	assetCategoryNative = "native"
+	assetCategoryOther  = "other"
+)
+
+// Коды находок проверки состава активов
+const (
+	AssetFindingVolatileRatio = "volatile_ratio"
+	AssetFindingConcentration = "concentration"
+	AssetFindingDominance     = "single_asset_dominance"
+	AssetFindingDepeg         = "stablecoin_depeg"
+)
+
+// AssetCompositionCheck - Проверка состава активов: доля волатильных активов, концентрация портфеля
+// (индекс Херфиндаля-Хиршмана и доля крупнейшего актива) и стейблкоины, потерявшие привязку к $1
 type AssetCompositionCheck struct {
 	goplusProvider *provider.GoPlusClient
-	alchemy        *provider.AlchemyClient
+	balances       provider.BalanceProvider
+	prices         *provider.PriceClient
+	registry       *registry.Registry
 	cfg            *config.Config
 	log            *logrus.Entry
 }
 
 // NewAssetCompositionCheck - Создает новую проверку состава активов
-func NewAssetCompositionCheck(goplusProvider *provider.GoPlusClient, alchemy *provider.AlchemyClient, cfg *config.Config, log *logrus.Entry) *AssetCompositionCheck {
+func NewAssetCompositionCheck(goplusProvider *provider.GoPlusClient, balances provider.BalanceProvider, prices *provider.PriceClient, reg *registry.Registry, cfg *config.Config, log *logrus.Entry) *AssetCompositionCheck {
 	logger := log.WithFields(logrus.Fields{"component": "assets"})
 	return &AssetCompositionCheck{
 		goplusProvider: goplusProvider,
-		alchemy:        alchemy,
+		balances:       balances,
+		prices:         prices,
+		registry:       reg,
 		cfg:            cfg,
 		log:            logger,
 	}
@@ -43,7 +72,7 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 	c.log.Debugf("Checking asset composition for address: %s", address)
 
 	// Получаем список токенов на кошельке
-	tokens, err := c.alchemy.GetERC20Tokens(ctx, address)
+	tokens, err := c.balances.GetERC20Tokens(ctx, address)
 	if err != nil {
 		c.log.Errorf("Failed to get ERC20 tokens for address %s: %v", address, err)
 		return nil, err
@@ -52,7 +81,7 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 	c.log.Debugf("Found %d ERC20 tokens for address %s", len(tokens), address)
 
 	// Получаем баланс ETH для кошелька
-	ethBalance, err := c.alchemy.GetETHBalance(ctx, address)
+	ethBalance, err := c.balances.GetETHBalance(ctx, address)
 
 	if err != nil {
 		c.log.Errorf("Failed to get ETH balance for address %s: %v", address, err)
@@ -61,12 +90,26 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 
 	c.log.Debugf("ETH balance for address %s: %.6f", address, ethBalance)
 
+	// Получаем USD цены ETH и токенов. Если цены недоступны, используем
+	// фиксированную цену ETH и номинал стейблкоинов, как раньше.
+	priceAddresses := []string{provider.NativeTokenAddress}
+	for _, token := range tokens {
+		priceAddresses = append(priceAddresses, token.ContractAddress)
+	}
+	prices, err := c.prices.GetPrices(ctx, priceAddresses)
+	if err != nil {
+		c.log.Warnf("Failed to get token prices for address %s: %v", address, err)
+		prices = map[string]float64{}
+	}
+
 	// Преобразуем токены в нашу структуру TokenInfo
 	var tokenInfos []entity.TokenInfo
 
 	// Добавляем ETH как отдельный токен
-	// Для MVP используем фиксированную цену ETH (в реальности нужно получать из API)
 	ethUSDPrice := 2000.0
+	if price, ok := prices[provider.NativeTokenAddress]; ok {
+		ethUSDPrice = price
+	}
 	if ethBalance > 0 {
 		tokenInfos = append(tokenInfos, entity.TokenInfo{
 			Address:    "0x0000000000000000000000000000000000000000",
@@ -76,6 +119,8 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 			Balance:    ethBalance,
 			USDValue:   ethBalance * ethUSDPrice,
 			IsStable:   false,
+			Category:   assetCategoryNative,
+			Price:      prices[provider.NativeTokenAddress],
 		})
 	}
 
@@ -103,18 +148,17 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 
 		// Преобразуем в читаемый формат
 		balance := new(big.Float).SetInt(balanceInt)
-		decimalsFactor := new(big.Float).SetFloat64(float64(10) * float64(decimals))
+		decimalsFactor := new(big.Float).SetFloat64(math.Pow10(decimals))
 		balanceFloat, _ := new(big.Float).Quo(balance, decimalsFactor).Float64()
 
-		// Для MVP используем фиксированную цену для стабильных токенов и нулевую для других
+		// Токены без известной цены оцениваются по номиналу (стейблкоины) или в ноль
 		var usdValue float64
-		isStable := util.IsTrusted(token.ContractAddress)
-		if isStable {
+		isStable := c.registry.HasCategory(token.ContractAddress, registry.CategoryStablecoin)
+		price, hasPrice := prices[strings.ToLower(token.ContractAddress)]
+		if hasPrice {
+			usdValue = balanceFloat * price
+		} else if isStable {
 			usdValue = balanceFloat
-		} else {
-			// Для нестабильных токенов в MVP можно установить нулевую цену
-			// В реальной реализации нужно получать цены из API (например, CoinGecko, CoinMarketCap)
-			usdValue = 0
 		}
 		tokenInfos = append(tokenInfos, entity.TokenInfo{
 			Address:    token.ContractAddress,
@@ -124,40 +168,47 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 			Balance:    balanceFloat,
 			USDValue:   usdValue,
 			IsStable:   isStable,
+			Category:   c.category(token.ContractAddress),
+			Price:      price,
 		})
 	}
 
-	// Анализируем состав активов
-	var totalStable float64
-	var totalVolatile float64
-	var totalValue float64
-
-	for _, token := range tokenInfos {
-		totalValue += token.USDValue
-		if token.IsStable {
-			totalStable += token.USDValue
-		} else {
-			totalVolatile += token.USDValue
-		}
-	}
+	// Анализируем состав активов: доли, концентрация и стейблкоины без привязки к $1
+	thresholds := assetThresholds(c.cfg.Checks.Assets)
+	info := analyzeComposition(tokenInfos, thresholds)
 
-	riskFound := false
+	riskFound := len(info.Findings) > 0
+	riskLevel := entity.RiskLevelLow
 	var scorePenalty float64
 	var details string
 
-	if totalValue > 0 {
-		stableRatio := (totalStable / totalValue) * 100
-		volatileRatio := (totalVolatile / totalValue) * 100
-
-		if volatileRatio > 90 {
-			riskFound = true
-			scorePenalty = c.cfg.Scoring.Weights["asset_ratio"] * 100
-			details = fmt.Sprintf("High volatile assets ratio: %.1f%%", volatileRatio)
-		} else {
-			details = fmt.Sprintf("Stable assets: %.1f%%, volatile assets: %.1f%%", stableRatio, volatileRatio)
-		}
-	} else {
+	switch {
+	case info.TotalUSD <= 0:
 		details = "No assets found"
+	case riskFound:
+		scorePenalty = c.cfg.Weight("asset_ratio") * 100
+		riskLevel = entity.RiskLevelMedium
+		var parts []string
+		for _, finding := range info.Findings {
+			switch finding {
+			case AssetFindingVolatileRatio:
+				parts = append(parts, fmt.Sprintf("High volatile assets ratio: %.1f%%", info.VolatileRatio))
+			case AssetFindingConcentration:
+				parts = append(parts, fmt.Sprintf("high concentration: HHI %.2f", info.HHI))
+			case AssetFindingDominance:
+				parts = append(parts, fmt.Sprintf("%s is %.1f%% of the portfolio", info.TopAsset, info.TopAssetShare))
+			case AssetFindingDepeg:
+				riskLevel = entity.RiskLevelHigh
+				symbols := make([]string, 0, len(info.Depegged))
+				for _, depeg := range info.Depegged {
+					symbols = append(symbols, fmt.Sprintf("%s at $%.3f", depeg.Symbol, depeg.Price))
+				}
+				parts = append(parts, fmt.Sprintf("%d stablecoins off peg (%s)", len(info.Depegged), strings.Join(symbols, ", ")))
+			}
+		}
+		details = strings.Join(parts, "; ")
+	default:
+		details = fmt.Sprintf("Stable assets: %.1f%%, volatile assets: %.1f%%, HHI %.2f", info.StableRatio, info.VolatileRatio, info.HHI)
 	}
 
 	c.log.Debugf("Asset composition check completed for address %s: %s", address, details)
@@ -165,9 +216,109 @@ func (c *AssetCompositionCheck) Execute(ctx context.Context, address string) (*e
 	return &entity.CheckResult{
 		CheckName:    c.Name(),
 		RiskFound:    riskFound,
-		RiskLevel:    entity.RiskLevelMedium,
+		RiskLevel:    riskLevel,
 		ScorePenalty: scorePenalty,
 		Details:      details,
-		RawData:      tokenInfos,
+		RawData:      info,
 	}, nil
 }
+
+// category - Категория токена в разбивке портфеля: категория реестра или other
+func (c *AssetCompositionCheck) category(address string) string {
+	if entry, ok := c.registry.Lookup(address); ok {
+		return string(entry.Category)
+	}
+	return assetCategoryOther
+}
+
+// assetThresholds - Пороги из конфига с подстановкой значений по умолчанию
+func assetThresholds(settings config.AssetsConfig) config.AssetsConfig {
+	if settings.VolatileRatioPercent <= 0 {
+		settings.VolatileRatioPercent = defaultVolatileRatioPercent
+	}
+	if settings.MaxHHI <= 0 {
+		settings.MaxHHI = defaultMaxHHI
+	}
+	if settings.MaxSingleAssetPercent <= 0 {
+		settings.MaxSingleAssetPercent = defaultMaxSingleAssetPercent
+	}
+	if settings.DepegPercent <= 0 {
+		settings.DepegPercent = defaultDepegPercent
+	}
+	return settings
+}
+
+// analyzeComposition - Считает доли активов и категорий, индекс концентрации и находит стейблкоины без привязки.
+// Доли и пороги в процентах, кроме HHI (0..1).
+func analyzeComposition(tokens []entity.TokenInfo, thresholds config.AssetsConfig) entity.AssetCompositionInfo {
+	info := entity.AssetCompositionInfo{Tokens: tokens}
+	for _, token := range tokens {
+		info.TotalUSD += token.USDValue
+	}
+	if info.TotalUSD <= 0 {
+		return info
+	}
+
+	var totalStable float64
+	categories := make(map[string]*entity.AssetCategoryShare)
+	var order []string
+	for i := range info.Tokens {
+		token := &info.Tokens[i]
+		share := token.USDValue / info.TotalUSD
+		token.Share = share * 100
+		info.HHI += share * share
+		if token.Share > info.TopAssetShare {
+			info.TopAsset = token.Symbol
+			info.TopAssetShare = token.Share
+		}
+		if token.IsStable {
+			totalStable += token.USDValue
+		}
+
+		category, ok := categories[token.Category]
+		if !ok {
+			category = &entity.AssetCategoryShare{Category: token.Category}
+			categories[token.Category] = category
+			order = append(order, token.Category)
+		}
+		category.USDValue += token.USDValue
+		category.Tokens++
+
+		// Привязка проверяется только по цене провайдера: номинал без цены не говорит ничего
+		if token.IsStable && token.Price > 0 {
+			deviation := math.Abs(token.Price-1) * 100
+			if deviation > thresholds.DepegPercent {
+				info.Depegged = append(info.Depegged, entity.DepegInfo{
+					Address:   token.Address,
+					Symbol:    token.Symbol,
+					Price:     token.Price,
+					Deviation: deviation,
+					USDValue:  token.USDValue,
+				})
+			}
+		}
+	}
+
+	info.StableRatio = totalStable / info.TotalUSD * 100
+	info.VolatileRatio = 100 - info.StableRatio
+	for _, name := range order {
+		category := categories[name]
+		category.Share = category.USDValue / info.TotalUSD * 100
+		info.Categories = append(info.Categories, *category)
+	}
+	sort.SliceStable(info.Categories, func(i, j int) bool { return info.Categories[i].USDValue > info.Categories[j].USDValue })
+
+	if info.VolatileRatio > thresholds.VolatileRatioPercent {
+		info.Findings = append(info.Findings, AssetFindingVolatileRatio)
+	}
+	if info.HHI > thresholds.MaxHHI {
+		info.Findings = append(info.Findings, AssetFindingConcentration)
+	}
+	if info.TopAssetShare > thresholds.MaxSingleAssetPercent {
+		info.Findings = append(info.Findings, AssetFindingDominance)
+	}
+	if len(info.Depegged) > 0 {
+		info.Findings = append(info.Findings, AssetFindingDepeg)
+	}
+	return info
+}
@@ -0,0 +1,92 @@
+package checks
+
+import (
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnalyzeComposition(t *testing.T) {
+	thresholds := assetThresholds(config.AssetsConfig{})
+
+	tests := []struct {
+		name     string
+		tokens   []entity.TokenInfo
+		hhi      float64
+		top      string
+		findings []string
+	}{
+		{
+			name: "diversified",
+			tokens: []entity.TokenInfo{
+				{Symbol: "ETH", USDValue: 300, Category: "native"},
+				{Symbol: "USDC", USDValue: 300, IsStable: true, Price: 1.0, Category: "stablecoin"},
+				{Symbol: "UNI", USDValue: 200, Category: "other"},
+				{Symbol: "DAI", USDValue: 200, IsStable: true, Price: 0.999, Category: "stablecoin"},
+			},
+			hhi: 0.26,
+			top: "ETH",
+		},
+		{
+			name: "single volatile asset",
+			tokens: []entity.TokenInfo{
+				{Symbol: "ETH", USDValue: 950, Category: "native"},
+				{Symbol: "USDC", USDValue: 50, IsStable: true, Price: 1.0, Category: "stablecoin"},
+			},
+			hhi:      0.905,
+			top:      "ETH",
+			findings: []string{AssetFindingVolatileRatio, AssetFindingConcentration, AssetFindingDominance},
+		},
+		{
+			name: "stablecoin off peg",
+			tokens: []entity.TokenInfo{
+				{Symbol: "ETH", USDValue: 500, Category: "native"},
+				{Symbol: "USDT", USDValue: 475, IsStable: true, Price: 0.95, Category: "stablecoin"},
+				// Без цены провайдера привязка не проверяется
+				{Symbol: "GUSD", USDValue: 25, IsStable: true, Category: "stablecoin"},
+			},
+			hhi:      0.47625,
+			top:      "ETH",
+			findings: []string{AssetFindingDepeg},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info := analyzeComposition(tt.tokens, thresholds)
+			assert.InDelta(t, tt.hhi, info.HHI, 1e-9)
+			assert.Equal(t, tt.top, info.TopAsset)
+			assert.Equal(t, tt.findings, info.Findings)
+		})
+	}
+}
+
+func TestAnalyzeComposition_Breakdown(t *testing.T) {
+	info := analyzeComposition([]entity.TokenInfo{
+		{Symbol: "USDC", USDValue: 250, IsStable: true, Price: 1, Category: "stablecoin"},
+		{Symbol: "ETH", USDValue: 500, Category: "native"},
+		{Symbol: "USDT", USDValue: 237.5, IsStable: true, Price: 0.95, Category: "stablecoin"},
+		{Symbol: "PEPE", USDValue: 12.5, Category: "other"},
+	}, assetThresholds(config.AssetsConfig{DepegPercent: 10}))
+
+	assert.InDelta(t, 1000, info.TotalUSD, 1e-9)
+	assert.InDelta(t, 48.75, info.StableRatio, 1e-9)
+	assert.InDelta(t, 25, info.Tokens[0].Share, 1e-9)
+	assert.Empty(t, info.Depegged, "5% deviation is within the configured 10%")
+
+	require.Len(t, info.Categories, 3)
+	assert.Equal(t, entity.AssetCategoryShare{Category: "native", USDValue: 500, Share: 50, Tokens: 1}, info.Categories[0])
+	assert.Equal(t, "stablecoin", info.Categories[1].Category)
+	assert.Equal(t, 2, info.Categories[1].Tokens)
+	assert.InDelta(t, 48.75, info.Categories[1].Share, 1e-9)
+}
+
+func TestAnalyzeComposition_Empty(t *testing.T) {
+	info := analyzeComposition(nil, assetThresholds(config.AssetsConfig{}))
+	assert.Zero(t, info.TotalUSD)
+	assert.Empty(t, info.Findings)
+}
@@ -4,27 +4,38 @@ import (
 	"alpha-hygiene-backend/config"
 	"context"
 	"fmt"
+	"strings"
+	"sync"
 
 	"alpha-hygiene-backend/internal/entity"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// nftVerdictConcurrency - Количество параллельных запросов вердиктов по коллекциям
+	nftVerdictConcurrency = 4
 )
 
 // DeadNFTCheck - Проверка на мертвые NFT
 type DeadNFTCheck struct {
 	goplusProvider *provider.GoPlusClient
 	alchemy        *provider.AlchemyClient
+	registry       *registry.Registry
 	cfg            *config.Config
 	log            *logrus.Entry
 }
 
 // NewDeadNFTCheck - Создает новую проверку на мертвые NFT
-func NewDeadNFTCheck(goplusProvider *provider.GoPlusClient, alchemy *provider.AlchemyClient, cfg *config.Config, log *logrus.Entry) *DeadNFTCheck {
+func NewDeadNFTCheck(goplusProvider *provider.GoPlusClient, alchemy *provider.AlchemyClient, reg *registry.Registry, cfg *config.Config, log *logrus.Entry) *DeadNFTCheck {
 	logger := log.WithFields(logrus.Fields{"component": "dead_nft"})
 	return &DeadNFTCheck{
 		goplusProvider: goplusProvider,
 		alchemy:        alchemy,
+		registry:       reg,
 		cfg:            cfg,
 		log:            logger,
 	}
@@ -50,19 +61,14 @@ func (c *DeadNFTCheck) Execute(ctx context.Context, address string) (*entity.Che
 
 	c.log.Debugf("Found %d NFTs for address %s", len(nfts), address)
 
-	// Для MVP определим "мертвые NFT" как NFT с контрактом, которые не являются популярными коллекциями
-	// В реальной реализации нужно проверять через GoPlus NFT Security API или другие источники
-	var deadNFTs []string
+	// Собираем уникальные NFT и их коллекции
+	var unique []*provider.AlchemyNFT
+	var collections []string
 	seen := make(map[string]bool)
+	seenCollection := make(map[string]bool)
 
 	for _, nft := range nfts {
 		c.log.Debugf("Processing NFT: contract=%s, tokenId=%s, type=%s", nft.ContractAddress, nft.TokenID, nft.TokenType)
-		// Здесь должна быть логика проверки на "мертвые" NFT
-		// Например, проверка на:
-		// - Наличие торгов на рынках (OpenSea, Blur)
-		// - Количество держателей
-		// - Активность контракта
-		// - Является ли коллекция скемой или фродом
 
 		// Проверяем, что NFT имеет все необходимые поля
 		if nft.ContractAddress == "" || nft.TokenID == "" {
@@ -76,16 +82,38 @@ func (c *DeadNFTCheck) Execute(ctx context.Context, address string) (*entity.Che
 			c.log.Warnf("Skipping duplicate NFT: %s", key)
 			continue
 		}
-
 		seen[key] = true
-		deadNFTs = append(deadNFTs, key)
+		unique = append(unique, nft)
+
+		collection := strings.ToLower(nft.ContractAddress)
+		// Для заблокированных в реестре коллекций вердикт GoPlus не нужен
+		if !seenCollection[collection] && !c.registry.IsBlocked(collection) {
+			seenCollection[collection] = true
+			collections = append(collections, collection)
+		}
+	}
+
+	// Вердикты GoPlus по коллекциям (кэшируются провайдером)
+	verdicts := c.getVerdicts(ctx, collections)
+
+	// "Мертвая" NFT - из коллекции, заблокированной в реестре, которую GoPlus считает вредоносной
+	// или по которой не было ни одной сделки. Коллекции без вердикта не учитываются.
+	var deadNFTs []string
+	for _, nft := range unique {
+		if !c.registry.IsBlocked(nft.ContractAddress) {
+			verdict, ok := verdicts[strings.ToLower(nft.ContractAddress)]
+			if !ok || !isDeadCollection(verdict) {
+				continue
+			}
+		}
+		deadNFTs = append(deadNFTs, fmt.Sprintf("%s:%s", nft.ContractAddress, nft.TokenID))
 	}
 
 	riskFound := len(deadNFTs) > 0
 	var scorePenalty float64
 
 	if riskFound {
-		scorePenalty = c.cfg.Scoring.Weights["dead_nft"] * 100
+		scorePenalty = c.cfg.Weight("dead_nft") * 100
 	}
 
 	return &entity.CheckResult{
@@ -93,6 +121,41 @@ func (c *DeadNFTCheck) Execute(ctx context.Context, address string) (*entity.Che
 		RiskFound:    riskFound,
 		RiskLevel:    entity.RiskLevelLow,
 		ScorePenalty: scorePenalty,
+		Details:      fmt.Sprintf("%d of %d NFTs belong to dead or malicious collections", len(deadNFTs), len(unique)),
 		RawData:      deadNFTs,
 	}, nil
 }
+
+// getVerdicts - Получает вердикты GoPlus по коллекциям; ошибки по отдельным коллекциям пропускаются
+func (c *DeadNFTCheck) getVerdicts(ctx context.Context, collections []string) map[string]*provider.NFTSecurity {
+	var mu sync.Mutex
+	verdicts := make(map[string]*provider.NFTSecurity, len(collections))
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(nftVerdictConcurrency)
+	for _, col := range collections {
+		collection := col
+		g.Go(func() error {
+			verdict, err := c.goplusProvider.GetNFTSecurity(gctx, collection)
+			if err != nil {
+				c.log.Warnf("Failed to get NFT security for collection %s: %v", collection, err)
+				return nil
+			}
+			mu.Lock()
+			verdicts[collection] = verdict
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return verdicts
+}
+
+// isDeadCollection - Коллекция вредоносная или по ней не было торгов
+func isDeadCollection(verdict *provider.NFTSecurity) bool {
+	if verdict.MaliciousNFTContract == 1 {
+		return true
+	}
+	return verdict.TrustList == 0 && verdict.TotalVolume == 0 && verdict.TradedVolume24h == 0
+}
@@ -0,0 +1,147 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Классификация делегата EIP-7702
+const (
+	DelegateKnownSafe = "known_safe"
+	DelegateUnknown   = "unknown"
+	DelegateMalicious = "malicious"
+)
+
+// DelegationCheck - Проверка делегирования кода кошелька по EIP-7702 (Pectra).
+// Код EOA вида 0xef0100 || address означает, что любые вызовы кошелька исполняет код делегата.
+// Делегат из allow-списка реестра считается безопасным, из block-списка или с флагами GoPlus - вредоносным.
+type DelegationCheck struct {
+	code     provider.CodeProvider
+	goplus   *provider.GoPlusClient
+	registry *registry.Registry
+	labeler  *labels.Labeler
+	cfg      *config.Config
+	log      *logrus.Entry
+}
+
+// NewDelegationCheck - Создает новую проверку делегирования EIP-7702
+func NewDelegationCheck(code provider.CodeProvider, goplus *provider.GoPlusClient, reg *registry.Registry, labeler *labels.Labeler, cfg *config.Config, log *logrus.Entry) *DelegationCheck {
+	logger := log.WithFields(logrus.Fields{"component": "eip7702_delegation"})
+	return &DelegationCheck{
+		code:     code,
+		goplus:   goplus,
+		registry: reg,
+		labeler:  labeler,
+		cfg:      cfg,
+		log:      logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *DelegationCheck) Name() string {
+	return "eip7702_delegation"
+}
+
+// Execute - Выполняет проверку
+func (c *DelegationCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking EIP-7702 delegation for address: %s", address)
+
+	if c.code == nil {
+		return nil, fmt.Errorf("account code source is not configured")
+	}
+	code, err := c.code.GetCode(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account code: %w", err)
+	}
+
+	info := entity.DelegationInfo{Address: strings.ToLower(address)}
+	result := &entity.CheckResult{
+		CheckName: c.Name(),
+		RiskLevel: entity.RiskLevelLow,
+		RawData:   info,
+	}
+
+	delegate, delegated := provider.ParseDelegation(code)
+	if !delegated {
+		info.IsContract = len(code) > 0
+		result.RawData = info
+		if info.IsContract {
+			result.Details = "Address is a smart contract account, EIP-7702 delegation is not applicable"
+		} else {
+			result.Details = "No EIP-7702 delegation"
+		}
+		return result, nil
+	}
+
+	target := strings.ToLower(delegate.Hex())
+	info.Delegated = true
+	info.DelegateAddress = target
+	info.DelegateURL = util.GetAdressURL(target)
+	info.DelegateLabel = c.labeler.Label(ctx, target, nil)
+
+	var goplusErr error
+	info.Classification, info.Reasons, goplusErr = c.classify(ctx, target)
+
+	switch info.Classification {
+	case DelegateMalicious:
+		result.RiskFound = true
+		result.RiskLevel = entity.RiskLevelCritical
+		result.Details = fmt.Sprintf("Wallet code is delegated to a malicious contract %s (%s), move funds to a new wallet", target, strings.Join(info.Reasons, ", "))
+	case DelegateUnknown:
+		result.RiskFound = true
+		result.RiskLevel = entity.RiskLevelHigh
+		result.Details = fmt.Sprintf("Wallet code is delegated to an unknown contract %s", target)
+	default:
+		result.Details = fmt.Sprintf("Wallet code is delegated to a known smart account implementation %s", target)
+	}
+	if goplusErr != nil {
+		result.Details += "; GoPlus verdict is unavailable"
+	}
+	if result.RiskFound {
+		result.ScorePenalty = c.cfg.Weight("eip7702_delegation") * 100
+	}
+	if info.DelegateLabel != nil {
+		result.Counterparties = []entity.AddressLabel{*info.DelegateLabel}
+	}
+	result.RawData = info
+
+	return result, nil
+}
+
+// classify - Классифицирует делегата по реестру и вердикту GoPlus address_security.
+// Записи реестра приоритетнее GoPlus; известным считается только делегат из allow-списка
+// с категорией account_delegate, прочие allow-адреса (токены, роутеры) проверяются как неизвестные.
+// Ошибка GoPlus оставляет делегата неизвестным.
+func (c *DelegationCheck) classify(ctx context.Context, delegate string) (string, []string, error) {
+	if entry, ok := c.registry.Lookup(delegate); ok {
+		if entry.List == registry.ListBlock {
+			return DelegateMalicious, []string{"registry:" + string(entry.Category)}, nil
+		}
+		if entry.Category == registry.CategoryAccountDelegate {
+			return DelegateKnownSafe, nil, nil
+		}
+	}
+
+	if c.goplus == nil {
+		return DelegateUnknown, nil, nil
+	}
+	security, err := c.goplus.GetAddressSecurity(ctx, delegate)
+	if err != nil {
+		c.log.Warnf("Failed to get GoPlus address security for %s: %v", delegate, err)
+		return DelegateUnknown, nil, err
+	}
+	if flags := security.Flags(); len(flags) > 0 {
+		return DelegateMalicious, flags, nil
+	}
+	return DelegateUnknown, nil, nil
+}
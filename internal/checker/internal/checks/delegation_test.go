@@ -0,0 +1,92 @@
+package checks
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCode []byte
+
+func (f fakeCode) GetCode(context.Context, string) ([]byte, error) {
+	return f, nil
+}
+
+func delegationCode(delegate string) fakeCode {
+	return append([]byte{0xef, 0x01, 0x00}, common.HexToAddress(delegate).Bytes()...)
+}
+
+func TestDelegationCheck(t *testing.T) {
+	const (
+		metamask = "0x63c0c19a282a1b52b07dd5a65b58948a07dae32b"
+		sweeper  = "0x5555555555555555555555555555555555555555"
+		unknown  = "0x6666666666666666666666666666666666666666"
+	)
+
+	// GoPlus помечает sweeper как контракт для кражи средств
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stealing := "0"
+		if strings.HasSuffix(r.URL.Path, sweeper) {
+			stealing = "1"
+		}
+		_, _ = w.Write([]byte(`{"code":1,"message":"ok","result":{"stealing_attack":"` + stealing + `","number_of_malicious_contracts_created":"0"}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.GoPlus.URL = srv.URL
+	cfg.Scoring.Weights = map[string]float64{"eip7702_delegation": 0.3}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+	goplus := provider.NewGoPlusClient(cfg, log)
+
+	tests := []struct {
+		name           string
+		code           fakeCode
+		riskFound      bool
+		level          entity.RiskLevel
+		classification string
+		reasons        []string
+	}{
+		{"plain EOA", nil, false, entity.RiskLevelLow, "", nil},
+		{"smart contract", common.FromHex("0x6080604052"), false, entity.RiskLevelLow, "", nil},
+		{"known delegate", delegationCode(metamask), false, entity.RiskLevelLow, DelegateKnownSafe, nil},
+		{"allow-listed non-delegate", delegationCode("0x7a250d5630b4cf539739df2c5dacb4c659f2488d"), true, entity.RiskLevelHigh, DelegateUnknown, nil},
+		{"unknown delegate", delegationCode(unknown), true, entity.RiskLevelHigh, DelegateUnknown, nil},
+		{"GoPlus flagged delegate", delegationCode(sweeper), true, entity.RiskLevelCritical, DelegateMalicious, []string{"stealing_attack"}},
+		{"registry blocked delegate", delegationCode("0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220"), true, entity.RiskLevelCritical, DelegateMalicious, []string{"registry:drainer"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			check := NewDelegationCheck(tt.code, goplus, reg, nil, cfg, log)
+			result, err := check.Execute(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.riskFound, result.RiskFound)
+			assert.Equal(t, tt.level, result.RiskLevel)
+			if tt.riskFound {
+				assert.InDelta(t, 30, result.ScorePenalty, 1e-9)
+			}
+
+			info, ok := result.RawData.(entity.DelegationInfo)
+			require.True(t, ok)
+			assert.Equal(t, tt.classification, info.Classification)
+			assert.Equal(t, tt.reasons, info.Reasons)
+			assert.Equal(t, tt.classification != "", info.Delegated)
+		})
+	}
+}
@@ -0,0 +1,203 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultWarnHealthFactor, defaultCriticalHealthFactor - Health factor, ниже которого позиция близка к ликвидации по умолчанию
+	defaultWarnHealthFactor     = 1.5
+	defaultCriticalHealthFactor = 1.1
+)
+
+var (
+	// defaultAavePools - Aave v3 Pool в Ethereum
+	defaultAavePools = []string{"0x87870bca3f3fd6335c3f4ce8392d69350b4fa4e2"}
+	// defaultCometMarkets - Рынки Compound v3 в Ethereum: cUSDCv3 и cWETHv3
+	defaultCometMarkets = []string{
+		"0xc3d688b66703497daa19211eedff47f25384cdc3",
+		"0xa17581a9e3d02a009a7e1c0c3ea5b3fa4bb1dbd5",
+	}
+)
+
+// LendingHealthCheck - Проверка заемных позиций кошелька в Aave v3 и Compound v3.
+// Залог, долг и health factor читаются через Multicall; позиция с health factor ниже
+// checks.lending_health.warn_health_factor / critical_health_factor - риск MEDIUM / HIGH, ликвидируемая - CRITICAL.
+// Позиции Compound v3 выражены в базовом активе рынка и переводятся в USD по его цене.
+type LendingHealthCheck struct {
+	multicall *provider.MulticallClient
+	prices    *provider.PriceClient
+	cfg       *config.Config
+	log       *logrus.Entry
+}
+
+// NewLendingHealthCheck - Создает новую проверку заемных позиций
+func NewLendingHealthCheck(multicall *provider.MulticallClient, prices *provider.PriceClient, cfg *config.Config, log *logrus.Entry) *LendingHealthCheck {
+	logger := log.WithFields(logrus.Fields{"component": "lending_health"})
+	return &LendingHealthCheck{
+		multicall: multicall,
+		prices:    prices,
+		cfg:       cfg,
+		log:       logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *LendingHealthCheck) Name() string {
+	return "lending_health"
+}
+
+// Execute - Выполняет проверку
+func (c *LendingHealthCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking lending positions for address: %s", address)
+
+	if c.multicall == nil {
+		return nil, fmt.Errorf("contract state source is not configured")
+	}
+
+	cfg := c.cfg.Checks.LendingHealth
+	positions, err := c.multicall.GetLendingPositions(ctx, common.HexToAddress(address),
+		marketAddresses(cfg.AavePools, defaultAavePools), marketAddresses(cfg.CometMarkets, defaultCometMarkets))
+	if err != nil {
+		return nil, err
+	}
+
+	prices := c.basePrices(ctx, positions)
+	warn, critical := healthThresholds(cfg)
+	info := entity.LendingHealthInfo{Positions: make([]entity.LendingPositionInfo, 0, len(positions))}
+	riskLevel := entity.RiskLevelLow
+	var atRisk int
+	for _, position := range positions {
+		item := newLendingPositionInfo(position, prices, warn, critical)
+		info.Positions = append(info.Positions, item)
+		info.TotalCollateralUSD += item.CollateralUSD
+		info.TotalDebtUSD += item.DebtUSD
+		if item.HealthFactor != nil && (info.LowestHealthFactor == nil || *item.HealthFactor < *info.LowestHealthFactor) {
+			hf := *item.HealthFactor
+			info.LowestHealthFactor = &hf
+		}
+		if item.RiskLevel == "" {
+			continue
+		}
+		atRisk++
+		if riskRank(item.RiskLevel) > riskRank(riskLevel) {
+			riskLevel = item.RiskLevel
+		}
+	}
+
+	riskFound := atRisk > 0
+	var scorePenalty float64
+	var details string
+	switch {
+	case riskFound:
+		scorePenalty = c.cfg.Weight("lending_health") * 100
+		details = fmt.Sprintf("Found %d lending positions near liquidation", atRisk)
+	case len(positions) > 0:
+		details = fmt.Sprintf("%d lending positions are healthy", len(positions))
+	default:
+		details = "No borrowing positions in Aave v3 and Compound v3"
+	}
+	if info.LowestHealthFactor != nil {
+		details += fmt.Sprintf(" (lowest health factor %.2f)", *info.LowestHealthFactor)
+	}
+
+	return &entity.CheckResult{
+		CheckName:    c.Name(),
+		RiskFound:    riskFound,
+		RiskLevel:    riskLevel,
+		ScorePenalty: scorePenalty,
+		Details:      details,
+		RawData:      info,
+	}, nil
+}
+
+// basePrices - USD цены базовых активов рынков Compound v3. Без цен позиции остаются в базовом активе.
+func (c *LendingHealthCheck) basePrices(ctx context.Context, positions []provider.LendingPosition) map[string]float64 {
+	var tokens []string
+	for _, position := range positions {
+		if position.BaseToken != (common.Address{}) {
+			tokens = append(tokens, position.BaseToken.Hex())
+		}
+	}
+	if len(tokens) == 0 || c.prices == nil {
+		return map[string]float64{}
+	}
+	prices, err := c.prices.GetPrices(ctx, tokens)
+	if err != nil {
+		c.log.Warnf("Failed to get base token prices: %v", err)
+		return map[string]float64{}
+	}
+	return prices
+}
+
+// newLendingPositionInfo - Переводит позицию провайдера в отчет и оценивает ее риск:
+// ликвидируемая - CRITICAL, health factor ниже critical - HIGH, ниже warn - MEDIUM
+func newLendingPositionInfo(position provider.LendingPosition, prices map[string]float64, warn, critical float64) entity.LendingPositionInfo {
+	market := strings.ToLower(position.Market.Hex())
+	item := entity.LendingPositionInfo{
+		Protocol:             position.Protocol,
+		Market:               market,
+		MarketURL:            util.GetAdressURL(market),
+		LiquidationThreshold: position.LiquidationThreshold * 100,
+		Liquidatable:         position.Liquidatable,
+	}
+	if position.BaseToken == (common.Address{}) {
+		item.CollateralUSD, item.DebtUSD = position.Collateral, position.Debt
+	} else {
+		item.BaseToken = strings.ToLower(position.BaseToken.Hex())
+		item.Collateral, item.Debt = position.Collateral, position.Debt
+		if price, ok := prices[item.BaseToken]; ok {
+			item.CollateralUSD, item.DebtUSD = position.Collateral*price, position.Debt*price
+		}
+	}
+	if position.HealthFactor > 0 {
+		hf := position.HealthFactor
+		item.HealthFactor = &hf
+	}
+
+	switch {
+	case position.Liquidatable:
+		item.RiskLevel = entity.RiskLevelCritical
+	case item.HealthFactor == nil:
+		// Залог не удалось оценить, остается только признак ликвидируемости от протокола
+	case *item.HealthFactor < critical:
+		item.RiskLevel = entity.RiskLevelHigh
+	case *item.HealthFactor < warn:
+		item.RiskLevel = entity.RiskLevelMedium
+	}
+	return item
+}
+
+// healthThresholds - Пороги health factor из конфига с подстановкой значений по умолчанию
+func healthThresholds(cfg config.LendingHealthConfig) (warn, critical float64) {
+	warn, critical = cfg.WarnHealthFactor, cfg.CriticalHealthFactor
+	if warn <= 0 {
+		warn = defaultWarnHealthFactor
+	}
+	if critical <= 0 {
+		critical = defaultCriticalHealthFactor
+	}
+	return warn, critical
+}
+
+// marketAddresses - Адреса рынков из конфига, пустой список - рынки по умолчанию
+func marketAddresses(configured, defaults []string) []common.Address {
+	if len(configured) == 0 {
+		configured = defaults
+	}
+	addresses := make([]common.Address, len(configured))
+	for i, addr := range configured {
+		addresses[i] = common.HexToAddress(addr)
+	}
+	return addresses
+}
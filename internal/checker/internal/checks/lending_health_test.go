@@ -0,0 +1,187 @@
+package checks
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/testutil/evmtest"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testLendingABI = `[
+	{"inputs":[{"name":"user","type":"address"}],"name":"getUserAccountData","outputs":[
+		{"name":"","type":"uint256"},{"name":"","type":"uint256"},{"name":"","type":"uint256"},
+		{"name":"","type":"uint256"},{"name":"","type":"uint256"},{"name":"","type":"uint256"}],"type":"function"},
+	{"inputs":[{"name":"account","type":"address"}],"name":"borrowBalanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"inputs":[{"name":"account","type":"address"}],"name":"isLiquidatable","outputs":[{"name":"","type":"bool"}],"type":"function"},
+	{"inputs":[],"name":"numAssets","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+	{"inputs":[],"name":"baseToken","outputs":[{"name":"","type":"address"}],"type":"function"},
+	{"inputs":[],"name":"baseTokenPriceFeed","outputs":[{"name":"","type":"address"}],"type":"function"},
+	{"inputs":[],"name":"baseScale","outputs":[{"name":"","type":"uint64"}],"type":"function"},
+	{"inputs":[{"name":"priceFeed","type":"address"}],"name":"getPrice","outputs":[{"name":"","type":"uint256"}],"type":"function"}
+]`
+
+// usd - Значение с 8 знаками после запятой (base currency Aave, цены Comet)
+func usd(v int64) common.Hash {
+	return evmtest.Word(new(big.Int).Mul(big.NewInt(v), big.NewInt(1e8)))
+}
+
+// healthFactor - Health factor Aave с точностью 1e18, задается в сотых
+func healthFactor(hundredths int64) common.Hash {
+	return evmtest.Word(new(big.Int).Mul(big.NewInt(hundredths), big.NewInt(1e16)))
+}
+
+func TestLendingHealthCheck(t *testing.T) {
+	var (
+		wallet   = common.HexToAddress("0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+		pool     = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+		comet    = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+		cometETH = common.HexToAddress("0x00000000000000000000000000000000000000c2")
+		usdc     = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+		weth     = common.HexToAddress("0x00000000000000000000000000000000000000e2")
+		baseFeed = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+		ethFeed  = common.HexToAddress("0x00000000000000000000000000000000000000f2")
+	)
+	parsed, err := abi.JSON(strings.NewReader(testLendingABI))
+	require.NoError(t, err)
+	pack := func(method string, args ...interface{}) []byte {
+		data, err := parsed.Pack(method, args...)
+		require.NoError(t, err)
+		return data
+	}
+	zero := evmtest.Word(big.NewInt(0))
+
+	// DefiLlama: USD цены базовых активов рынков Compound v3
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"coins":{` +
+			`"ethereum:` + strings.ToLower(usdc.Hex()) + `":{"price":1},` +
+			`"ethereum:` + strings.ToLower(weth.Hex()) + `":{"price":3000}}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Prices.URL = srv.URL
+	cfg.Scoring.Weights = map[string]float64{"lending_health": 0.3}
+	cfg.Checks.LendingHealth.AavePools = []string{pool.Hex()}
+	cfg.Checks.LendingHealth.CometMarkets = []string{comet.Hex(), cometETH.Hex()}
+
+	newCheck := func(alloc types.GenesisAlloc) *LendingHealthCheck {
+		chain := evmtest.NewChain(t, alloc)
+		multicall, err := provider.NewMulticallClientWithBackend(context.Background(), chain.Client, cfg, log)
+		require.NoError(t, err)
+		return NewLendingHealthCheck(multicall, provider.NewPriceClient(cfg, log), cfg, log)
+	}
+
+	t.Run("positions near liquidation", func(t *testing.T) {
+		aave := map[common.Hash]common.Hash{}
+		evmtest.SetReturn(aave, pack("getUserAccountData", wallet),
+			usd(10000), usd(7900), zero, evmtest.Word(big.NewInt(8300)), evmtest.Word(big.NewInt(8000)), healthFactor(105))
+
+		// Залоги Comet не заданы, риск определяется по isLiquidatable
+		market := map[common.Hash]common.Hash{}
+		evmtest.SetReturn(market, pack("borrowBalanceOf", wallet), evmtest.Word(big.NewInt(2500e6)))
+		evmtest.SetReturn(market, pack("isLiquidatable", wallet), evmtest.Word(big.NewInt(1)))
+		evmtest.SetReturn(market, pack("numAssets"), zero)
+		evmtest.SetReturn(market, pack("baseToken"), evmtest.AddressTopic(usdc))
+		evmtest.SetReturn(market, pack("baseTokenPriceFeed"), evmtest.AddressTopic(baseFeed))
+		evmtest.SetReturn(market, pack("baseScale"), evmtest.Word(big.NewInt(1e6)))
+		evmtest.SetReturn(market, pack("getPrice", baseFeed), usd(1))
+
+		// Рынок с базовым WETH: оракул возвращает цену в ETH, долг 1 WETH переводится в USD по цене WETH
+		marketETH := map[common.Hash]common.Hash{}
+		evmtest.SetReturn(marketETH, pack("borrowBalanceOf", wallet), evmtest.Word(big.NewInt(1e18)))
+		evmtest.SetReturn(marketETH, pack("isLiquidatable", wallet), zero)
+		evmtest.SetReturn(marketETH, pack("numAssets"), zero)
+		evmtest.SetReturn(marketETH, pack("baseToken"), evmtest.AddressTopic(weth))
+		evmtest.SetReturn(marketETH, pack("baseTokenPriceFeed"), evmtest.AddressTopic(ethFeed))
+		evmtest.SetReturn(marketETH, pack("baseScale"), evmtest.Word(big.NewInt(1e18)))
+		evmtest.SetReturn(marketETH, pack("getPrice", ethFeed), usd(1))
+
+		check := newCheck(types.GenesisAlloc{
+			pool:     {Code: evmtest.MockTupleContract, Storage: aave},
+			comet:    {Code: evmtest.MockTupleContract, Storage: market},
+			cometETH: {Code: evmtest.MockTupleContract, Storage: marketETH},
+		})
+		result, err := check.Execute(context.Background(), wallet.Hex())
+		require.NoError(t, err)
+
+		assert.True(t, result.RiskFound)
+		assert.Equal(t, entity.RiskLevelCritical, result.RiskLevel)
+		assert.InDelta(t, 30, result.ScorePenalty, 1e-9)
+		assert.Equal(t, "Found 2 lending positions near liquidation (lowest health factor 1.05)", result.Details)
+
+		info, ok := result.RawData.(entity.LendingHealthInfo)
+		require.True(t, ok)
+		require.Len(t, info.Positions, 3)
+		assert.InDelta(t, 13400, info.TotalDebtUSD, 1e-6)
+
+		assert.Equal(t, provider.LendingProtocolAaveV3, info.Positions[0].Protocol)
+		assert.Equal(t, entity.RiskLevelHigh, info.Positions[0].RiskLevel)
+		assert.InDelta(t, 83, info.Positions[0].LiquidationThreshold, 1e-9)
+
+		assert.Equal(t, provider.LendingProtocolCompoundV3, info.Positions[1].Protocol)
+		assert.True(t, info.Positions[1].Liquidatable)
+		assert.Nil(t, info.Positions[1].HealthFactor)
+		assert.Equal(t, entity.RiskLevelCritical, info.Positions[1].RiskLevel)
+		assert.InDelta(t, 2500, info.Positions[1].DebtUSD, 1e-6)
+
+		assert.Equal(t, strings.ToLower(weth.Hex()), info.Positions[2].BaseToken)
+		assert.InDelta(t, 1, info.Positions[2].Debt, 1e-9)
+		assert.InDelta(t, 3000, info.Positions[2].DebtUSD, 1e-6)
+		assert.Empty(t, info.Positions[2].RiskLevel)
+	})
+
+	t.Run("healthy position", func(t *testing.T) {
+		aave := map[common.Hash]common.Hash{}
+		evmtest.SetReturn(aave, pack("getUserAccountData", wallet),
+			usd(10000), usd(3000), zero, evmtest.Word(big.NewInt(8300)), evmtest.Word(big.NewInt(8000)), healthFactor(276))
+
+		result, err := newCheck(types.GenesisAlloc{pool: {Code: evmtest.MockTupleContract, Storage: aave}}).Execute(context.Background(), wallet.Hex())
+		require.NoError(t, err)
+		assert.False(t, result.RiskFound)
+		assert.Equal(t, entity.RiskLevelLow, result.RiskLevel)
+		assert.Equal(t, "1 lending positions are healthy (lowest health factor 2.76)", result.Details)
+	})
+
+	t.Run("no positions", func(t *testing.T) {
+		result, err := newCheck(nil).Execute(context.Background(), wallet.Hex())
+		require.NoError(t, err)
+		assert.False(t, result.RiskFound)
+		assert.Equal(t, "No borrowing positions in Aave v3 and Compound v3", result.Details)
+	})
+}
+
+func TestNewLendingPositionInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		position provider.LendingPosition
+		level    entity.RiskLevel
+	}{
+		{"healthy", provider.LendingPosition{HealthFactor: 1.8}, ""},
+		{"below warn", provider.LendingPosition{HealthFactor: 1.4}, entity.RiskLevelMedium},
+		{"below critical", provider.LendingPosition{HealthFactor: 1.09}, entity.RiskLevelHigh},
+		{"liquidatable", provider.LendingPosition{HealthFactor: 0.97, Liquidatable: true}, entity.RiskLevelCritical},
+		{"unknown health factor", provider.LendingPosition{}, ""},
+	}
+
+	warn, critical := healthThresholds(config.LendingHealthConfig{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.level, newLendingPositionInfo(tt.position, nil, warn, critical).RiskLevel)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/sirupsen/logrus"
+)
+
+// defaultRecentChangeDays - Сколько дней изменение владельцев Safe считается недавним по умолчанию
+const defaultRecentChangeDays = 30
+
+// Коды проблем конфигурации Safe
+const (
+	SafeFindingSingleSigner  = "single_signer_threshold"
+	SafeFindingUnknownModule = "unknown_module"
+	SafeFindingRecentChange  = "recent_owner_change"
+	SafeFindingRiskyOwner    = "risky_owner"
+)
+
+// SafeConfigCheck - Аудит конфигурации Safe (Gnosis Safe) multisig.
+// Владельцы, порог, модули и guard читаются одним batch через Multicall, история владельцев - из событий Etherscan.
+// Проблемы: порог 1 (одна подпись), модули вне allow-списка реестра, недавние изменения владельцев
+// и владельцы из block-списка реестра или с флагами GoPlus.
+type SafeConfigCheck struct {
+	multicall *provider.MulticallClient
+	etherscan *provider.EtherscanClient
+	goplus    *provider.GoPlusClient
+	registry  *registry.Registry
+	cfg       *config.Config
+	log       *logrus.Entry
+}
+
+// NewSafeConfigCheck - Создает новую проверку конфигурации Safe
+func NewSafeConfigCheck(multicall *provider.MulticallClient, etherscan *provider.EtherscanClient, goplus *provider.GoPlusClient, reg *registry.Registry, cfg *config.Config, log *logrus.Entry) *SafeConfigCheck {
+	logger := log.WithFields(logrus.Fields{"component": "safe_config"})
+	return &SafeConfigCheck{
+		multicall: multicall,
+		etherscan: etherscan,
+		goplus:    goplus,
+		registry:  reg,
+		cfg:       cfg,
+		log:       logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *SafeConfigCheck) Name() string {
+	return "safe_config"
+}
+
+// Execute - Выполняет проверку
+func (c *SafeConfigCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking Safe configuration for address: %s", address)
+
+	if c.multicall == nil {
+		return nil, fmt.Errorf("contract state source is not configured")
+	}
+	state, err := c.multicall.GetSafeState(ctx, common.HexToAddress(address))
+	if err != nil {
+		return nil, err
+	}
+
+	info := entity.SafeAuditInfo{Address: strings.ToLower(address)}
+	if state == nil {
+		return &entity.CheckResult{
+			CheckName: c.Name(),
+			RiskLevel: entity.RiskLevelLow,
+			Details:   "Address is not a Safe",
+			RawData:   info,
+		}, nil
+	}
+
+	info.IsSafe = true
+	info.Version = state.Version
+	info.Threshold = state.Threshold
+	info.Nonce = state.Nonce
+	if state.Guard != (common.Address{}) {
+		info.Guard = strings.ToLower(state.Guard.Hex())
+	}
+
+	var notes []string
+
+	// Владельцы из block-списка реестра или с флагами GoPlus
+	var goplusErrors int
+	for _, owner := range state.Owners {
+		addr := strings.ToLower(owner.Hex())
+		reasons, err := c.ownerReasons(ctx, addr)
+		if err != nil {
+			goplusErrors++
+		}
+		info.Owners = append(info.Owners, entity.SafeOwnerInfo{
+			Address:    addr,
+			AddressURL: util.GetAdressURL(addr),
+			Risky:      len(reasons) > 0,
+			Reasons:    reasons,
+		})
+		if len(reasons) > 0 {
+			info.Findings = append(info.Findings, entity.SafeFinding{
+				Code:      SafeFindingRiskyOwner,
+				RiskLevel: entity.RiskLevelCritical,
+				Address:   addr,
+				Message:   fmt.Sprintf("Owner %s is flagged (%s)", addr, strings.Join(reasons, ", ")),
+			})
+		}
+	}
+	if goplusErrors > 0 {
+		notes = append(notes, fmt.Sprintf("GoPlus verdict for %d owners is unavailable", goplusErrors))
+	}
+
+	// Порог в одну подпись
+	if state.Threshold == 1 {
+		info.Findings = append(info.Findings, entity.SafeFinding{
+			Code:      SafeFindingSingleSigner,
+			RiskLevel: entity.RiskLevelHigh,
+			Message:   fmt.Sprintf("Threshold is 1 of %d owners, a single key controls the Safe", len(state.Owners)),
+		})
+	}
+
+	// Модули исполняют транзакции без подписей владельцев
+	for _, module := range state.Modules {
+		addr := strings.ToLower(module.Hex())
+		moduleInfo := entity.SafeModuleInfo{Address: addr, AddressURL: util.GetAdressURL(addr)}
+		entry, ok := c.registry.Lookup(addr)
+		if ok {
+			moduleInfo.Label = entry.Label
+			moduleInfo.Known = entry.List == registry.ListAllow
+		}
+		info.Modules = append(info.Modules, moduleInfo)
+		if moduleInfo.Known {
+			continue
+		}
+
+		finding := entity.SafeFinding{
+			Code:      SafeFindingUnknownModule,
+			RiskLevel: entity.RiskLevelHigh,
+			Address:   addr,
+			Message:   fmt.Sprintf("Module %s is not a known Safe module and can execute transactions without owner signatures", addr),
+		}
+		if ok && entry.List == registry.ListBlock {
+			finding.RiskLevel = entity.RiskLevelCritical
+			finding.Message = fmt.Sprintf("Module %s is blocklisted (%s)", addr, entry.Category)
+		}
+		info.Findings = append(info.Findings, finding)
+	}
+	if state.ModulesTruncated {
+		notes = append(notes, fmt.Sprintf("only the first %d modules are checked", len(state.Modules)))
+	}
+
+	// Недавние изменения владельцев и порога
+	if c.etherscan != nil {
+		changes, err := c.etherscan.GetSafeOwnerChanges(ctx, info.Address)
+		if err != nil {
+			c.log.Warnf("Failed to get Safe owner history for %s: %v", address, err)
+			notes = append(notes, "owner change history is unavailable")
+		}
+		info.OwnerChanges, info.Findings = c.ownerChanges(changes, info.Findings, time.Now())
+	}
+
+	riskLevel := entity.RiskLevelLow
+	for _, finding := range info.Findings {
+		if riskRank(finding.RiskLevel) > riskRank(riskLevel) {
+			riskLevel = finding.RiskLevel
+		}
+	}
+
+	riskFound := len(info.Findings) > 0
+	var scorePenalty float64
+	var details string
+	if riskFound {
+		scorePenalty = c.cfg.Weight("safe_config") * 100
+		details = fmt.Sprintf("Found %d issues in Safe configuration (threshold %d of %d owners, %d modules)",
+			len(info.Findings), state.Threshold, len(state.Owners), len(state.Modules))
+	} else {
+		details = fmt.Sprintf("Safe configuration looks healthy (threshold %d of %d owners, %d modules)",
+			state.Threshold, len(state.Owners), len(state.Modules))
+	}
+	if len(notes) > 0 {
+		details += "; " + strings.Join(notes, "; ")
+	}
+
+	return &entity.CheckResult{
+		CheckName:    c.Name(),
+		RiskFound:    riskFound,
+		RiskLevel:    riskLevel,
+		ScorePenalty: scorePenalty,
+		Details:      details,
+		RawData:      info,
+	}, nil
+}
+
+// ownerReasons - Причины считать владельца рискованным: категория block-списка реестра или флаги GoPlus address_security
+func (c *SafeConfigCheck) ownerReasons(ctx context.Context, owner string) ([]string, error) {
+	if entry, ok := c.registry.Lookup(owner); ok {
+		if entry.List == registry.ListBlock {
+			return []string{"registry:" + string(entry.Category)}, nil
+		}
+		return nil, nil
+	}
+
+	if c.goplus == nil {
+		return nil, nil
+	}
+	security, err := c.goplus.GetAddressSecurity(ctx, owner)
+	if err != nil {
+		c.log.Warnf("Failed to get GoPlus address security for %s: %v", owner, err)
+		return nil, err
+	}
+	return security.Flags(), nil
+}
+
+// ownerChanges - Переводит события Safe в отчет и добавляет находку для каждого изменения за последние recent_change_days
+func (c *SafeConfigCheck) ownerChanges(changes []provider.SafeOwnerChange, findings []entity.SafeFinding, now time.Time) ([]entity.SafeOwnerChange, []entity.SafeFinding) {
+	days := c.cfg.Checks.SafeConfig.RecentChangeDays
+	if days <= 0 {
+		days = defaultRecentChangeDays
+	}
+	since := now.AddDate(0, 0, -days)
+
+	result := make([]entity.SafeOwnerChange, 0, len(changes))
+	for _, change := range changes {
+		item := entity.SafeOwnerChange{
+			Event:     change.Event,
+			Owner:     change.Owner,
+			Threshold: change.Threshold,
+			TxHash:    change.TxHash,
+		}
+		if change.Timestamp > 0 {
+			at := time.Unix(change.Timestamp, 0).UTC()
+			item.At = &at
+		}
+		result = append(result, item)
+
+		if item.At == nil || item.At.Before(since) {
+			continue
+		}
+		var message string
+		switch change.Event {
+		case provider.SafeEventAddedOwner:
+			message = fmt.Sprintf("Owner %s was added %s", change.Owner, item.At.Format(time.DateOnly))
+		case provider.SafeEventRemovedOwner:
+			message = fmt.Sprintf("Owner %s was removed %s", change.Owner, item.At.Format(time.DateOnly))
+		default:
+			message = fmt.Sprintf("Threshold was changed to %d %s", change.Threshold, item.At.Format(time.DateOnly))
+		}
+		findings = append(findings, entity.SafeFinding{
+			Code:      SafeFindingRecentChange,
+			RiskLevel: entity.RiskLevelMedium,
+			Address:   change.Owner,
+			Message:   message,
+		})
+	}
+	return result, findings
+}
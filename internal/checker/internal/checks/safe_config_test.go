@@ -0,0 +1,182 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSafeABI = `[
+	{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"type":"function"},
+	{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"inputs":[{"name":"start","type":"address"},{"name":"pageSize","type":"uint256"}],"name":"getModulesPaginated",
+	 "outputs":[{"name":"array","type":"address[]"},{"name":"next","type":"address"}],"type":"function"}
+]`
+
+// fakeSafeBackend - Backend сети без Multicall3, отвечающий на view методы Safe; без ответов адрес не является Safe
+type fakeSafeBackend struct {
+	abi       abi.ABI
+	responses map[string][]byte
+}
+
+func (f *fakeSafeBackend) ChainID(context.Context) (*big.Int, error) {
+	return big.NewInt(31337), nil
+}
+
+func (f *fakeSafeBackend) CallContract(_ context.Context, msg ethereum.CallMsg, _ *big.Int) ([]byte, error) {
+	if method, err := f.abi.MethodById(msg.Data[:4]); err == nil {
+		if data, ok := f.responses[method.Name]; ok {
+			return data, nil
+		}
+	}
+	return nil, errors.New("execution reverted")
+}
+
+func newFakeSafeBackend(t *testing.T, owners []common.Address, threshold int64, modules []common.Address) *fakeSafeBackend {
+	parsed, err := abi.JSON(strings.NewReader(testSafeABI))
+	require.NoError(t, err)
+	pack := func(method string, values ...interface{}) []byte {
+		data, err := parsed.Methods[method].Outputs.Pack(values...)
+		require.NoError(t, err)
+		return data
+	}
+	return &fakeSafeBackend{abi: parsed, responses: map[string][]byte{
+		"getOwners":           pack("getOwners", owners),
+		"getThreshold":        pack("getThreshold", big.NewInt(threshold)),
+		"getModulesPaginated": pack("getModulesPaginated", modules, common.HexToAddress("0x1")),
+	}}
+}
+
+func TestSafeConfigCheck(t *testing.T) {
+	const (
+		safe    = "0x4444444444444444444444444444444444444444"
+		owner   = "0x1111111111111111111111111111111111111111"
+		drainer = "0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220"
+		newbie  = "0x2222222222222222222222222222222222222222"
+	)
+	allowanceModule := common.HexToAddress("0xcfbfac74c26f8647cbdb8c5caf80bb5b32e43134")
+	unknownModule := common.HexToAddress("0x9999999999999999999999999999999999999999")
+
+	recent := time.Now().AddDate(0, 0, -5).Unix()
+	old := time.Now().AddDate(-1, 0, 0).Unix()
+	logs := map[common.Hash][]map[string]interface{}{
+		crypto.Keccak256Hash([]byte("AddedOwner(address)")): {{
+			"topics":          []string{crypto.Keccak256Hash([]byte("AddedOwner(address)")).Hex(), common.BytesToHash(common.HexToAddress(newbie).Bytes()).Hex()},
+			"data":            "0x",
+			"blockNumber":     "0x20",
+			"timeStamp":       fmt.Sprintf("0x%x", recent),
+			"transactionHash": "0xadd",
+		}},
+		crypto.Keccak256Hash([]byte("ChangedThreshold(uint256)")): {{
+			"topics":          []string{crypto.Keccak256Hash([]byte("ChangedThreshold(uint256)")).Hex()},
+			"data":            common.BigToHash(big.NewInt(1)).Hex(),
+			"blockNumber":     "0x10",
+			"timeStamp":       fmt.Sprintf("0x%x", old),
+			"transactionHash": "0xthreshold",
+		}},
+	}
+	etherscan := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, ok := logs[common.HexToHash(r.URL.Query().Get("topic0"))]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "No records found", "result": []interface{}{}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": result})
+	}))
+	t.Cleanup(etherscan.Close)
+
+	goplus := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"code":1,"message":"ok","result":{"stealing_attack":"0"}}`))
+	}))
+	t.Cleanup(goplus.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Etherscan.URL = etherscan.URL
+	cfg.GoPlus.URL = goplus.URL
+	cfg.Scoring.Weights = map[string]float64{"safe_config": 0.3}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	newCheck := func(backend *fakeSafeBackend) *SafeConfigCheck {
+		multicall, err := provider.NewMulticallClientWithBackend(context.Background(), backend, cfg, log)
+		require.NoError(t, err)
+		return NewSafeConfigCheck(multicall, provider.NewEtherscanClient(cfg, log), provider.NewGoPlusClient(cfg, log), reg, cfg, log)
+	}
+
+	t.Run("risky configuration", func(t *testing.T) {
+		backend := newFakeSafeBackend(t,
+			[]common.Address{common.HexToAddress(owner), common.HexToAddress(drainer), common.HexToAddress(newbie)},
+			1, []common.Address{allowanceModule, unknownModule})
+
+		result, err := newCheck(backend).Execute(context.Background(), safe)
+		require.NoError(t, err)
+
+		assert.True(t, result.RiskFound)
+		assert.Equal(t, entity.RiskLevelCritical, result.RiskLevel)
+		assert.InDelta(t, 30, result.ScorePenalty, 1e-9)
+		assert.Equal(t, "Found 4 issues in Safe configuration (threshold 1 of 3 owners, 2 modules)", result.Details)
+
+		info, ok := result.RawData.(entity.SafeAuditInfo)
+		require.True(t, ok)
+		assert.True(t, info.IsSafe)
+		require.Len(t, info.Owners, 3)
+		assert.False(t, info.Owners[0].Risky)
+		assert.Equal(t, []string{"registry:drainer"}, info.Owners[1].Reasons)
+		require.Len(t, info.Modules, 2)
+		assert.True(t, info.Modules[0].Known)
+		assert.Equal(t, "Safe Allowance Module", info.Modules[0].Label)
+		assert.False(t, info.Modules[1].Known)
+		require.Len(t, info.OwnerChanges, 2)
+
+		codes := make([]string, 0, len(info.Findings))
+		for _, finding := range info.Findings {
+			codes = append(codes, finding.Code)
+		}
+		assert.Equal(t, []string{SafeFindingRiskyOwner, SafeFindingSingleSigner, SafeFindingUnknownModule, SafeFindingRecentChange}, codes)
+		assert.Equal(t, newbie, info.Findings[3].Address)
+	})
+
+	t.Run("healthy configuration", func(t *testing.T) {
+		backend := newFakeSafeBackend(t,
+			[]common.Address{common.HexToAddress(owner), common.HexToAddress(newbie)},
+			2, []common.Address{allowanceModule})
+		cfg.Checks.SafeConfig.RecentChangeDays = 1
+		t.Cleanup(func() { cfg.Checks.SafeConfig.RecentChangeDays = 0 })
+
+		result, err := newCheck(backend).Execute(context.Background(), safe)
+		require.NoError(t, err)
+		assert.False(t, result.RiskFound)
+		assert.Equal(t, "Safe configuration looks healthy (threshold 2 of 2 owners, 1 modules)", result.Details)
+	})
+
+	t.Run("not a Safe", func(t *testing.T) {
+		result, err := newCheck(&fakeSafeBackend{}).Execute(context.Background(), safe)
+		require.NoError(t, err)
+		assert.False(t, result.RiskFound)
+		assert.Equal(t, "Address is not a Safe", result.Details)
+		info, ok := result.RawData.(entity.SafeAuditInfo)
+		require.True(t, ok)
+		assert.False(t, info.IsSafe)
+	})
+}
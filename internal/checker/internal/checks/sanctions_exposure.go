@@ -0,0 +1,365 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultMaxHopCounterparties - Сколько контрагентов проверяется на связь через посредника по умолчанию
+	defaultMaxHopCounterparties = 10
+	// defaultHopRecentTransfers - Сколько последних транзакций посредника просматривается по умолчанию
+	defaultHopRecentTransfers = 100
+	// ethDecimals - Знаков после запятой у ETH (wei)
+	ethDecimals = 18
+)
+
+// transfer - Перевод актива из истории Etherscan в общем виде (ETH, внутренние транзакции, ERC-20)
+type transfer struct {
+	from      string
+	to        string
+	hash      string
+	timestamp string
+	symbol    string
+	token     string
+	amount    float64
+}
+
+// SanctionsExposureCheck - Проверка связей кошелька с санкционными адресами и миксерами.
+// Списки берутся из реестра (категории sanctioned и mixer, обычно файл config/sanctions.yaml).
+// Прямая связь - переводы кошелька с адресом из списка; связь через посредника - последние переводы
+// самых частых контрагентов-EOA кошелька с такими адресами. Контракты (роутеры, пулы) посредниками
+// не считаются: через них проходят переводы всех пользователей, в том числе миксеров.
+type SanctionsExposureCheck struct {
+	etherscan *provider.EtherscanClient
+	registry  *registry.Registry
+	cfg       *config.Config
+	log       *logrus.Entry
+}
+
+// NewSanctionsExposureCheck - Создает новую проверку связей с санкционными адресами
+func NewSanctionsExposureCheck(etherscan *provider.EtherscanClient, reg *registry.Registry, cfg *config.Config, log *logrus.Entry) *SanctionsExposureCheck {
+	logger := log.WithFields(logrus.Fields{"component": "sanctions_exposure"})
+	return &SanctionsExposureCheck{
+		etherscan: etherscan,
+		registry:  reg,
+		cfg:       cfg,
+		log:       logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *SanctionsExposureCheck) Name() string {
+	return "sanctions_exposure"
+}
+
+// Execute - Выполняет проверку
+func (c *SanctionsExposureCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking sanctions exposure for address: %s", address)
+
+	wallet := strings.ToLower(address)
+	transfers, truncated, err := c.history(ctx, wallet)
+	if err != nil {
+		return nil, err
+	}
+
+	exposures := scanExposure(wallet, transfers, c.listed)
+
+	// Связь через посредника: последние переводы самых частых контрагентов-EOA, не входящих в списки и реестр доверенных
+	var hopErrors int
+	var hopUnavailable bool
+	if settings := c.cfg.Checks.SanctionsExposure; settings.OneHop {
+		limit := settings.MaxHopCounterparties
+		if limit <= 0 {
+			limit = defaultMaxHopCounterparties
+		}
+		recent := settings.HopRecentTransfers
+		if recent <= 0 {
+			recent = defaultHopRecentTransfers
+		}
+		candidates, err := c.hopCandidates(ctx, wallet, transfers, limit)
+		if err != nil {
+			c.log.Warnf("Failed to select counterparties of %s: %v", wallet, err)
+			hopUnavailable = true
+		}
+		for _, via := range candidates {
+			viaTransfers, err := c.recentHistory(ctx, via, recent)
+			if err != nil {
+				c.log.Warnf("Failed to get history of counterparty %s: %v", via, err)
+				hopErrors++
+				continue
+			}
+			for _, info := range scanExposure(via, viaTransfers, c.listed) {
+				info.Hops = 1
+				info.Via = via
+				info.ViaURL = util.GetAdressURL(via)
+				exposures = append(exposures, info)
+			}
+		}
+	}
+
+	riskFound := len(exposures) > 0
+	riskLevel := entity.RiskLevelLow
+	var scorePenalty float64
+	var details string
+
+	if riskFound {
+		var direct int
+		for _, info := range exposures {
+			if info.Hops == 0 {
+				direct++
+			}
+			if level := exposureRiskLevel(info); riskRank(level) > riskRank(riskLevel) {
+				riskLevel = level
+			}
+		}
+		scorePenalty = c.cfg.Weight("sanctions_exposure") * 100
+		details = fmt.Sprintf("Found exposure to %d sanctioned or mixer addresses (%d direct, %d via counterparties)", len(exposures), direct, len(exposures)-direct)
+	} else {
+		details = "No exposure to sanctioned addresses or mixers found"
+	}
+	if truncated {
+		details += truncatedHistoryNote
+	}
+	if hopErrors > 0 {
+		details += fmt.Sprintf("; history of %d counterparties is unavailable", hopErrors)
+	}
+	if hopUnavailable {
+		details += "; exposure via counterparties was not checked"
+	}
+
+	return &entity.CheckResult{
+		CheckName:    c.Name(),
+		RiskFound:    riskFound,
+		RiskLevel:    riskLevel,
+		ScorePenalty: scorePenalty,
+		Details:      details,
+		RawData:      exposures,
+	}, nil
+}
+
+// history - Вся история кошелька: обычные и внутренние транзакции и ERC-20 переводы.
+// truncated - история длиннее etherscan.max_results, проверены только самые новые записи.
+func (c *SanctionsExposureCheck) history(ctx context.Context, address string) (transfers []transfer, truncated bool, err error) {
+	txs, err := c.etherscan.GetTransactions(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, false, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	internal, err := c.etherscan.GetInternalTransactions(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, false, fmt.Errorf("failed to get internal transactions: %w", err)
+	}
+	tokenTxs, err := c.etherscan.GetTokenTransfers(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, false, fmt.Errorf("failed to get token transfers: %w", err)
+	}
+	return collectTransfers(txs, internal, tokenTxs), truncated, nil
+}
+
+// recentHistory - Последние limit обычных и внутренних транзакций посредника (по одному запросу каждого вида)
+func (c *SanctionsExposureCheck) recentHistory(ctx context.Context, address string, limit int) ([]transfer, error) {
+	txs, err := c.etherscan.GetRecentTransactions(ctx, address, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+	internal, err := c.etherscan.GetRecentInternalTransactions(ctx, address, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get internal transactions: %w", err)
+	}
+	return collectTransfers(txs, internal, nil), nil
+}
+
+// listed - Запись реестра, если адрес в санкционном списке или списке миксеров
+func (c *SanctionsExposureCheck) listed(address string) (registry.Entry, bool) {
+	entry, ok := c.registry.Lookup(address)
+	if !ok || entry.List != registry.ListBlock {
+		return registry.Entry{}, false
+	}
+	return entry, entry.Category == registry.CategorySanctioned || entry.Category == registry.CategoryMixer
+}
+
+// hopCandidates - Самые частые контрагенты кошелька, история которых проверяется на связь через посредника.
+// Из limit самых частых остаются только EOA (адреса без записи getcontractcreation).
+func (c *SanctionsExposureCheck) hopCandidates(ctx context.Context, wallet string, transfers []transfer, limit int) ([]string, error) {
+	counts := make(map[string]int)
+	for _, t := range transfers {
+		other := counterpart(wallet, t.from, t.to)
+		if other == "" || other == zeroAddress || c.registry.IsAllowed(other) {
+			continue
+		}
+		if _, ok := c.listed(other); ok {
+			continue
+		}
+		counts[other]++
+	}
+
+	candidates := make([]string, 0, len(counts))
+	for addr := range counts {
+		candidates = append(candidates, addr)
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if counts[candidates[i]] != counts[candidates[j]] {
+			return counts[candidates[i]] > counts[candidates[j]]
+		}
+		return candidates[i] < candidates[j]
+	})
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+	if len(candidates) == 0 {
+		return nil, nil
+	}
+
+	contracts, err := c.etherscan.GetContractCreations(ctx, candidates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contract creations: %w", err)
+	}
+	eoas := candidates[:0]
+	for _, addr := range candidates {
+		if _, ok := contracts[addr]; !ok {
+			eoas = append(eoas, addr)
+		}
+	}
+	return eoas, nil
+}
+
+// scanExposure - Переводы subject с адресами из списков, сгруппированные по адресу (упорядочены по адресу)
+func scanExposure(subject string, transfers []transfer, listed func(string) (registry.Entry, bool)) []entity.SanctionsExposureInfo {
+	findings := make(map[string]*entity.SanctionsExposureInfo)
+	for _, t := range transfers {
+		other := counterpart(subject, t.from, t.to)
+		if other == "" {
+			continue
+		}
+		entry, ok := listed(other)
+		if !ok {
+			continue
+		}
+
+		info, ok := findings[other]
+		if !ok {
+			info = &entity.SanctionsExposureInfo{
+				Address:    other,
+				AddressURL: util.GetAdressURL(other),
+				Label:      entry.Label,
+				Category:   string(entry.Category),
+			}
+			findings[other] = info
+		}
+
+		if t.from == subject {
+			info.Sent = addAmount(info.Sent, t)
+		} else {
+			info.Received = addAmount(info.Received, t)
+		}
+		info.Transfers++
+
+		at := parseUnixTime(t.timestamp)
+		if at.IsZero() {
+			if info.ExampleTx == "" {
+				info.ExampleTx = t.hash
+			}
+			continue
+		}
+		if info.FirstSeenAt == nil || at.Before(*info.FirstSeenAt) {
+			first := at
+			info.FirstSeenAt = &first
+		}
+		if info.LastSeenAt == nil || at.After(*info.LastSeenAt) {
+			last := at
+			info.LastSeenAt = &last
+			info.ExampleTx = t.hash
+		}
+	}
+
+	result := make([]entity.SanctionsExposureInfo, 0, len(findings))
+	for _, info := range findings {
+		result = append(result, *info)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+	return result
+}
+
+// collectTransfers - Приводит историю Etherscan к общему виду; неуспешные транзакции пропускаются
+func collectTransfers(txs []provider.Transaction, internal []provider.InternalTransaction, tokenTxs []provider.TokenTransaction) []transfer {
+	var transfers []transfer
+	for _, tx := range txs {
+		if tx.IsError == "1" {
+			continue
+		}
+		amount, _ := parseTokenAmount(tx.Value, ethDecimals)
+		transfers = append(transfers, transfer{from: tx.From, to: tx.To, hash: tx.Hash, timestamp: tx.TimeStamp, symbol: "ETH", amount: amount})
+	}
+	for _, tx := range internal {
+		if tx.IsError == "1" {
+			continue
+		}
+		amount, _ := parseTokenAmount(tx.Value, ethDecimals)
+		transfers = append(transfers, transfer{from: tx.From, to: tx.To, hash: tx.Hash, timestamp: tx.TimeStamp, symbol: "ETH", amount: amount})
+	}
+	for _, tx := range tokenTxs {
+		decimals, _ := strconv.Atoi(tx.TokenDecimal)
+		amount, _ := parseTokenAmount(tx.Value, decimals)
+		transfers = append(transfers, transfer{
+			from: tx.From, to: tx.To, hash: tx.Hash, timestamp: tx.TimeStamp,
+			symbol: tx.TokenSymbol, token: strings.ToLower(tx.ContractAddress), amount: amount,
+		})
+	}
+	for i := range transfers {
+		transfers[i].from = strings.ToLower(transfers[i].from)
+		transfers[i].to = strings.ToLower(transfers[i].to)
+	}
+	return transfers
+}
+
+// addAmount - Добавляет сумму перевода к итогу по активу (переводы без суммы не учитываются)
+func addAmount(amounts []entity.AssetAmount, t transfer) []entity.AssetAmount {
+	if t.amount == 0 {
+		return amounts
+	}
+	for i := range amounts {
+		if amounts[i].Symbol == t.symbol && amounts[i].TokenAddress == t.token {
+			amounts[i].Amount += t.amount
+			return amounts
+		}
+	}
+	return append(amounts, entity.AssetAmount{Symbol: t.symbol, TokenAddress: t.token, Amount: t.amount})
+}
+
+// counterpart - Вторая сторона перевода, в котором участвует subject (адреса в нижнем регистре)
+func counterpart(subject, from, to string) string {
+	switch subject {
+	case from:
+		return to
+	case to:
+		return from
+	}
+	return ""
+}
+
+// exposureRiskLevel - Прямая связь с санкционным адресом - CRITICAL, с миксером - HIGH;
+// через посредника - MEDIUM и LOW соответственно
+func exposureRiskLevel(info entity.SanctionsExposureInfo) entity.RiskLevel {
+	sanctioned := info.Category == string(registry.CategorySanctioned)
+	switch {
+	case info.Hops == 0 && sanctioned:
+		return entity.RiskLevelCritical
+	case info.Hops == 0:
+		return entity.RiskLevelHigh
+	case sanctioned:
+		return entity.RiskLevelMedium
+	default:
+		return entity.RiskLevelLow
+	}
+}
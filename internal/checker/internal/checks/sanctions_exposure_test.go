@@ -0,0 +1,159 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanctionsExposureCheck(t *testing.T) {
+	const (
+		wallet     = "0x742d35cc6634c0532925a3b88650d7241eff5cbc"
+		tornado    = "0x12d66f87a04a9e220743712ce6d9bb1b5616b8fc"
+		sanctioned = "0x098b716b8aaf21512996dc57eb0615e2383e2f96"
+		friend     = "0x7777777777777777777777777777777777777777"
+		router     = "0x8888888888888888888888888888888888888888"
+		usdt       = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	)
+
+	history := map[string]map[string]interface{}{
+		wallet: {
+			"txlist": []provider.Transaction{
+				{Hash: "0xdeposit", From: wallet, To: tornado, Value: "1000000000000000000", TimeStamp: "1700000000"},
+				{Hash: "0xf1", From: wallet, To: friend, Value: "500000000000000000", TimeStamp: "1700000100"},
+				{Hash: "0xf2", From: wallet, To: friend, Value: "500000000000000000", TimeStamp: "1700000200"},
+				{Hash: "0xfailed", From: wallet, To: tornado, Value: "1000000000000000000", TimeStamp: "1700000300", IsError: "1"},
+				{Hash: "0xswap1", From: wallet, To: router, Value: "0", TimeStamp: "1700000400"},
+				{Hash: "0xswap2", From: wallet, To: router, Value: "0", TimeStamp: "1700000500"},
+				{Hash: "0xswap3", From: wallet, To: router, Value: "0", TimeStamp: "1700000600"},
+			},
+			"txlistinternal": []provider.InternalTransaction{
+				{Hash: "0xwithdraw", From: tornado, To: wallet, Value: "900000000000000000", TimeStamp: "1710000000"},
+			},
+			"tokentx": []provider.TokenTransaction{
+				{Hash: "0xusdt", From: sanctioned, To: wallet, ContractAddress: usdt, TokenSymbol: "USDT", TokenDecimal: "6", Value: "100000000", TimeStamp: "1720000000"},
+			},
+		},
+		friend: {
+			"txlist": []provider.Transaction{
+				{Hash: "0xhop", From: friend, To: sanctioned, Value: "2000000000000000000", TimeStamp: "1690000000"},
+			},
+		},
+		// Роутер - контракт: через него идут переводы всех пользователей, посредником он не считается
+		router: {
+			"txlist": []provider.Transaction{
+				{Hash: "0xrouted", From: router, To: tornado, Value: "1000000000000000000", TimeStamp: "1690000000"},
+			},
+		},
+	}
+
+	var hopQueries []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("action") == "getcontractcreation" {
+			var result []provider.ContractCreation
+			for _, addr := range strings.Split(q.Get("contractaddresses"), ",") {
+				if addr == router {
+					result = append(result, provider.ContractCreation{ContractAddress: router})
+				}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": result})
+			return
+		}
+		if address := strings.ToLower(q.Get("address")); address != wallet {
+			hopQueries = append(hopQueries, address+":"+q.Get("action")+":"+q.Get("sort")+":"+q.Get("offset"))
+		}
+		result, ok := history[strings.ToLower(q.Get("address"))][q.Get("action")]
+		if !ok {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "No transactions found", "result": []interface{}{}})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": result})
+	}))
+	t.Cleanup(srv.Close)
+
+	listPath := filepath.Join(t.TempDir(), "sanctions.yaml")
+	require.NoError(t, os.WriteFile(listPath, []byte(`
+version: 1
+entries:
+  - { address: "`+sanctioned+`", list: block, category: sanctioned, label: "Lazarus Group" }
+  - { address: "`+tornado+`", list: block, category: mixer, label: "Tornado Cash 0.1 ETH" }
+`), 0o644))
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Etherscan.URL = srv.URL
+	cfg.Registry.Files = []string{listPath}
+	cfg.Checks.SanctionsExposure.OneHop = true
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	check := NewSanctionsExposureCheck(provider.NewEtherscanClient(cfg, log), reg, cfg, log)
+	result, err := check.Execute(context.Background(), wallet)
+	require.NoError(t, err)
+
+	assert.True(t, result.RiskFound)
+	assert.Equal(t, entity.RiskLevelCritical, result.RiskLevel)
+	assert.Equal(t, "Found exposure to 3 sanctioned or mixer addresses (2 direct, 1 via counterparties)", result.Details)
+
+	exposures, ok := result.RawData.([]entity.SanctionsExposureInfo)
+	require.True(t, ok)
+	require.Len(t, exposures, 3)
+
+	direct := exposures[0]
+	assert.Equal(t, sanctioned, direct.Address)
+	assert.Equal(t, "sanctioned", direct.Category)
+	assert.Equal(t, []entity.AssetAmount{{Symbol: "USDT", TokenAddress: usdt, Amount: 100}}, direct.Received)
+	assert.Equal(t, "0xusdt", direct.ExampleTx)
+
+	mixer := exposures[1]
+	assert.Equal(t, tornado, mixer.Address)
+	assert.Equal(t, 0, mixer.Hops)
+	assert.Equal(t, []entity.AssetAmount{{Symbol: "ETH", Amount: 1}}, mixer.Sent)
+	assert.Equal(t, []entity.AssetAmount{{Symbol: "ETH", Amount: 0.9}}, mixer.Received)
+	assert.Equal(t, 2, mixer.Transfers)
+	require.NotNil(t, mixer.FirstSeenAt)
+	require.NotNil(t, mixer.LastSeenAt)
+	assert.Equal(t, int64(1700000000), mixer.FirstSeenAt.Unix())
+	assert.Equal(t, int64(1710000000), mixer.LastSeenAt.Unix())
+	assert.Equal(t, "0xwithdraw", mixer.ExampleTx)
+
+	assert.Equal(t, []string{friend + ":txlist:desc:100", friend + ":txlistinternal:desc:100"}, hopQueries,
+		"only EOA counterparties are checked, one recent page each")
+
+	hop := exposures[2]
+	assert.Equal(t, sanctioned, hop.Address)
+	assert.Equal(t, 1, hop.Hops)
+	assert.Equal(t, friend, hop.Via)
+	assert.Equal(t, []entity.AssetAmount{{Symbol: "ETH", Amount: 2}}, hop.Sent)
+
+	// История длиннее etherscan.max_results проверяется по самым новым записям, а не считается ошибкой
+	cfg.Etherscan.MaxResults = 3
+	cfg.Checks.SanctionsExposure.OneHop = false
+	check = NewSanctionsExposureCheck(provider.NewEtherscanClient(cfg, log), reg, cfg, log)
+	result, err = check.Execute(context.Background(), wallet)
+	require.NoError(t, err)
+	assert.True(t, result.RiskFound)
+	assert.True(t, strings.HasSuffix(result.Details, truncatedHistoryNote), result.Details)
+}
+
+func TestExposureRiskLevel(t *testing.T) {
+	assert.Equal(t, entity.RiskLevelCritical, exposureRiskLevel(entity.SanctionsExposureInfo{Category: "sanctioned"}))
+	assert.Equal(t, entity.RiskLevelHigh, exposureRiskLevel(entity.SanctionsExposureInfo{Category: "mixer"}))
+	assert.Equal(t, entity.RiskLevelMedium, exposureRiskLevel(entity.SanctionsExposureInfo{Category: "sanctioned", Hops: 1}))
+	assert.Equal(t, entity.RiskLevelLow, exposureRiskLevel(entity.SanctionsExposureInfo{Category: "mixer", Hops: 1}))
+}
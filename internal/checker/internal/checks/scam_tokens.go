@@ -3,31 +3,67 @@ package checks
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 
 	"alpha-hygiene-backend/config"
 	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
 	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+	"alpha-hygiene-backend/pkg/util"
 
 	"github.com/sirupsen/logrus"
 )
 
-// parseCreatorPercent - Пытается преобразовать строковой процент создателя в float64
-func parseCreatorPercent(percentStr string) (float64, error) {
-	percentStr = trimString(percentStr)
-	if percentStr == "" {
-		return 0.0, fmt.Errorf("empty creator percent")
-	}
-	return strconv.ParseFloat(percentStr, 64)
+const (
+	// Пороги по умолчанию (см. config checks.scam_tokens), %
+	defaultCreatorPercent = 50
+	defaultTaxPercent     = 10
+)
+
+// tokenFlag - Флаг GoPlus token_security, который выставляется значением "1"
+type tokenFlag struct {
+	code  string
+	level entity.RiskLevel
+	value func(*provider.TokenSecurity) string
 }
 
-// parseTax - Пытается преобразовать строковой налог в float64
-func parseTax(taxStr string) (float64, error) {
-	taxStr = trimString(taxStr)
-	if taxStr == "" {
-		return 0.0, fmt.Errorf("empty tax")
+// tokenFlags - Флаги token_security по убыванию риска. HIGH - токен нельзя продать или баланс
+// контролирует владелец; MEDIUM - признаки rug pull; LOW - права владельца контракта, обычные и для легитимных токенов.
+var tokenFlags = []tokenFlag{
+	{"is_honeypot", entity.RiskLevelHigh, func(s *provider.TokenSecurity) string { return s.IsHoneypot }},
+	{"cannot_buy", entity.RiskLevelHigh, func(s *provider.TokenSecurity) string { return s.CannotBuy }},
+	{"cannot_sell_all", entity.RiskLevelHigh, func(s *provider.TokenSecurity) string { return s.CannotSellAll }},
+	{"owner_change_balance", entity.RiskLevelHigh, func(s *provider.TokenSecurity) string { return s.OwnerChangeBalance }},
+	{"is_airdrop_scam", entity.RiskLevelHigh, func(s *provider.TokenSecurity) string { return s.IsAirdropScam }},
+	{"honeypot_with_same_creator", entity.RiskLevelMedium, func(s *provider.TokenSecurity) string { return s.HoneypotWithSameCreator }},
+	{"hidden_owner", entity.RiskLevelMedium, func(s *provider.TokenSecurity) string { return s.HiddenOwner }},
+	{"can_take_back_ownership", entity.RiskLevelMedium, func(s *provider.TokenSecurity) string { return s.CanTakeBackOwnership }},
+	{"selfdestruct", entity.RiskLevelMedium, func(s *provider.TokenSecurity) string { return s.SelfDestruct }},
+	{"is_blacklisted", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.IsBlacklisted }},
+	{"is_mintable", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.IsMintable }},
+	{"transfer_pausable", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.TransferPausable }},
+	{"slippage_modifiable", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.SlippageModifiable }},
+	{"personal_slippage_modifiable", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.PersonalSlippageModifiable }},
+	{"trading_cooldown", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.TradingCooldown }},
+	{"anti_whale_modifiable", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.AntiWhaleModifiable }},
+	{"external_call", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.ExternalCall }},
+	{"is_proxy", entity.RiskLevelLow, func(s *provider.TokenSecurity) string { return s.IsProxy }},
+}
+
+// parseFraction - Преобразует долю GoPlus ("0.1") в проценты (10)
+func parseFraction(value string) (float64, error) {
+	value = trimString(value)
+	if value == "" {
+		return 0.0, fmt.Errorf("empty value")
+	}
+	fraction, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0.0, err
 	}
-	return strconv.ParseFloat(taxStr, 64)
+	return fraction * 100, nil
 }
 
 // trimString - Удаляет возможные лишние символы из строки
@@ -45,38 +81,26 @@ func trimString(s string) string {
 	return string(trimmed)
 }
 
-// адреса которые уже в черных списках
-// https://chainabuse.com/reports?query=honeypot
-// 0xaff8ed5415b68ab81786200e3bfd74d7c37df31e DOPP
-// 0x060360e3f44c2e8ad7b48c3e3a5533075939aee6 XMTP
-// 0x83e0bac227956aab8834324e31e9420505760db9 ZYNK
-// 0xd7a7c5bd399df4aeadd2cf9ac19b0276afd7e7a6 ALTS
-// 0x6a100890d851867a97dbd560874ad0f6f5253eb1 BRZ
-// 0xb279577df4e083228c2f797fdade203fc7848feb HAR
-// Honeypot tokens , created by same addresses 0xD8c4cA0809Be7A908779405C02EEA87AfB98c836 contract creator.
-// 0x5ecCBfFb378463782069080F7ACD6d2E7199d903 , possible wallet 0xA961A268cE78DBB24Ac55239609535Fab4B0bef2 for receiving funds.
-
-// https://chainabuse.com/reports?query=fake+airdrop
-// 0xdaecFaE58c531cF68cf8651A2fdA75AFF1C56dD4
-// 0x794a299251d9b4129048567d291128d17e7fcae3
-// DRAIN TX: 0xdc46ccd32bb3a2cf3b8f4249839fad727063f7058111e1ae69d76f6762976e40
-// MALICIOUS CONTRACT: 0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220
-// Contract Explorer: https://etherscan.io/address/0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220
-
-// ScamTokensCheck - Проверка на скам-токены
+// ScamTokensCheck - Проверка на скам-токены.
+// Для каждого токена составляется профиль риска из всех сработавших флагов GoPlus token_security;
+// токен с флагом уровня MEDIUM и выше (или из block-списка реестра) считается скамом.
 type ScamTokensCheck struct {
 	goPlusProvider *provider.GoPlusClient
-	alchemyClient  *provider.AlchemyClient
+	balances       provider.BalanceProvider
+	registry       *registry.Registry
+	labeler        *labels.Labeler
 	cfg            *config.Config
 	log            *logrus.Entry
 }
 
 // NewScamTokensCheck - Создает новую проверку на скам-токены
-func NewScamTokensCheck(goPlusProvider *provider.GoPlusClient, alchemyClient *provider.AlchemyClient, cfg *config.Config, log *logrus.Entry) *ScamTokensCheck {
+func NewScamTokensCheck(goPlusProvider *provider.GoPlusClient, balances provider.BalanceProvider, reg *registry.Registry, labeler *labels.Labeler, cfg *config.Config, log *logrus.Entry) *ScamTokensCheck {
 	logger := log.WithFields(logrus.Fields{"component": "scam_tokens"})
 	return &ScamTokensCheck{
 		goPlusProvider: goPlusProvider,
-		alchemyClient:  alchemyClient,
+		balances:       balances,
+		registry:       reg,
+		labeler:        labeler,
 		cfg:            cfg,
 		log:            logger,
 	}
@@ -92,22 +116,34 @@ func (c *ScamTokensCheck) Execute(ctx context.Context, address string) (*entity.
 	c.log.Debugf("Checking for scam tokens for address: %s", address)
 
 	// Получаем список токенов на кошельке
-	tokens, err := c.alchemyClient.GetERC20Tokens(ctx, address)
+	tokens, err := c.balances.GetERC20Tokens(ctx, address)
 	if err != nil {
 		c.log.Errorf("Failed to get ERC20 tokens for address %s: %v", address, err)
 		return nil, err
 	}
 
-	// Извлекаем адреса токенов для проверки
+	// Заблокированные в реестре токены - скам без запроса к GoPlus, доверенные не проверяются
+	var profiles []entity.TokenRiskProfile
 	var tokenAddresses []string
+	names := make(map[string]*provider.TokenBalance, len(tokens))
 	for _, token := range tokens {
+		addr := strings.ToLower(token.ContractAddress)
+		names[addr] = token
+		if entry, ok := c.registry.Lookup(addr); ok {
+			if entry.List == registry.ListBlock {
+				profiles = append(profiles, newTokenRiskProfile(addr, token.TokenName, token.TokenSymbol,
+					[]entity.TokenRiskFlag{{Code: "registry:" + string(entry.Category), RiskLevel: entity.RiskLevelHigh}}))
+			}
+			continue
+		}
 		tokenAddresses = append(tokenAddresses, token.ContractAddress)
 	}
 
 	c.log.Debugf("Found %d tokens to check for scams", len(tokenAddresses))
 
 	// Проверяем токены через GoPlus API
-	var scamTokens []string
+	var uncheckedTokens []string
+	var security map[string]provider.TokenSecurity
 	if len(tokenAddresses) > 0 {
 		securityResult, err := c.goPlusProvider.GetTokenSecurity(ctx, tokenAddresses)
 		if err != nil {
@@ -115,62 +151,149 @@ func (c *ScamTokensCheck) Execute(ctx context.Context, address string) (*entity.
 			return nil, err
 		}
 
-		// Анализируем результаты и собираем скам-токены
-		for addr, info := range securityResult.Result {
-			// Проверяем на потенциальные скам-токены по нескольким критериям:
+		security = securityResult.Result
 
-			// 1. Токен нельзя купить или продать
-			if info.CannotBuy == "1" {
-				scamTokens = append(scamTokens, addr)
-				continue
-			}
-
-			// 2. Токен не добавлен в ни один DEX
-			if info.IsInDex == "0" {
-				scamTokens = append(scamTokens, addr)
-				continue
-			}
+		// Токены, которые не удалось проверить, не валят всю проверку
+		for addr, reason := range securityResult.Failed {
+			c.log.Warnf("Token %s was not checked: %s", addr, reason)
+			uncheckedTokens = append(uncheckedTokens, addr)
+		}
 
-			// 3. Высокий процент владения создателем (более 50%) - потенциальный Rug Pull
-			creatorPercent, err := parseCreatorPercent(info.CreatorPercent)
-			if err == nil && creatorPercent > 50.0 {
-				scamTokens = append(scamTokens, addr)
+		thresholds := scamThresholds(c.cfg.Checks.ScamTokens)
+		for addr, info := range securityResult.Result {
+			flags := tokenRiskFlags(&info, thresholds)
+			if len(flags) == 0 {
 				continue
 			}
-
-			// 4. Высокие налоги на покупку или продажу (более 10%)
-			buyTax, err := parseTax(info.BuyTax)
-			if err == nil && buyTax > 10.0 {
-				scamTokens = append(scamTokens, addr)
-				continue
+			name, symbol := info.TokenName, info.TokenSymbol
+			if token, ok := names[strings.ToLower(addr)]; ok && symbol == "" {
+				name, symbol = token.TokenName, token.TokenSymbol
 			}
+			profiles = append(profiles, newTokenRiskProfile(strings.ToLower(addr), name, symbol, flags))
+		}
+	}
 
-			sellTax, err := parseTax(info.SellTax)
-			if err == nil && sellTax > 10.0 {
-				scamTokens = append(scamTokens, addr)
-				continue
-			}
+	// Сначала скам-токены и более высокий риск
+	sort.Slice(profiles, func(i, j int) bool {
+		if riskRank(profiles[i].RiskLevel) != riskRank(profiles[j].RiskLevel) {
+			return riskRank(profiles[i].RiskLevel) > riskRank(profiles[j].RiskLevel)
+		}
+		return profiles[i].Address < profiles[j].Address
+	})
 
-			// 5. Создаатель имеет 100% токенов
-			if info.CreatorPercent == "100.000000" || info.CreatorBalance == info.TotalSupply {
-				scamTokens = append(scamTokens, addr)
-				continue
-			}
+	// Описания скам-токенов: из реестра или по данным GoPlus
+	riskLevel := entity.RiskLevelLow
+	var scamCount int
+	tokenHints := make(map[string]*labels.Hint)
+	for _, profile := range profiles {
+		if !profile.Scam {
+			continue
+		}
+		scamCount++
+		if riskRank(profile.RiskLevel) > riskRank(riskLevel) {
+			riskLevel = profile.RiskLevel
 		}
+		var hint *labels.Hint
+		if info, ok := security[profile.Address]; ok {
+			hint = labels.HintFromTokenSecurity(info)
+		}
+		tokenHints[profile.Address] = hint
 	}
 
-	riskFound := len(scamTokens) > 0
+	riskFound := scamCount > 0
 	var scorePenalty float64
+	var details string
 
 	if riskFound {
-		scorePenalty = c.cfg.Scoring.Weights["scam_tokens"] * 100
+		scorePenalty = c.cfg.Weight("scam_tokens") * 100
+		details = fmt.Sprintf("Found %d scam tokens", scamCount)
+	} else {
+		details = "No scam tokens found"
+	}
+	if other := len(profiles) - scamCount; other > 0 {
+		details = fmt.Sprintf("%s; %d tokens have low-risk flags", details, other)
+	}
+	if len(uncheckedTokens) > 0 {
+		details = fmt.Sprintf("%s; %d of %d tokens could not be checked", details, len(uncheckedTokens), len(tokenAddresses))
 	}
 
 	return &entity.CheckResult{
-		CheckName:    c.Name(),
-		RiskFound:    riskFound,
-		RiskLevel:    entity.RiskLevelHigh,
-		ScorePenalty: scorePenalty,
-		RawData:      scamTokens,
+		CheckName:      c.Name(),
+		RiskFound:      riskFound,
+		RiskLevel:      riskLevel,
+		ScorePenalty:   scorePenalty,
+		Details:        details,
+		RawData:        profiles,
+		Counterparties: labels.Sorted(c.labeler.LabelAll(ctx, tokenHints)),
 	}, nil
 }
+
+// scamThresholds - Пороги из конфига с подстановкой значений по умолчанию
+func scamThresholds(settings config.ScamTokensConfig) config.ScamTokensConfig {
+	if settings.CreatorPercent <= 0 {
+		settings.CreatorPercent = defaultCreatorPercent
+	}
+	if settings.BuyTaxPercent <= 0 {
+		settings.BuyTaxPercent = defaultTaxPercent
+	}
+	if settings.SellTaxPercent <= 0 {
+		settings.SellTaxPercent = defaultTaxPercent
+	}
+	return settings
+}
+
+// tokenRiskFlags - Все сработавшие флаги токена по данным GoPlus, по убыванию риска
+func tokenRiskFlags(info *provider.TokenSecurity, thresholds config.ScamTokensConfig) []entity.TokenRiskFlag {
+	var flags []entity.TokenRiskFlag
+	for _, flag := range tokenFlags {
+		if flag.value(info) == "1" {
+			flags = append(flags, entity.TokenRiskFlag{Code: flag.code, RiskLevel: flag.level})
+		}
+	}
+	if info.FakeToken != nil && info.FakeToken.Value == 1 {
+		flags = append(flags, entity.TokenRiskFlag{Code: "fake_token", RiskLevel: entity.RiskLevelHigh, Value: info.FakeToken.TrueTokenAddress})
+	}
+
+	// Пороговые признаки rug pull: налоги, доля создателя, отсутствие ликвидности на DEX
+	if buyTax, err := parseFraction(info.BuyTax); err == nil && buyTax > thresholds.BuyTaxPercent {
+		flags = append(flags, entity.TokenRiskFlag{Code: "buy_tax", RiskLevel: entity.RiskLevelMedium, Value: fmt.Sprintf("%.1f%%", buyTax)})
+	}
+	if sellTax, err := parseFraction(info.SellTax); err == nil && sellTax > thresholds.SellTaxPercent {
+		flags = append(flags, entity.TokenRiskFlag{Code: "sell_tax", RiskLevel: entity.RiskLevelMedium, Value: fmt.Sprintf("%.1f%%", sellTax)})
+	}
+	creatorPercent, err := parseFraction(info.CreatorPercent)
+	if info.CreatorBalance != "" && info.CreatorBalance != "0" && info.CreatorBalance == info.TotalSupply {
+		creatorPercent, err = 100, nil
+	}
+	if err == nil && creatorPercent > thresholds.CreatorPercent {
+		flags = append(flags, entity.TokenRiskFlag{Code: "creator_percent", RiskLevel: entity.RiskLevelMedium, Value: fmt.Sprintf("%.1f%%", creatorPercent)})
+	}
+	if info.IsInDex == "0" {
+		flags = append(flags, entity.TokenRiskFlag{Code: "not_in_dex", RiskLevel: entity.RiskLevelMedium})
+	}
+	if info.IsOpenSource == "0" {
+		flags = append(flags, entity.TokenRiskFlag{Code: "closed_source", RiskLevel: entity.RiskLevelLow})
+	}
+
+	sort.SliceStable(flags, func(i, j int) bool { return riskRank(flags[i].RiskLevel) > riskRank(flags[j].RiskLevel) })
+	return flags
+}
+
+// newTokenRiskProfile - Профиль риска токена: уровень - максимальный среди флагов
+func newTokenRiskProfile(address, name, symbol string, flags []entity.TokenRiskFlag) entity.TokenRiskProfile {
+	profile := entity.TokenRiskProfile{
+		Address:    address,
+		AddressURL: util.GetAdressURL(address),
+		Name:       name,
+		Symbol:     symbol,
+		RiskLevel:  entity.RiskLevelLow,
+		Flags:      flags,
+	}
+	for _, flag := range flags {
+		if riskRank(flag.RiskLevel) > riskRank(profile.RiskLevel) {
+			profile.RiskLevel = flag.RiskLevel
+		}
+	}
+	profile.Scam = riskRank(profile.RiskLevel) >= riskRank(entity.RiskLevelMedium)
+	return profile
+}
@@ -0,0 +1,123 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBalances []*provider.TokenBalance
+
+func (f fakeBalances) GetETHBalance(context.Context, string) (float64, error) {
+	return 0, nil
+}
+
+func (f fakeBalances) GetERC20Tokens(context.Context, string) ([]*provider.TokenBalance, error) {
+	return f, nil
+}
+
+func TestTokenRiskFlags(t *testing.T) {
+	thresholds := scamThresholds(config.ScamTokensConfig{})
+
+	tests := []struct {
+		name  string
+		info  provider.TokenSecurity
+		codes []string
+	}{
+		{"clean token", provider.TokenSecurity{IsInDex: "1", IsOpenSource: "1", BuyTax: "0", SellTax: "0.05", CreatorPercent: "0.01"}, nil},
+		{"honeypot", provider.TokenSecurity{IsHoneypot: "1", CannotSellAll: "1", IsInDex: "1"}, []string{"is_honeypot", "cannot_sell_all"}},
+		{"high sell tax and no dex", provider.TokenSecurity{SellTax: "0.25", IsInDex: "0"}, []string{"sell_tax", "not_in_dex"}},
+		{"creator holds whole supply", provider.TokenSecurity{CreatorBalance: "1000", TotalSupply: "1000", CreatorPercent: "0.000000"}, []string{"creator_percent"}},
+		{"owner powers only", provider.TokenSecurity{IsMintable: "1", TransferPausable: "1", IsProxy: "1", IsOpenSource: "0"},
+			[]string{"is_mintable", "transfer_pausable", "is_proxy", "closed_source"}},
+		{"fake token", provider.TokenSecurity{FakeToken: &provider.TokenFakeInfo{TrueTokenAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", Value: 1}, HiddenOwner: "1"},
+			[]string{"fake_token", "hidden_owner"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var codes []string
+			for _, flag := range tokenRiskFlags(&tt.info, thresholds) {
+				codes = append(codes, flag.Code)
+			}
+			assert.Equal(t, tt.codes, codes)
+		})
+	}
+}
+
+func TestTokenRiskFlags_ConfigurableThresholds(t *testing.T) {
+	info := provider.TokenSecurity{BuyTax: "0.15", CreatorPercent: "0.4"}
+
+	flags := tokenRiskFlags(&info, scamThresholds(config.ScamTokensConfig{CreatorPercent: 30, BuyTaxPercent: 20}))
+	require.Len(t, flags, 1)
+	assert.Equal(t, entity.TokenRiskFlag{Code: "creator_percent", RiskLevel: entity.RiskLevelMedium, Value: "40.0%"}, flags[0])
+}
+
+func TestScamTokensCheck(t *testing.T) {
+	const (
+		honeypot = "0x1111111111111111111111111111111111111111"
+		mintable = "0x2222222222222222222222222222222222222222"
+		clean    = "0x3333333333333333333333333333333333333333"
+		dopp     = "0xaff8ed5415b68ab81786200e3bfd74d7c37df31e"
+		usdt     = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"code": 1, "message": "OK", "result": map[string]provider.TokenSecurity{
+			honeypot: {TokenName: "Free Money", TokenSymbol: "FREE", IsHoneypot: "1", IsInDex: "1", IsOpenSource: "1"},
+			mintable: {TokenSymbol: "MINT", IsMintable: "1", IsInDex: "1", IsOpenSource: "1"},
+			clean:    {TokenSymbol: "OK", IsInDex: "1", IsOpenSource: "1"},
+		}})
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.GoPlus.URL = srv.URL
+	cfg.Scoring.Weights = map[string]float64{"scam_tokens": 0.2}
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	balances := fakeBalances{
+		{ContractAddress: honeypot, Balance: "1"},
+		{ContractAddress: mintable, Balance: "1"},
+		{ContractAddress: clean, Balance: "1"},
+		{ContractAddress: dopp, TokenName: "DOPP", TokenSymbol: "DOPP", Balance: "1"},
+		{ContractAddress: usdt, Balance: "1"},
+	}
+	check := NewScamTokensCheck(provider.NewGoPlusClient(cfg, log), balances, reg, nil, cfg, log)
+
+	result, err := check.Execute(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	require.NoError(t, err)
+	assert.True(t, result.RiskFound)
+	assert.Equal(t, entity.RiskLevelHigh, result.RiskLevel)
+	assert.InDelta(t, 20, result.ScorePenalty, 1e-9)
+	assert.Equal(t, "Found 2 scam tokens; 1 tokens have low-risk flags", result.Details)
+
+	profiles, ok := result.RawData.([]entity.TokenRiskProfile)
+	require.True(t, ok)
+	require.Len(t, profiles, 3)
+
+	assert.Equal(t, honeypot, profiles[0].Address)
+	assert.Equal(t, "FREE", profiles[0].Symbol)
+	assert.True(t, profiles[0].Scam)
+	assert.Equal(t, []entity.TokenRiskFlag{{Code: "is_honeypot", RiskLevel: entity.RiskLevelHigh}}, profiles[0].Flags)
+
+	assert.Equal(t, dopp, profiles[1].Address)
+	assert.Equal(t, "registry:honeypot", profiles[1].Flags[0].Code)
+
+	assert.Equal(t, mintable, profiles[2].Address)
+	assert.False(t, profiles[2].Scam)
+	assert.Equal(t, entity.RiskLevelLow, profiles[2].RiskLevel)
+}
@@ -0,0 +1,299 @@
+package checks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/labels"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/pkg/util"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// Пороги по умолчанию, дней
+	defaultStaleMediumAfterDays = 180
+	defaultStaleHighAfterDays   = 365
+	defaultStaleUnusedAfterDays = 90
+
+	// Коды причин устаревания approval
+	StaleReasonApprovalAge   = "approval_age"
+	StaleReasonSpenderUnused = "spender_unused"
+
+	day = 24 * time.Hour
+)
+
+// staleThresholds - Пороги устаревания approval
+type staleThresholds struct {
+	medium time.Duration
+	high   time.Duration
+	unused time.Duration
+}
+
+// spenderActivity - Последняя транзакция кошелька в адрес спендера
+type spenderActivity struct {
+	at   time.Time
+	hash string
+}
+
+// StaleApprovalsCheck - Проверка устаревших approvals: давно выданных или на спендеров,
+// с которыми кошелек давно не взаимодействовал (по истории транзакций Etherscan)
+type StaleApprovalsCheck struct {
+	approvals provider.ApprovalProvider
+	etherscan *provider.EtherscanClient
+	labeler   *labels.Labeler
+	cfg       *config.Config
+	log       *logrus.Entry
+}
+
+// NewStaleApprovalsCheck - Создает новую проверку устаревших approvals
+func NewStaleApprovalsCheck(approvals provider.ApprovalProvider, etherscan *provider.EtherscanClient, labeler *labels.Labeler, cfg *config.Config, log *logrus.Entry) *StaleApprovalsCheck {
+	logger := log.WithFields(logrus.Fields{"component": "stale_approvals"})
+	return &StaleApprovalsCheck{
+		approvals: approvals,
+		etherscan: etherscan,
+		labeler:   labeler,
+		cfg:       cfg,
+		log:       logger,
+	}
+}
+
+// Name - Возвращает имя проверки
+func (c *StaleApprovalsCheck) Name() string {
+	return "stale_approvals"
+}
+
+// Execute - Выполняет проверку
+func (c *StaleApprovalsCheck) Execute(ctx context.Context, address string) (*entity.CheckResult, error) {
+	c.log.Debugf("Checking stale approvals for address: %s", address)
+
+	resp, err := c.approvals.GetTokenApprovals(ctx, address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token approvals: %w", err)
+	}
+
+	// История транзакций нужна только для признака "последнее использование"; без нее проверяется только возраст
+	activity, truncated, historyErr := c.spenderActivity(ctx, address)
+	if historyErr != nil {
+		c.log.Warnf("Failed to get transaction history for address %s: %v", address, historyErr)
+	}
+
+	thresholds := c.thresholds()
+	now := time.Now()
+
+	var stale []entity.StaleApprovalInfo
+	spenderHints := make(map[string]*labels.Hint)
+	for _, tokenApproval := range resp.Result {
+		for _, approval := range tokenApproval.ApprovedList {
+			if approval.ApprovedAmount == "0" {
+				continue
+			}
+
+			spender := strings.ToLower(approval.ApprovedContract)
+			approvedAt := approvalTime(approval)
+			lastUsed, used := activity[spender]
+			unlimited := approval.ApprovedAmount == "Unlimited"
+			// В обрезанной истории отсутствие транзакций спендеру ничего не говорит о последнем использовании
+			historyKnown := historyErr == nil && (used || !truncated)
+
+			level, reasons := classifyStaleApproval(approvedAt, lastUsed.at, unlimited, historyKnown, thresholds, now)
+			if len(reasons) == 0 {
+				continue
+			}
+
+			info := entity.StaleApprovalInfo{
+				TokenAddress:   tokenApproval.TokenAddress,
+				TokenName:      tokenApproval.TokenName,
+				SpenderAddress: approval.ApprovedContract,
+				SpenderURL:     util.GetAdressURL(approval.ApprovedContract),
+				ApprovedAmount: approval.ApprovedAmount,
+				IsUnlimited:    unlimited,
+				Reasons:        reasons,
+				RiskLevel:      level,
+			}
+			if !approvedAt.IsZero() {
+				info.ApprovedAt = &approvedAt
+				info.AgeDays = int(now.Sub(approvedAt) / day)
+			}
+			if used {
+				info.LastUsedAt = &lastUsed.at
+				info.LastUsedTx = lastUsed.hash
+			}
+			if historyKnown {
+				if last := latest(lastUsed.at, approvedAt); !last.IsZero() {
+					days := int(now.Sub(last) / day)
+					info.DaysSinceLastUse = &days
+				}
+			}
+
+			spenderHints[spender] = labels.HintFromAddressInfo(approval.AddressInfo)
+			stale = append(stale, info)
+		}
+	}
+
+	// Описания спендеров
+	spenderLabels := c.labeler.LabelAll(ctx, spenderHints)
+	for i := range stale {
+		if label, ok := spenderLabels[strings.ToLower(stale[i].SpenderAddress)]; ok {
+			stale[i].SpenderLabel = &label
+		}
+	}
+
+	riskFound := len(stale) > 0
+	maxLevel := entity.RiskLevelLow
+	var scorePenalty float64
+	var details string
+
+	if riskFound {
+		oldest := 0
+		for _, info := range stale {
+			if riskRank(info.RiskLevel) > riskRank(maxLevel) {
+				maxLevel = info.RiskLevel
+			}
+			if info.AgeDays > oldest {
+				oldest = info.AgeDays
+			}
+		}
+		scorePenalty = c.cfg.Weight("stale_approvals") * 100
+		details = fmt.Sprintf("Found %d stale approvals (oldest is %d days old)", len(stale), oldest)
+	} else {
+		details = "No stale approvals found"
+	}
+	if historyErr != nil {
+		details += "; spender activity is unavailable, only approval age was checked"
+	} else if truncated {
+		details += truncatedHistoryNote
+	}
+
+	return &entity.CheckResult{
+		CheckName:      c.Name(),
+		RiskFound:      riskFound,
+		RiskLevel:      maxLevel,
+		ScorePenalty:   scorePenalty,
+		Details:        details,
+		RawData:        stale,
+		Counterparties: labels.Sorted(spenderLabels),
+	}, nil
+}
+
+// thresholds - Пороги из конфигурации с учетом значений по умолчанию
+func (c *StaleApprovalsCheck) thresholds() staleThresholds {
+	cfg := c.cfg.Checks.StaleApprovals
+	days := func(value, def int) time.Duration {
+		if value <= 0 {
+			value = def
+		}
+		return time.Duration(value) * day
+	}
+	return staleThresholds{
+		medium: days(cfg.MediumAfterDays, defaultStaleMediumAfterDays),
+		high:   days(cfg.HighAfterDays, defaultStaleHighAfterDays),
+		unused: days(cfg.UnusedAfterDays, defaultStaleUnusedAfterDays),
+	}
+}
+
+// spenderActivity - Последние успешные транзакции кошелька по адресу получателя.
+// truncated - история обрезана до самых новых транзакций: найденные даты точны, но спендеры без транзакций
+// могли использоваться раньше.
+func (c *StaleApprovalsCheck) spenderActivity(ctx context.Context, address string) (activity map[string]spenderActivity, truncated bool, err error) {
+	txs, err := c.etherscan.GetTransactions(ctx, address, 0, 0)
+	if err = partialHistory(err, &truncated); err != nil {
+		return nil, false, err
+	}
+
+	owner := strings.ToLower(address)
+	activity = make(map[string]spenderActivity)
+	for _, tx := range txs {
+		if strings.ToLower(tx.From) != owner || tx.To == "" || tx.IsError == "1" {
+			continue
+		}
+		at := parseUnixTime(tx.TimeStamp)
+		if at.IsZero() {
+			continue
+		}
+		to := strings.ToLower(tx.To)
+		if at.After(activity[to].at) {
+			activity[to] = spenderActivity{at: at, hash: tx.Hash}
+		}
+	}
+	return activity, truncated, nil
+}
+
+// classifyStaleApproval - Причины устаревания approval и уровень риска.
+// Уровень определяется возрастом approval; неограниченный approval поднимает его на ступень (не выше HIGH).
+// Неиспользуемым считается спендер без транзакций кошелька с момента выдачи approval дольше порога unused.
+func classifyStaleApproval(approvedAt, lastUsedAt time.Time, unlimited, historyKnown bool, t staleThresholds, now time.Time) (entity.RiskLevel, []string) {
+	level := entity.RiskLevelLow
+	var reasons []string
+
+	if !approvedAt.IsZero() {
+		age := now.Sub(approvedAt)
+		switch {
+		case age >= t.high:
+			level = entity.RiskLevelHigh
+			reasons = append(reasons, StaleReasonApprovalAge)
+		case age >= t.medium:
+			level = entity.RiskLevelMedium
+			reasons = append(reasons, StaleReasonApprovalAge)
+		}
+	}
+
+	if historyKnown {
+		if last := latest(lastUsedAt, approvedAt); !last.IsZero() && now.Sub(last) >= t.unused {
+			reasons = append(reasons, StaleReasonSpenderUnused)
+		}
+	}
+
+	if len(reasons) == 0 {
+		return "", nil
+	}
+	if unlimited {
+		switch level {
+		case entity.RiskLevelLow:
+			level = entity.RiskLevelMedium
+		case entity.RiskLevelMedium:
+			level = entity.RiskLevelHigh
+		}
+	}
+	return level, reasons
+}
+
+// approvalTime - Время последнего изменения approval (или первой выдачи, если оно неизвестно)
+func approvalTime(approval provider.ApprovedSpender) time.Time {
+	ts := approval.ApprovedTime
+	if ts <= 0 {
+		ts = approval.InitialApprovalTime
+	}
+	if ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).UTC()
+}
+
+// latest - Более позднее из двух времен
+func latest(a, b time.Time) time.Time {
+	if a.After(b) {
+		return a
+	}
+	return b
+}
+
+// riskRank - Порядок уровней риска
+func riskRank(level entity.RiskLevel) int {
+	switch level {
+	case entity.RiskLevelLow:
+		return 1
+	case entity.RiskLevelMedium:
+		return 2
+	case entity.RiskLevelHigh:
+		return 3
+	case entity.RiskLevelCritical:
+		return 4
+	}
+	return 0
+}
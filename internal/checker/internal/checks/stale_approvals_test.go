@@ -0,0 +1,143 @@
+package checks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyStaleApproval(t *testing.T) {
+	now := time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)
+	thresholds := staleThresholds{medium: 180 * day, high: 365 * day, unused: 90 * day}
+	daysAgo := func(days int) time.Time { return now.Add(-time.Duration(days) * day) }
+
+	tests := []struct {
+		name         string
+		approvedAt   time.Time
+		lastUsedAt   time.Time
+		unlimited    bool
+		historyKnown bool
+		level        entity.RiskLevel
+		reasons      []string
+	}{
+		{"fresh and used", daysAgo(10), daysAgo(1), true, true, "", nil},
+		{"recent approval, spender never used", daysAgo(30), time.Time{}, false, true, "", nil},
+		{"unused spender", daysAgo(120), daysAgo(100), false, true, entity.RiskLevelLow, []string{StaleReasonSpenderUnused}},
+		{"unused spender, unlimited", daysAgo(120), time.Time{}, true, true, entity.RiskLevelMedium, []string{StaleReasonSpenderUnused}},
+		{"old but recently used", daysAgo(200), daysAgo(5), false, true, entity.RiskLevelMedium, []string{StaleReasonApprovalAge}},
+		{"very old and unused", daysAgo(400), daysAgo(390), false, true, entity.RiskLevelHigh, []string{StaleReasonApprovalAge, StaleReasonSpenderUnused}},
+		{"very old unlimited stays high", daysAgo(400), time.Time{}, true, true, entity.RiskLevelHigh, []string{StaleReasonApprovalAge, StaleReasonSpenderUnused}},
+		{"no history, only age", daysAgo(200), time.Time{}, true, false, entity.RiskLevelHigh, []string{StaleReasonApprovalAge}},
+		{"unknown approval time", time.Time{}, time.Time{}, true, true, "", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			level, reasons := classifyStaleApproval(tt.approvedAt, tt.lastUsedAt, tt.unlimited, tt.historyKnown, thresholds, now)
+			assert.Equal(t, tt.level, level)
+			assert.Equal(t, tt.reasons, reasons)
+		})
+	}
+}
+
+func TestStaleApprovalsCheck(t *testing.T) {
+	const (
+		wallet  = "0x742d35cc6634c0532925a3b88650d7241eff5cbc"
+		token   = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+		router  = "0x68b3465833FB72A70ecDF485E0e4C7bD8665Fc45"
+		idle    = "0x1111111111111111111111111111111111111111"
+		revoked = "0x2222222222222222222222222222222222222222"
+		fresh   = "0x3333333333333333333333333333333333333333"
+	)
+	now := time.Now()
+	daysAgo := func(days int) int64 { return now.Add(-time.Duration(days) * day).Unix() }
+	timestamp := func(days int) string { return strconv.FormatInt(daysAgo(days), 10) }
+
+	approvals := fakeApprovals{resp: &provider.TokenApprovalResponse{Result: []provider.TokenApproval{{
+		TokenAddress: token,
+		TokenName:    "Tether USD",
+		ApprovedList: []provider.ApprovedSpender{
+			{ApprovedContract: router, ApprovedAmount: "Unlimited", ApprovedTime: daysAgo(400)},
+			{ApprovedContract: idle, ApprovedAmount: "1000", ApprovedTime: daysAgo(120)},
+			{ApprovedContract: revoked, ApprovedAmount: "0", ApprovedTime: daysAgo(400)},
+			{ApprovedContract: fresh, ApprovedAmount: "Unlimited", ApprovedTime: daysAgo(10)},
+		},
+	}}}}
+
+	// Etherscan отдает адреса получателей в нижнем регистре; неуспешная транзакция использованием не считается
+	historyAvailable := true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !historyAvailable {
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "NOTOK", "result": "Invalid API Key"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": []provider.Transaction{
+			{Hash: "0xswap", From: wallet, To: strings.ToLower(router), TimeStamp: timestamp(5)},
+			{Hash: "0xfailed", From: wallet, To: idle, TimeStamp: timestamp(1), IsError: "1"},
+		}})
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Etherscan.URL = srv.URL
+	cfg.Scoring.Weights = map[string]float64{"stale_approvals": 0.1}
+	check := NewStaleApprovalsCheck(approvals, provider.NewEtherscanClient(cfg, log), nil, cfg, log)
+
+	t.Run("with spender activity", func(t *testing.T) {
+		result, err := check.Execute(context.Background(), wallet)
+		require.NoError(t, err)
+
+		assert.True(t, result.RiskFound)
+		assert.Equal(t, entity.RiskLevelHigh, result.RiskLevel)
+		assert.InDelta(t, 10, result.ScorePenalty, 1e-9)
+		assert.Equal(t, "Found 2 stale approvals (oldest is 400 days old)", result.Details)
+
+		stale, ok := result.RawData.([]entity.StaleApprovalInfo)
+		require.True(t, ok)
+		require.Len(t, stale, 2)
+
+		used := stale[0]
+		assert.Equal(t, router, used.SpenderAddress)
+		assert.Equal(t, []string{StaleReasonApprovalAge}, used.Reasons)
+		assert.Equal(t, entity.RiskLevelHigh, used.RiskLevel)
+		assert.Equal(t, "0xswap", used.LastUsedTx)
+		require.NotNil(t, used.DaysSinceLastUse)
+		assert.Equal(t, 5, *used.DaysSinceLastUse)
+
+		unused := stale[1]
+		assert.Equal(t, idle, unused.SpenderAddress)
+		assert.Equal(t, []string{StaleReasonSpenderUnused}, unused.Reasons)
+		assert.Equal(t, entity.RiskLevelLow, unused.RiskLevel)
+		assert.Empty(t, unused.LastUsedTx)
+		require.NotNil(t, unused.DaysSinceLastUse)
+		assert.Equal(t, 120, *unused.DaysSinceLastUse)
+	})
+
+	t.Run("history unavailable", func(t *testing.T) {
+		historyAvailable = false
+		result, err := check.Execute(context.Background(), wallet)
+		require.NoError(t, err)
+
+		assert.Equal(t, "Found 1 stale approvals (oldest is 400 days old); spender activity is unavailable, only approval age was checked", result.Details)
+		stale, ok := result.RawData.([]entity.StaleApprovalInfo)
+		require.True(t, ok)
+		require.Len(t, stale, 1)
+		assert.Equal(t, router, stale[0].SpenderAddress)
+		assert.Equal(t, []string{StaleReasonApprovalAge}, stale[0].Reasons)
+		assert.Nil(t, stale[0].DaysSinceLastUse)
+	})
+}
@@ -1,5 +1,7 @@
 package entity
 
+import "time"
+
 // Language - Язык для отчета
 type Language string
 
@@ -15,6 +17,13 @@ type WalletReport struct {
 	Checks          []CheckResult `json:"checks"`
 	Errors          []string      `json:"errors,omitempty"`
 	Recommendations string        `json:"recommendations,omitempty"`
+	// Stale - Отчет взят из кэша после истечения срока свежести и обновляется в фоне
+	Stale bool `json:"stale,omitempty"`
+	// Counterparties - Описания адресов, с которыми связаны результаты проверок (спендеры, токены)
+	Counterparties []AddressLabel `json:"counterparties,omitempty"`
+	// CachedAt, ExpiresAt - Когда отчет был сохранен в кэш и до какого момента он считается свежим
+	CachedAt  *time.Time `json:"cached_at,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
 }
 
 // CheckResult - Результат одной проверки
@@ -25,8 +34,22 @@ type CheckResult struct {
 	ScorePenalty float64     `json:"score_penalty"`
 	Details      string      `json:"details"`
 	RawData      interface{} `json:"raw_data"`
+	// Source - Откуда взят результат: выполнен сейчас или из кэша проверок
+	Source CheckSource `json:"source,omitempty"`
+	// CachedAt - Когда результат был сохранен в кэш (для Source = cache)
+	CachedAt *time.Time `json:"cached_at,omitempty"`
+	// Counterparties - Описания адресов, упомянутых в результате проверки
+	Counterparties []AddressLabel `json:"counterparties,omitempty"`
 }
 
+// CheckSource - Происхождение результата проверки
+type CheckSource string
+
+const (
+	CheckSourceFresh CheckSource = "fresh"
+	CheckSourceCache CheckSource = "cache"
+)
+
 // RiskLevel - Уровень риска
 type RiskLevel string
 
@@ -46,17 +69,277 @@ type TokenInfo struct {
 	Balance    float64 `json:"balance"`
 	USDValue   float64 `json:"usd_value"`
 	IsStable   bool    `json:"is_stable"`
+	// Category - native, категория реестра (stablecoin, infrastructure, ...) или other
+	Category string `json:"category"`
+	// Price - USD цена из провайдера цен (0 - цена неизвестна)
+	Price float64 `json:"price,omitempty"`
+	// Share - Доля в стоимости портфеля, %
+	Share float64 `json:"share"`
+}
+
+// AssetCompositionInfo - Состав портфеля: доли активов и категорий, концентрация, стейблкоины без привязки
+type AssetCompositionInfo struct {
+	Tokens        []TokenInfo `json:"tokens"`
+	TotalUSD      float64     `json:"total_usd"`
+	StableRatio   float64     `json:"stable_ratio"`
+	VolatileRatio float64     `json:"volatile_ratio"`
+	// HHI - Индекс Херфиндаля-Хиршмана по долям активов: от 1/N (равные доли) до 1 (один актив)
+	HHI float64 `json:"hhi"`
+	// TopAsset, TopAssetShare - Крупнейший актив и его доля, %
+	TopAsset      string               `json:"top_asset,omitempty"`
+	TopAssetShare float64              `json:"top_asset_share"`
+	Categories    []AssetCategoryShare `json:"categories"`
+	Depegged      []DepegInfo          `json:"depegged,omitempty"`
+	// Findings - volatile_ratio, concentration, single_asset_dominance, stablecoin_depeg
+	Findings []string `json:"findings,omitempty"`
+}
+
+// AssetCategoryShare - Стоимость и доля категории активов
+type AssetCategoryShare struct {
+	Category string  `json:"category"`
+	USDValue float64 `json:"usd_value"`
+	Share    float64 `json:"share"`
+	Tokens   int     `json:"tokens"`
+}
+
+// DepegInfo - Стейблкоин, цена которого отклонилась от $1
+type DepegInfo struct {
+	Address string  `json:"address"`
+	Symbol  string  `json:"symbol"`
+	Price   float64 `json:"price"`
+	// Deviation - Отклонение цены от $1, %
+	Deviation float64 `json:"deviation"`
+	USDValue  float64 `json:"usd_value"`
+}
+
+// TokenRiskProfile - Флаги риска токена на кошельке по данным реестра и GoPlus token_security
+type TokenRiskProfile struct {
+	Address    string `json:"address"`
+	AddressURL string `json:"address_url"`
+	Name       string `json:"name,omitempty"`
+	Symbol     string `json:"symbol,omitempty"`
+	// Scam - Есть флаг уровня MEDIUM и выше; такие токены учитываются в оценке
+	Scam      bool            `json:"scam"`
+	RiskLevel RiskLevel       `json:"risk_level"`
+	Flags     []TokenRiskFlag `json:"flags"`
+}
+
+// TokenRiskFlag - Сработавший флаг риска токена
+type TokenRiskFlag struct {
+	// Code - Поле GoPlus (is_honeypot, buy_tax, ...), not_in_dex, closed_source или registry:<категория>
+	Code      string    `json:"code"`
+	RiskLevel RiskLevel `json:"risk_level"`
+	// Value - Значение для пороговых флагов (налог, доля создателя)
+	Value string `json:"value,omitempty"`
 }
 
 // ApprovalInfo - Информация о разрешении на токен
 type ApprovalInfo struct {
-	TokenAddress    string  `json:"token_address"`
-	TokenURL        string  `json:"token_url"`
-	TokenName       string  `json:"token_name"`
-	SpenderAddress  string  `json:"spender_address"`
-	SpenderURL      string  `json:"spender_url"`
-	ApprovedAmount  string  `json:"approved_amount"`
-	ExposureBalance float64 `json:"exposure_balance"`
-	IsUnlimited     bool    `json:"is_unlimited"`
-	IsMalicious     bool    `json:"is_malicious"`
+	TokenAddress   string `json:"token_address"`
+	TokenURL       string `json:"token_url"`
+	TokenName      string `json:"token_name"`
+	SpenderAddress string `json:"spender_address"`
+	SpenderURL     string `json:"spender_url"`
+	// SpenderLabel - Человекочитаемое описание спендера
+	SpenderLabel    *AddressLabel `json:"spender_label,omitempty"`
+	ApprovedAmount  string        `json:"approved_amount"`
+	ExposureBalance float64       `json:"exposure_balance"`
+	IsUnlimited     bool          `json:"is_unlimited"`
+	IsMalicious     bool          `json:"is_malicious"`
+	// Via - Контракт-посредник approval второго уровня (permit2 - allowance внутри Uniswap Permit2)
+	Via string `json:"via,omitempty"`
+	// ExpiresAt - Срок действия allowance (для Permit2)
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	RiskLevel RiskLevel  `json:"risk_level"`
+	// Reasons - Коды сработавших правил (blocked_spender, spender_eoa, fresh_unverified_contract, unlimited, ...)
+	Reasons []string `json:"reasons"`
+}
+
+// StaleApprovalInfo - Устаревший approval: давно выданный или на неиспользуемого спендера
+type StaleApprovalInfo struct {
+	TokenAddress   string        `json:"token_address"`
+	TokenName      string        `json:"token_name"`
+	SpenderAddress string        `json:"spender_address"`
+	SpenderURL     string        `json:"spender_url"`
+	SpenderLabel   *AddressLabel `json:"spender_label,omitempty"`
+	ApprovedAmount string        `json:"approved_amount"`
+	IsUnlimited    bool          `json:"is_unlimited"`
+	ApprovedAt     *time.Time    `json:"approved_at,omitempty"`
+	AgeDays        int           `json:"age_days"`
+	// LastUsedAt, LastUsedTx - Последняя транзакция кошелька в адрес спендера (нет - не найдена или история недоступна)
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	LastUsedTx string     `json:"last_used_tx,omitempty"`
+	// DaysSinceLastUse - Дней с последнего использования или выдачи approval
+	DaysSinceLastUse *int `json:"days_since_last_use,omitempty"`
+	// Reasons - Коды причин: approval_age, spender_unused
+	Reasons   []string  `json:"reasons"`
+	RiskLevel RiskLevel `json:"risk_level"`
+}
+
+// PoisonedAddressInfo - Адрес, с которого (или на который) пришли переводы для отравления истории кошелька.
+// Такой адрес нельзя копировать из истории транзакций.
+type PoisonedAddressInfo struct {
+	Address    string `json:"address"`
+	AddressURL string `json:"address_url"`
+	// ImitatedAddress - Реальный контрагент кошелька, под которого маскируется адрес
+	ImitatedAddress string `json:"imitated_address,omitempty"`
+	// Kinds - Признаки: lookalike_address, zero_value_transfer, fake_token, funds_sent
+	Kinds []string `json:"kinds"`
+	// FakeTokens - Контракты токенов, имитирующих символы известных токенов
+	FakeTokens []string   `json:"fake_tokens,omitempty"`
+	Transfers  int        `json:"transfers"`
+	ExampleTx  string     `json:"example_tx"`
+	LastSeenAt *time.Time `json:"last_seen_at,omitempty"`
+	// SentTx - Первый перевод кошелька на этот адрес: адрес уже скопирован из истории
+	SentTx string `json:"sent_tx,omitempty"`
+}
+
+// DelegationInfo - Делегирование кода EOA по EIP-7702
+type DelegationInfo struct {
+	Address string `json:"address"`
+	// IsContract - Адрес является обычным контрактом (смарт-аккаунтом), а не EOA
+	IsContract bool `json:"is_contract"`
+	Delegated  bool `json:"delegated"`
+	// DelegateAddress - Контракт, код которого исполняется от имени EOA
+	DelegateAddress string        `json:"delegate_address,omitempty"`
+	DelegateURL     string        `json:"delegate_url,omitempty"`
+	DelegateLabel   *AddressLabel `json:"delegate_label,omitempty"`
+	// Classification - known_safe, unknown или malicious
+	Classification string `json:"classification,omitempty"`
+	// Reasons - Категория реестра или флаги GoPlus, по которым делегат признан вредоносным
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// SanctionsExposureInfo - Связь кошелька с санкционным адресом или миксером.
+// При Hops = 1 связь идет через контрагента Via, а Sent/Received - его переводы с адресом из списка.
+type SanctionsExposureInfo struct {
+	Address    string `json:"address"`
+	AddressURL string `json:"address_url"`
+	Label      string `json:"label,omitempty"`
+	// Category - sanctioned или mixer
+	Category string `json:"category"`
+	// Hops - 0 для прямых переводов, 1 - через одного посредника
+	Hops        int           `json:"hops"`
+	Via         string        `json:"via,omitempty"`
+	ViaURL      string        `json:"via_url,omitempty"`
+	Sent        []AssetAmount `json:"sent,omitempty"`
+	Received    []AssetAmount `json:"received,omitempty"`
+	Transfers   int           `json:"transfers"`
+	FirstSeenAt *time.Time    `json:"first_seen_at,omitempty"`
+	LastSeenAt  *time.Time    `json:"last_seen_at,omitempty"`
+	ExampleTx   string        `json:"example_tx,omitempty"`
+}
+
+// SafeAuditInfo - Конфигурация Safe multisig и найденные в ней проблемы
+type SafeAuditInfo struct {
+	Address   string `json:"address"`
+	IsSafe    bool   `json:"is_safe"`
+	Version   string `json:"version,omitempty"`
+	Threshold uint64 `json:"threshold,omitempty"`
+	Nonce     uint64 `json:"nonce,omitempty"`
+	// Guard - Контракт, проверяющий транзакции Safe (пусто - guard не задан)
+	Guard        string            `json:"guard,omitempty"`
+	Owners       []SafeOwnerInfo   `json:"owners,omitempty"`
+	Modules      []SafeModuleInfo  `json:"modules,omitempty"`
+	OwnerChanges []SafeOwnerChange `json:"owner_changes,omitempty"`
+	Findings     []SafeFinding     `json:"findings,omitempty"`
+}
+
+// SafeOwnerInfo - Владелец Safe
+type SafeOwnerInfo struct {
+	Address    string `json:"address"`
+	AddressURL string `json:"address_url"`
+	// Risky - Владелец в block-списке реестра или помечен GoPlus
+	Risky   bool     `json:"risky"`
+	Reasons []string `json:"reasons,omitempty"`
+}
+
+// SafeModuleInfo - Модуль Safe, который может исполнять транзакции без подписей владельцев
+type SafeModuleInfo struct {
+	Address    string `json:"address"`
+	AddressURL string `json:"address_url"`
+	Label      string `json:"label,omitempty"`
+	// Known - Модуль в allow-списке реестра
+	Known bool `json:"known"`
+}
+
+// SafeOwnerChange - Изменение владельцев или порога Safe
+type SafeOwnerChange struct {
+	// Event - added_owner, removed_owner или changed_threshold
+	Event     string     `json:"event"`
+	Owner     string     `json:"owner,omitempty"`
+	Threshold uint64     `json:"threshold,omitempty"`
+	At        *time.Time `json:"at,omitempty"`
+	TxHash    string     `json:"tx_hash,omitempty"`
+}
+
+// SafeFinding - Проблема конфигурации Safe
+type SafeFinding struct {
+	// Code - single_signer_threshold, unknown_module, recent_owner_change или risky_owner
+	Code      string    `json:"code"`
+	RiskLevel RiskLevel `json:"risk_level"`
+	Address   string    `json:"address,omitempty"`
+	Message   string    `json:"message"`
+}
+
+// LendingHealthInfo - Заемные позиции кошелька в протоколах кредитования
+type LendingHealthInfo struct {
+	Positions          []LendingPositionInfo `json:"positions"`
+	TotalCollateralUSD float64               `json:"total_collateral_usd"`
+	TotalDebtUSD       float64               `json:"total_debt_usd"`
+	// LowestHealthFactor - Минимальный health factor среди позиций (не задан, если позиций нет)
+	LowestHealthFactor *float64 `json:"lowest_health_factor,omitempty"`
+}
+
+// LendingPositionInfo - Заемная позиция в одном рынке Aave v3 или Compound v3
+type LendingPositionInfo struct {
+	// Protocol - aave_v3 или compound_v3
+	Protocol  string `json:"protocol"`
+	Market    string `json:"market"`
+	MarketURL string `json:"market_url"`
+	// CollateralUSD, DebtUSD - Стоимость залога и долг в USD (0, если нет цены базового актива Compound v3)
+	CollateralUSD float64 `json:"collateral_usd"`
+	DebtUSD       float64 `json:"debt_usd"`
+	// BaseToken, Collateral, Debt - Базовый актив рынка Compound v3 и стоимость залога и долг в нем (для Aave не заданы)
+	BaseToken  string  `json:"base_token,omitempty"`
+	Collateral float64 `json:"collateral,omitempty"`
+	Debt       float64 `json:"debt,omitempty"`
+	// HealthFactor - Отношение залога, взвешенного по порогам ликвидации, к долгу (не задан, если залог не удалось оценить)
+	HealthFactor *float64 `json:"health_factor,omitempty"`
+	// LiquidationThreshold - Средневзвешенный порог ликвидации залога, %
+	LiquidationThreshold float64   `json:"liquidation_threshold"`
+	Liquidatable         bool      `json:"liquidatable"`
+	RiskLevel            RiskLevel `json:"risk_level,omitempty"`
+}
+
+// AssetAmount - Сумма переводов одного актива
+type AssetAmount struct {
+	Symbol string `json:"symbol"`
+	// TokenAddress - Контракт токена (пусто для ETH)
+	TokenAddress string  `json:"token_address,omitempty"`
+	Amount       float64 `json:"amount"`
+}
+
+// LabelSource - Откуда взято описание адреса
+type LabelSource string
+
+const (
+	LabelSourceRegistry  LabelSource = "registry"
+	LabelSourceGoPlus    LabelSource = "goplus"
+	LabelSourceEtherscan LabelSource = "etherscan"
+	// LabelSourceDerived - Имя неизвестно, описание составлено по признакам адреса
+	LabelSourceDerived LabelSource = "derived"
+)
+
+// AddressLabel - Человекочитаемое описание адреса
+type AddressLabel struct {
+	Address string      `json:"address"`
+	Label   string      `json:"label"`
+	Source  LabelSource `json:"source"`
+	// Category, List - Категория и список (allow/block) из реестра адресов
+	Category   string     `json:"category,omitempty"`
+	List       string     `json:"list,omitempty"`
+	IsContract *bool      `json:"is_contract,omitempty"`
+	Verified   *bool      `json:"verified,omitempty"`
+	DeployedAt *time.Time `json:"deployed_at,omitempty"`
 }
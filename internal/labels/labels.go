@@ -0,0 +1,347 @@
+package labels
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultMaxLookups - Сколько адресов за один вызов досматривается через Etherscan по умолчанию
+	defaultMaxLookups = 20
+	// defaultLookupConcurrency - Количество параллельных запросов getsourcecode по умолчанию
+	defaultLookupConcurrency = 2
+	// defaultInfoTTL - Срок кэширования метаданных контрактов по умолчанию
+	defaultInfoTTL = 7 * 24 * time.Hour
+)
+
+// Hint - Сведения об адресе, уже полученные от провайдера (например, address_info GoPlus)
+type Hint struct {
+	Name       string
+	Tag        string
+	IsContract *bool
+	Verified   *bool
+	DeployedAt time.Time
+}
+
+// HintFromAddressInfo - Подсказка из address_info спендера в ответе GoPlus token_approval_security.
+// Пустой address_info (например, approvals из собственного узла) подсказкой не считается.
+func HintFromAddressInfo(info provider.AddressInfo) *Hint {
+	if info.ContractName == "" && info.Tag == nil && info.CreatorAddress == "" && info.DeployedTime == 0 && info.IsContract == 0 {
+		return nil
+	}
+	hint := &Hint{
+		Name:       strings.TrimSpace(info.ContractName),
+		IsContract: boolPtr(info.IsContract == 1),
+	}
+	if info.Tag != nil {
+		hint.Tag = strings.TrimSpace(*info.Tag)
+	}
+	if info.IsContract == 1 {
+		hint.Verified = boolPtr(info.IsOpenSource == 1)
+	}
+	if info.DeployedTime > 0 {
+		hint.DeployedAt = time.Unix(info.DeployedTime, 0).UTC()
+	}
+	return hint
+}
+
+// HintFromTokenSecurity - Подсказка из ответа GoPlus token_security
+func HintFromTokenSecurity(info provider.TokenSecurity) *Hint {
+	hint := &Hint{IsContract: boolPtr(true)}
+	switch {
+	case info.TokenName != "" && info.TokenSymbol != "":
+		hint.Name = fmt.Sprintf("%s (%s)", info.TokenName, info.TokenSymbol)
+	case info.TokenName != "":
+		hint.Name = info.TokenName
+	default:
+		hint.Name = info.TokenSymbol
+	}
+	if info.IsOpenSource != "" {
+		hint.Verified = boolPtr(info.IsOpenSource == "1")
+	}
+	return hint
+}
+
+// addressInfo - Метаданные адреса из Etherscan (кэшируются)
+type addressInfo struct {
+	Name       string    `json:"name,omitempty"`
+	IsContract bool      `json:"is_contract"`
+	Verified   bool      `json:"verified"`
+	DeployedAt time.Time `json:"deployed_at,omitempty"`
+}
+
+// Labeler - Описывает адреса человекочитаемыми метками.
+// Источники по приоритету: реестр адресов, подсказки провайдеров (GoPlus), метаданные Etherscan.
+// Если имя не найдено, описание составляется по признакам: "unverified contract deployed 3 days ago".
+type Labeler struct {
+	registry          *registry.Registry
+	etherscan         *provider.EtherscanClient
+	maxLookups        int
+	lookupConcurrency int
+	infoTTL           time.Duration
+	infoCache         *cache.Typed[addressInfo]
+	now               func() time.Time
+	log               *logrus.Entry
+}
+
+// New - Создает сервис меток; при labels.enabled = false возвращает nil (метки не добавляются)
+func New(reg *registry.Registry, etherscan *provider.EtherscanClient, cfg *config.Config, log *logrus.Entry) *Labeler {
+	if !cfg.Labels.Enabled {
+		return nil
+	}
+	maxLookups := cfg.Labels.MaxLookups
+	if maxLookups <= 0 {
+		maxLookups = defaultMaxLookups
+	}
+	concurrency := cfg.Labels.LookupConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultLookupConcurrency
+	}
+	infoTTL := defaultInfoTTL
+	if cfg.Cache.LabelTTLSeconds > 0 {
+		infoTTL = time.Duration(cfg.Cache.LabelTTLSeconds) * time.Second
+	}
+	return &Labeler{
+		registry:          reg,
+		etherscan:         etherscan,
+		maxLookups:        maxLookups,
+		lookupConcurrency: concurrency,
+		infoTTL:           infoTTL,
+		now:               time.Now,
+		log:               log.WithFields(logrus.Fields{"component": "labels"}),
+	}
+}
+
+// UseCache - Подключает кэш метаданных Etherscan
+func (l *Labeler) UseCache(store cache.Store) {
+	if l == nil {
+		return
+	}
+	l.infoCache = cache.NewTyped[addressInfo](store, cache.NamespaceAddressInfo, l.infoTTL, l.log)
+}
+
+// Label - Описание одного адреса
+func (l *Labeler) Label(ctx context.Context, address string, hint *Hint) *entity.AddressLabel {
+	labels := l.LabelAll(ctx, map[string]*Hint{address: hint})
+	if label, ok := labels[strings.ToLower(address)]; ok {
+		return &label
+	}
+	return nil
+}
+
+// LabelAll - Описания адресов (ключ - адрес в нижнем регистре; подсказка может быть nil).
+// Адреса без записи в реестре и без имени в подсказке досматриваются через Etherscan,
+// но не более maxLookups за вызов. Ошибки Etherscan не прерывают работу - такие адреса описываются по подсказке.
+func (l *Labeler) LabelAll(ctx context.Context, hints map[string]*Hint) map[string]entity.AddressLabel {
+	if l == nil || len(hints) == 0 {
+		return nil
+	}
+
+	normalized := make(map[string]*Hint, len(hints))
+	var lookup []string
+	for address, hint := range hints {
+		address = strings.ToLower(address)
+		normalized[address] = hint
+		if _, ok := l.registry.Lookup(address); ok {
+			continue
+		}
+		if hint == nil || (hint.Name == "" && hint.Tag == "" && hint.IsContract == nil) {
+			lookup = append(lookup, address)
+		}
+	}
+
+	sort.Strings(lookup)
+	infos := l.lookup(ctx, lookup)
+
+	result := make(map[string]entity.AddressLabel, len(normalized))
+	for address, hint := range normalized {
+		result[address] = l.compose(address, hint, infos[address])
+	}
+	return result
+}
+
+// Sorted - Описания, упорядоченные по адресу (для стабильного вывода в отчетах)
+func Sorted(labels map[string]entity.AddressLabel) []entity.AddressLabel {
+	if len(labels) == 0 {
+		return nil
+	}
+	result := make([]entity.AddressLabel, 0, len(labels))
+	for _, label := range labels {
+		result = append(result, label)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Address < result[j].Address })
+	return result
+}
+
+// lookup - Метаданные адресов из кэша или Etherscan
+func (l *Labeler) lookup(ctx context.Context, addresses []string) map[string]*addressInfo {
+	infos := make(map[string]*addressInfo, len(addresses))
+	if len(addresses) == 0 {
+		return infos
+	}
+
+	cached, missing := l.infoCache.GetMany(ctx, addresses)
+	for address, info := range cached {
+		info := info
+		infos[address] = &info
+	}
+	if len(missing) > l.maxLookups {
+		l.log.Debugf("Skipping Etherscan lookup for %d of %d addresses", len(missing)-l.maxLookups, len(missing))
+		missing = missing[:l.maxLookups]
+	}
+	if len(missing) == 0 || l.etherscan == nil {
+		return infos
+	}
+
+	creations, err := l.etherscan.GetContractCreations(ctx, missing)
+	if err != nil {
+		l.log.Warnf("Failed to get contract creations: %v", err)
+		return infos
+	}
+
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(l.lookupConcurrency)
+	for _, a := range missing {
+		address := a
+		creation, isContract := creations[address]
+		if !isContract {
+			info := addressInfo{}
+			l.infoCache.Set(ctx, address, info)
+			mu.Lock()
+			infos[address] = &info
+			mu.Unlock()
+			continue
+		}
+
+		g.Go(func() error {
+			info := addressInfo{IsContract: true, DeployedAt: creation.CreatedAt()}
+			source, err := l.etherscan.GetContractSource(gctx, address)
+			if err != nil {
+				// Без данных о верификации метаданные не кэшируются
+				l.log.Warnf("Failed to get contract source for %s: %v", address, err)
+			} else {
+				info.Verified = source.IsVerified()
+				if info.Verified {
+					info.Name = source.ContractName
+				}
+				l.infoCache.Set(gctx, address, info)
+			}
+
+			mu.Lock()
+			infos[address] = &info
+			mu.Unlock()
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return infos
+}
+
+// compose - Составляет описание адреса из всех источников
+func (l *Labeler) compose(address string, hint *Hint, info *addressInfo) entity.AddressLabel {
+	label := entity.AddressLabel{Address: address}
+
+	if hint != nil {
+		label.IsContract = hint.IsContract
+		label.Verified = hint.Verified
+		if !hint.DeployedAt.IsZero() {
+			deployedAt := hint.DeployedAt
+			label.DeployedAt = &deployedAt
+		}
+	}
+	if info != nil {
+		label.IsContract = boolPtr(info.IsContract)
+		if info.IsContract {
+			label.Verified = boolPtr(info.Verified)
+		}
+		if !info.DeployedAt.IsZero() {
+			deployedAt := info.DeployedAt
+			label.DeployedAt = &deployedAt
+		}
+	}
+
+	switch entry, ok := l.registry.Lookup(address); {
+	case ok:
+		label.Source = entity.LabelSourceRegistry
+		label.Category = string(entry.Category)
+		label.List = string(entry.List)
+		label.Label = entry.Label
+		if label.Label == "" {
+			label.Label = strings.ReplaceAll(string(entry.Category), "_", " ")
+		}
+	case hint != nil && hint.Tag != "":
+		label.Source = entity.LabelSourceGoPlus
+		label.Label = hint.Tag
+	case hint != nil && hint.Name != "":
+		label.Source = entity.LabelSourceGoPlus
+		label.Label = hint.Name
+	case info != nil && info.Name != "":
+		label.Source = entity.LabelSourceEtherscan
+		label.Label = info.Name
+	default:
+		label.Source = entity.LabelSourceDerived
+		label.Label = describe(label, l.now())
+	}
+
+	return label
+}
+
+// describe - Описание адреса без имени по его признакам
+func describe(label entity.AddressLabel, now time.Time) string {
+	if label.IsContract == nil {
+		return "unknown address"
+	}
+	if !*label.IsContract {
+		return "externally owned account"
+	}
+
+	description := "contract"
+	if label.Verified != nil {
+		if *label.Verified {
+			description = "verified contract"
+		} else {
+			description = "unverified contract"
+		}
+	}
+	if label.DeployedAt != nil {
+		description += " deployed " + formatAge(now.Sub(*label.DeployedAt))
+	}
+	return description
+}
+
+// formatAge - Давность в днях, месяцах или годах
+func formatAge(age time.Duration) string {
+	days := int(age.Hours() / 24)
+	switch {
+	case days < 1:
+		return "today"
+	case days == 1:
+		return "1 day ago"
+	case days < 60:
+		return fmt.Sprintf("%d days ago", days)
+	case days < 730:
+		return fmt.Sprintf("%d months ago", days/30)
+	default:
+		return fmt.Sprintf("%d years ago", days/365)
+	}
+}
+
+// boolPtr - Указатель на значение bool
+func boolPtr(v bool) *bool {
+	return &v
+}
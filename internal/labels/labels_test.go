@@ -0,0 +1,162 @@
+package labels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
+	"alpha-hygiene-backend/internal/entity"
+	"alpha-hygiene-backend/internal/provider"
+	"alpha-hygiene-backend/internal/registry"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	uniswapRouter = "0xE592427A0AEce92De3Edee1F18E0157C05861564"
+	freshContract = "0x1111111111111111111111111111111111111111"
+	namedContract = "0x2222222222222222222222222222222222222222"
+	walletAddress = "0x3333333333333333333333333333333333333333"
+)
+
+var testNow = time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+// newTestLabeler - Labeler с фиктивным Etherscan: freshContract - непроверенный контракт трехдневной давности,
+// namedContract - верифицированный контракт, остальные адреса - не контракты
+func newTestLabeler(t *testing.T, requests *atomic.Int32) *Labeler {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		q := r.URL.Query()
+		var result interface{}
+		switch q.Get("action") {
+		case "getcontractcreation":
+			var creations []provider.ContractCreation
+			for _, addr := range strings.Split(q.Get("contractaddresses"), ",") {
+				if addr == freshContract || addr == namedContract {
+					creations = append(creations, provider.ContractCreation{
+						ContractAddress: addr,
+						TimeStamp:       strconv.FormatInt(testNow.Add(-3*24*time.Hour).Unix(), 10),
+					})
+				}
+			}
+			if len(creations) == 0 {
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "0", "message": "No data found", "result": nil})
+				return
+			}
+			result = creations
+		case "getsourcecode":
+			source := provider.ContractSource{ABI: "Contract source code not verified"}
+			if q.Get("address") == namedContract {
+				source = provider.ContractSource{SourceCode: "contract Vault {}", ABI: "[]", ContractName: "Vault"}
+			}
+			result = []provider.ContractSource{source}
+		default:
+			t.Errorf("unexpected Etherscan action %q", q.Get("action"))
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"status": "1", "message": "OK", "result": result})
+	}))
+	t.Cleanup(srv.Close)
+
+	log := logrus.NewEntry(logrus.New())
+	cfg := &config.Config{}
+	cfg.Etherscan.URL = srv.URL
+	cfg.Labels.Enabled = true
+	cfg.Labels.MaxLookups = 3
+
+	reg, err := registry.New(cfg, log)
+	require.NoError(t, err)
+
+	labeler := New(reg, provider.NewEtherscanClient(cfg, log), cfg, log)
+	labeler.UseCache(cache.NewMemoryStore(cfg, log))
+	labeler.now = func() time.Time { return testNow }
+	return labeler
+}
+
+func TestLabeler_ResolvesFromAllSources(t *testing.T) {
+	var requests atomic.Int32
+	labeler := newTestLabeler(t, &requests)
+	tag := "Inferno Drainer"
+
+	result := labeler.LabelAll(context.Background(), map[string]*Hint{
+		uniswapRouter: nil,
+		"0x4444444444444444444444444444444444444444": HintFromAddressInfo(provider.AddressInfo{Tag: &tag, IsContract: 1}),
+		freshContract: nil,
+		namedContract: nil,
+		walletAddress: nil,
+	})
+	require.Len(t, result, 5)
+
+	router := result[strings.ToLower(uniswapRouter)]
+	assert.Equal(t, "Uniswap V3 Router", router.Label)
+	assert.Equal(t, entity.LabelSourceRegistry, router.Source)
+	assert.Equal(t, "dex_router", router.Category)
+
+	drainer := result["0x4444444444444444444444444444444444444444"]
+	assert.Equal(t, "Inferno Drainer", drainer.Label)
+	assert.Equal(t, entity.LabelSourceGoPlus, drainer.Source)
+
+	fresh := result[freshContract]
+	assert.Equal(t, "unverified contract deployed 3 days ago", fresh.Label)
+	assert.Equal(t, entity.LabelSourceDerived, fresh.Source)
+
+	named := result[namedContract]
+	assert.Equal(t, "Vault", named.Label)
+	assert.Equal(t, entity.LabelSourceEtherscan, named.Source)
+
+	assert.Equal(t, "externally owned account", result[walletAddress].Label)
+
+	// Метаданные Etherscan кэшируются
+	before := requests.Load()
+	again := labeler.Label(context.Background(), freshContract, nil)
+	require.NotNil(t, again)
+	assert.Equal(t, "unverified contract deployed 3 days ago", again.Label)
+	assert.Equal(t, before, requests.Load())
+}
+
+func TestLabeler_LimitsEtherscanLookups(t *testing.T) {
+	var requests atomic.Int32
+	labeler := newTestLabeler(t, &requests)
+
+	hints := make(map[string]*Hint)
+	for i := 0; i < 5; i++ {
+		hints["0x55555555555555555555555555555555555555"+strconv.Itoa(10+i)] = nil
+	}
+
+	result := labeler.LabelAll(context.Background(), hints)
+	require.Len(t, result, 5)
+
+	var unknown int
+	for _, label := range result {
+		if label.Label == "unknown address" {
+			unknown++
+		}
+	}
+	assert.Equal(t, 2, unknown)
+}
+
+func TestLabeler_DisabledIsNil(t *testing.T) {
+	cfg := &config.Config{}
+	labeler := New(nil, nil, cfg, logrus.NewEntry(logrus.New()))
+	assert.Nil(t, labeler)
+	assert.Nil(t, labeler.LabelAll(context.Background(), map[string]*Hint{walletAddress: nil}))
+	assert.Nil(t, labeler.Label(context.Background(), walletAddress, nil))
+}
+
+func TestFormatAge(t *testing.T) {
+	assert.Equal(t, "today", formatAge(2*time.Hour))
+	assert.Equal(t, "1 day ago", formatAge(30*time.Hour))
+	assert.Equal(t, "3 months ago", formatAge(95*24*time.Hour))
+	assert.Equal(t, "2 years ago", formatAge(800*24*time.Hour))
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// AdminAuth returns a Gin middleware that only lets through requests carrying
+// the configured admin token as "Authorization: Bearer <token>".
+// When no token is configured, admin endpoints are disabled.
+func AdminAuth(cfg *config.Config, log *logrus.Logger) gin.HandlerFunc {
+	token := cfg.Admin.Token
+	if strings.HasPrefix(token, "USE-") {
+		token = ""
+	}
+
+	return func(c *gin.Context) {
+		if token == "" {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "admin endpoints are disabled",
+			})
+			return
+		}
+
+		provided, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			log.Warnf("Unauthorized admin request from IP: %s", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "unauthorized",
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package middleware
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"time"
+
+	"alpha-hygiene-backend/internal/apikey"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// APIKeyHeader is the request header carrying the API key
+	APIKeyHeader = "X-API-Key"
+	// apiKeyContextKey and apiTierContextKey store the authenticated key in the Gin context
+	apiKeyContextKey  = "api_key"
+	apiTierContextKey = "api_tier"
+)
+
+// APIKeyAuth returns a Gin middleware that requires a valid, non-revoked key in the X-API-Key header.
+// The key and its tier are stored in the context for the handlers and quota middleware.
+func APIKeyAuth(manager *apikey.Manager, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, tier, err := manager.Authenticate(c.Request.Context(), c.GetHeader(APIKeyHeader))
+		if err != nil {
+			log.Errorf("API key lookup failed: %v", err)
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{
+				"error": "failed to verify API key",
+			})
+			return
+		}
+		if key == nil {
+			log.Warnf("Request with missing or invalid API key from IP: %s", c.ClientIP())
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "missing or invalid API key",
+			})
+			return
+		}
+
+		c.Set(apiKeyContextKey, key)
+		c.Set(apiTierContextKey, tier)
+		c.Next()
+	}
+}
+
+// ScanQuota returns a Gin middleware that charges one scan against the daily quota of the key.
+// It must run after APIKeyAuth; requests without a key are passed through.
+func ScanQuota(manager *apikey.Manager, log *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key, tier, ok := APIKeyFromContext(c)
+		if !ok {
+			c.Next()
+			return
+		}
+
+		quota, err := manager.ConsumeScan(c.Request.Context(), key, tier)
+		if err != nil {
+			// Usage accounting failure must not take the API down
+			log.Errorf("Failed to count usage for API key %s: %v", key.ID, err)
+			c.Next()
+			return
+		}
+
+		if quota.Limit > 0 {
+			c.Header("X-Quota-Limit", fmt.Sprintf("%d", quota.Limit))
+			c.Header("X-Quota-Remaining", fmt.Sprintf("%d", quota.Remaining))
+			c.Header("X-Quota-Reset", fmt.Sprintf("%d", quota.ResetAt.Unix()))
+		}
+		if !quota.Allowed {
+			log.Warnf("Daily quota exceeded for API key %s", key.ID)
+			c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(time.Until(quota.ResetAt).Seconds()))))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{
+				"error":   "Quota Exceeded",
+				"message": fmt.Sprintf("Daily limit of %d scans for tier %s is exhausted.", quota.Limit, tier.Name),
+			})
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// APIKeyFromContext returns the key and tier set by APIKeyAuth
+func APIKeyFromContext(c *gin.Context) (*apikey.Key, *apikey.Tier, bool) {
+	key, ok := c.Get(apiKeyContextKey)
+	if !ok {
+		return nil, nil, false
+	}
+	tier, ok := c.Get(apiTierContextKey)
+	if !ok {
+		return nil, nil, false
+	}
+	return key.(*apikey.Key), tier.(*apikey.Tier), true
+}
@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/apikey"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeyAuth_EnforcesKeyAndQuota(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.APIKeys.Tiers = map[string]config.APIKeyTier{"free": {ScansPerDay: 1}}
+	log := logrus.New()
+
+	store, err := apikey.NewFileStore(filepath.Join(t.TempDir(), "api_keys.yaml"), log.WithContext(context.Background()))
+	require.NoError(t, err)
+	manager := apikey.NewManagerWithStore(store, cfg, log.WithContext(context.Background()))
+	rawKey, _, err := manager.Issue(context.Background(), "wallet-app", "free")
+	require.NoError(t, err)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.GET("/", APIKeyAuth(manager, log), ScanQuota(manager, log), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	request := func(path, key string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if key != "" {
+			req.Header.Set(APIKeyHeader, key)
+		}
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, request("/", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, request("/", "wns_invalid").Code)
+
+	w := request("/", rawKey)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-Quota-Remaining"))
+
+	w = request("/", rawKey)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
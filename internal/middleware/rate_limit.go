@@ -1,7 +1,9 @@
 package middleware
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"net"
 	"net/http"
 	"sync"
@@ -38,8 +40,71 @@ func NewRateLimiter(cfg *config.Config, log *logrus.Logger) *RateLimiter {
 	}
 }
 
+// SetLimit updates the limit, e.g. after a configuration reload
+func (rl *RateLimiter) SetLimit(requests int, window time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.Requests = requests
+	rl.Window = window
+}
+
+// Decision is the outcome of a rate limit check for one request
+type Decision struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAt    time.Time
+	RetryAfter time.Duration
+}
+
+// Limiter decides whether a request from the given client is allowed
+type Limiter interface {
+	Allow(ctx context.Context, key string) (Decision, error)
+}
+
+// Allow implements a fixed window counter per client
+func (rl *RateLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	now := time.Now()
+	entry, exists := rl.clientCount[key]
+
+	// New client or expired window - start a new window
+	if !exists || now.After(entry.resetTime) {
+		entry.count = 0
+		entry.resetTime = now.Add(rl.Window)
+	}
+
+	// Window is active and the limit is reached
+	if entry.count >= rl.Requests {
+		return Decision{
+			Allowed:    false,
+			Limit:      rl.Requests,
+			Remaining:  0,
+			ResetAt:    entry.resetTime,
+			RetryAfter: entry.resetTime.Sub(now),
+		}, nil
+	}
+
+	entry.count++
+	rl.clientCount[key] = entry
+	return Decision{
+		Allowed:   true,
+		Limit:     rl.Requests,
+		Remaining: rl.Requests - entry.count,
+		ResetAt:   entry.resetTime,
+	}, nil
+}
+
 // RateLimitMiddleware returns a Gin middleware that implements rate limiting
 func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
+	return NewRateLimitMiddleware(rl, rl.log)
+}
+
+// NewRateLimitMiddleware returns a Gin middleware that enforces limits of the given limiter per client IP
+func NewRateLimitMiddleware(limiter Limiter, log *logrus.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		// Get client IP address
 		clientIP := c.ClientIP()
@@ -51,67 +116,35 @@ func (rl *RateLimiter) RateLimitMiddleware() gin.HandlerFunc {
 			}
 		}
 
-		rl.mu.Lock()
-		defer rl.mu.Unlock()
-
-		// Cleanup old entries if window has passed
-		now := time.Now()
-
-		// Check if client exists in map
-		entry, exists := rl.clientCount[clientIP]
-
-		// Client doesn't exist - create new entry
-		if !exists {
-			rl.clientCount[clientIP] = struct {
-				count     int
-				resetTime time.Time
-			}{
-				count:     1,
-				resetTime: now.Add(rl.Window),
-			}
-			rl.setHeaders(c, clientIP)
-			c.Next()
-			return
-		}
-
-		// Client exists, check if window has expired
-		if now.After(entry.resetTime) {
-			rl.clientCount[clientIP] = struct {
-				count     int
-				resetTime time.Time
-			}{
-				count:     1,
-				resetTime: now.Add(rl.Window),
-			}
-			rl.setHeaders(c, clientIP)
+		decision, err := limiter.Allow(c.Request.Context(), clientIP)
+		if err != nil {
+			// Limiter failure must not take the API down
+			log.Errorf("Rate limiter failed for IP %s: %v", clientIP, err)
 			c.Next()
 			return
 		}
 
-		// Client exists and window is active, check rate limit
-		if entry.count >= rl.Requests {
-			rl.log.Warnf("Rate limit exceeded for IP: %s", clientIP)
+		setHeaders(c, decision)
+		if !decision.Allowed {
+			log.Warnf("Rate limit exceeded for IP: %s", clientIP)
+			c.Header("Retry-After", fmt.Sprintf("%d", int(math.Ceil(decision.RetryAfter.Seconds()))))
 			c.JSON(http.StatusTooManyRequests, gin.H{
 				"error":   "Too Many Requests",
-				"message": fmt.Sprintf("Rate limit exceeded. Please try again in %0.0f seconds.", entry.resetTime.Sub(now).Seconds()),
+				"message": fmt.Sprintf("Rate limit exceeded. Please try again in %0.0f seconds.", decision.RetryAfter.Seconds()),
 			})
 			c.Abort()
 			return
 		}
 
-		// All checks passed - increment count
-		entry.count++
-		rl.clientCount[clientIP] = entry
-		rl.setHeaders(c, clientIP)
 		c.Next()
 	}
 }
 
 // setHeaders sets the rate limit response headers
-func (rl *RateLimiter) setHeaders(c *gin.Context, clientIP string) {
-	c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", rl.Requests))
-	c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", rl.Requests-rl.clientCount[clientIP].count))
-	c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", rl.clientCount[clientIP].resetTime.Unix()))
+func setHeaders(c *gin.Context, decision Decision) {
+	c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", decision.Limit))
+	c.Header("X-RateLimit-Remaining", fmt.Sprintf("%d", decision.Remaining))
+	c.Header("X-RateLimit-Reset", fmt.Sprintf("%d", decision.ResetAt.Unix()))
 }
 
 // IPToKey converts an IP address to a string key, handling IPv6 addresses
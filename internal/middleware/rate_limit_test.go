@@ -0,0 +1,111 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testRouter(handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(handler)
+	r.GET("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	return r
+}
+
+func doRequest(r *gin.Engine) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func testRateLimitConfig(addr string) *config.Config {
+	cfg := &config.Config{}
+	cfg.Redis.Addr = addr
+	cfg.App.RateLimit = config.RateLimitConfig{
+		Enabled:  true,
+		Requests: 3,
+		Window:   60,
+		Backend:  config.RateLimitBackendRedis,
+	}
+	return cfg
+}
+
+func TestRateLimiter_RejectsOverLimit(t *testing.T) {
+	cfg := testRateLimitConfig("")
+	r := testRouter(NewRateLimiter(cfg, logrus.New()).RateLimitMiddleware())
+
+	for i := 0; i < 3; i++ {
+		w := doRequest(r)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "3", w.Header().Get("X-RateLimit-Limit"))
+	}
+
+	w := doRequest(r)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "0", w.Header().Get("X-RateLimit-Remaining"))
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+}
+
+func TestRedisRateLimiter_SharesLimitBetweenInstances(t *testing.T) {
+	mr := miniredis.RunT(t)
+	mr.SetTime(time.Now())
+	cfg := testRateLimitConfig(mr.Addr())
+	log := logrus.New()
+
+	first := NewRedisRateLimiter(cfg, NewRateLimiter(cfg, log), log)
+	second := NewRedisRateLimiter(cfg, NewRateLimiter(cfg, log), log)
+	t.Cleanup(func() {
+		_ = first.Close()
+		_ = second.Close()
+	})
+	r1 := testRouter(first.RateLimitMiddleware())
+	r2 := testRouter(second.RateLimitMiddleware())
+
+	w := doRequest(r1)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-RateLimit-Remaining"))
+	require.Equal(t, http.StatusOK, doRequest(r2).Code)
+	require.Equal(t, http.StatusOK, doRequest(r1).Code)
+
+	w = doRequest(r2)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Equal(t, "20", w.Header().Get("Retry-After"))
+	assert.True(t, mr.Exists(redisRateLimitPrefix+"10.0.0.1"))
+
+	// GCRA frees one request every window/requests seconds
+	mr.SetTime(time.Now().Add(21 * time.Second))
+	assert.Equal(t, http.StatusOK, doRequest(r2).Code)
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(r1).Code)
+}
+
+func TestRedisRateLimiter_FallsBackToMemory(t *testing.T) {
+	mr := miniredis.RunT(t)
+	cfg := testRateLimitConfig(mr.Addr())
+	log := logrus.New()
+
+	limiter := NewRedisRateLimiter(cfg, NewRateLimiter(cfg, log), log)
+	t.Cleanup(func() { _ = limiter.Close() })
+	r := testRouter(limiter.RateLimitMiddleware())
+
+	mr.Close()
+
+	// The limit still applies without Redis
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusOK, doRequest(r).Code)
+	}
+	assert.Equal(t, http.StatusTooManyRequests, doRequest(r).Code)
+	assert.NotZero(t, limiter.degradedUntil.Load())
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-redis/redis/v8"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// redisRateLimitPrefix is the key prefix of rate limit state in Redis
+	redisRateLimitPrefix = "rate_limit:"
+	// redisRateLimitTimeout bounds a single limiter round trip to Redis
+	redisRateLimitTimeout = 200 * time.Millisecond
+	// redisRetryInterval is how long the limiter stays on the in-memory fallback after a Redis error
+	redisRetryInterval = 5 * time.Second
+)
+
+// gcraScript implements the Generic Cell Rate Algorithm atomically.
+// The key stores the theoretical arrival time (TAT) in milliseconds of Redis server time,
+// so all instances share one clock.
+// KEYS[1] - client key, ARGV[1] - emission interval (ms), ARGV[2] - period (ms).
+// Returns {allowed, remaining, retry_after_ms, reset_after_ms}.
+var gcraScript = redis.NewScript(`
+local interval = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if not tat or tat < now then
+	tat = now
+end
+
+local new_tat = tat + interval
+local allow_at = new_tat - period
+if now < allow_at then
+	return {0, 0, allow_at - now, tat - now}
+end
+
+redis.call('SET', KEYS[1], string.format('%d', new_tat), 'PX', new_tat - now)
+return {1, math.floor((now - allow_at) / interval), 0, new_tat - now}
+`)
+
+// RedisRateLimiter represents a rate limiter shared by all API instances through Redis.
+// It uses GCRA, which behaves like a sliding window without storing every request.
+// While Redis is unavailable, requests are limited by the in-memory fallback.
+type RedisRateLimiter struct {
+	client   *redis.Client
+	fallback *RateLimiter
+	// mu guards Requests and Window, which change on configuration reload
+	mu       sync.RWMutex
+	Requests int
+	Window   time.Duration
+	// degradedUntil is the unix nano time until which Redis is not retried
+	degradedUntil atomic.Int64
+	log           *logrus.Logger
+}
+
+// NewRedisRateLimiter creates a Redis-backed rate limiter with the given in-memory fallback
+func NewRedisRateLimiter(cfg *config.Config, fallback *RateLimiter, log *logrus.Logger) *RedisRateLimiter {
+	client := redis.NewClient(&redis.Options{
+		Addr:     cfg.Redis.Addr,
+		Password: cfg.Redis.Password,
+		DB:       cfg.Redis.DB,
+	})
+
+	return &RedisRateLimiter{
+		client:   client,
+		fallback: fallback,
+		Requests: cfg.App.RateLimit.Requests,
+		Window:   time.Duration(cfg.App.RateLimit.Window) * time.Second,
+		log:      log,
+	}
+}
+
+// Allow checks the request against the shared limit, falling back to memory when Redis fails
+func (rl *RedisRateLimiter) Allow(ctx context.Context, key string) (Decision, error) {
+	if time.Now().UnixNano() < rl.degradedUntil.Load() {
+		return rl.fallback.Allow(ctx, key)
+	}
+
+	decision, err := rl.allowRedis(ctx, key)
+	if err != nil {
+		if rl.degradedUntil.Swap(time.Now().Add(redisRetryInterval).UnixNano()) == 0 {
+			rl.log.Warnf("Redis rate limiter unavailable, falling back to in-memory limiter: %v", err)
+		}
+		return rl.fallback.Allow(ctx, key)
+	}
+
+	if rl.degradedUntil.Swap(0) != 0 {
+		rl.log.Info("Redis rate limiter recovered")
+	}
+	return decision, nil
+}
+
+// SetLimit updates the limit of the limiter and its fallback, e.g. after a configuration reload
+func (rl *RedisRateLimiter) SetLimit(requests int, window time.Duration) {
+	rl.mu.Lock()
+	rl.Requests = requests
+	rl.Window = window
+	rl.mu.Unlock()
+
+	rl.fallback.SetLimit(requests, window)
+}
+
+// allowRedis runs the GCRA script for the client key
+func (rl *RedisRateLimiter) allowRedis(ctx context.Context, key string) (Decision, error) {
+	rl.mu.RLock()
+	requests, window := rl.Requests, rl.Window
+	rl.mu.RUnlock()
+
+	if requests <= 0 || window <= 0 {
+		return Decision{Allowed: false, Limit: requests, ResetAt: time.Now().Add(window), RetryAfter: window}, nil
+	}
+
+	period := window.Milliseconds()
+	interval := period / int64(requests)
+	if interval < 1 {
+		interval = 1
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, redisRateLimitTimeout)
+	defer cancel()
+
+	res, err := gcraScript.Run(ctx, rl.client, []string{redisRateLimitPrefix + key}, interval, period).Int64Slice()
+	if err != nil {
+		return Decision{}, err
+	}
+	if len(res) != 4 {
+		return Decision{}, fmt.Errorf("unexpected rate limit script result: %v", res)
+	}
+
+	now := time.Now()
+	return Decision{
+		Allowed:    res[0] == 1,
+		Limit:      requests,
+		Remaining:  int(res[1]),
+		ResetAt:    now.Add(time.Duration(res[3]) * time.Millisecond),
+		RetryAfter: time.Duration(res[2]) * time.Millisecond,
+	}, nil
+}
+
+// RateLimitMiddleware returns a Gin middleware that implements rate limiting
+func (rl *RedisRateLimiter) RateLimitMiddleware() gin.HandlerFunc {
+	return NewRateLimitMiddleware(rl, rl.log)
+}
+
+// Close closes the Redis connection
+func (rl *RedisRateLimiter) Close() error {
+	return rl.client.Close()
+}
@@ -11,9 +11,17 @@ import (
 	"time"
 
 	"alpha-hygiene-backend/config"
-	"alpha-hygiene-backend/pkg/util"
+	"alpha-hygiene-backend/internal/cache"
+	"alpha-hygiene-backend/internal/registry"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// alchemyMetadataConcurrency - Количество параллельных запросов метаданных токенов
+	alchemyMetadataConcurrency = 8
 )
 
 // AlchemyClient - Клиент для Alchemy API
@@ -21,7 +29,11 @@ type AlchemyClient struct {
 	apiKey  string
 	baseURL string
 	client  *http.Client
-	log     *logrus.Entry
+	// metadataCache - Бессрочный кэш метаданных токенов (nil - кэш не подключен)
+	metadataCache *cache.Typed[TokenMetadata]
+	// registry - Реестр адресов; NFT доверенных контрактов не возвращаются (nil - реестр не подключен)
+	registry *registry.Registry
+	log      *logrus.Entry
 }
 
 // NewAlchemyClient - Создает новый клиент для Alchemy API
@@ -41,6 +53,32 @@ func NewAlchemyClient(cfg *config.Config, log *logrus.Entry) *AlchemyClient {
 	}
 }
 
+// RPCURL - JSON-RPC endpoint Alchemy ("" - API ключ не задан)
+func (c *AlchemyClient) RPCURL() string {
+	if c.apiKey == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s", c.baseURL, c.apiKey)
+}
+
+// UseCache - Подключает кэш метаданных токенов
+func (c *AlchemyClient) UseCache(store cache.Store) {
+	c.metadataCache = cache.NewTyped[TokenMetadata](store, cache.NamespaceTokenMetadata, cache.NoExpiration, c.log)
+}
+
+// UseRegistry - Подключает реестр адресов
+func (c *AlchemyClient) UseRegistry(reg *registry.Registry) {
+	c.registry = reg
+}
+
+// TokenMetadata - Метаданные ERC-20 токена (не меняются после деплоя)
+type TokenMetadata struct {
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals *int   `json:"decimals"`
+	Logo     string `json:"logo"`
+}
+
 // AlchemyTokenBalance - Структура для баланса токена из Alchemy API
 type AlchemyTokenBalance struct {
 	ContractAddress string `json:"contractAddress"`
@@ -187,9 +225,6 @@ func (c *AlchemyClient) GetERC20Tokens(ctx context.Context, address string) ([]*
 	// Преобразуем в нашу структуру TokenBalance
 	var result []*TokenBalance
 	for _, tb := range response.Result.TokenBalances {
-		// Для получения дополнительной информации (name, symbol, decimals) нужно сделать отдельный запрос
-		// Но для MVP мы можем использовать пустые значения
-
 		// Конвертируем баланс из hex строки в decimal строку
 		var balanceStr string
 		if tb.TokenBalance == "0x" {
@@ -214,9 +249,114 @@ func (c *AlchemyClient) GetERC20Tokens(ctx context.Context, address string) ([]*
 		})
 	}
 
+	c.fillTokenMetadata(ctx, result)
+
 	return result, nil
 }
 
+// fillTokenMetadata - Дополняет балансы именем, символом и decimals токена.
+// Ошибки не критичны: токен остается с пустыми именами и 18 decimals.
+func (c *AlchemyClient) fillTokenMetadata(ctx context.Context, tokens []*TokenBalance) {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(alchemyMetadataConcurrency)
+
+	for _, t := range tokens {
+		token := t
+		if token.Balance == "0" {
+			continue
+		}
+		g.Go(func() error {
+			metadata, err := c.GetTokenMetadata(gctx, token.ContractAddress)
+			if err != nil {
+				c.log.Warnf("Failed to get metadata for token %s: %v", token.ContractAddress, err)
+				return nil
+			}
+			token.TokenName = metadata.Name
+			token.TokenSymbol = metadata.Symbol
+			if metadata.Decimals != nil {
+				token.TokenDecimal = fmt.Sprintf("%d", *metadata.Decimals)
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+}
+
+// GetTokenMetadata - Получает метаданные токена через alchemy_getTokenMetadata (с бессрочным кэшем)
+func (c *AlchemyClient) GetTokenMetadata(ctx context.Context, contractAddress string) (*TokenMetadata, error) {
+	if cached, ok := c.metadataCache.Get(ctx, contractAddress); ok {
+		return &cached, nil
+	}
+
+	var metadata TokenMetadata
+	if err := c.rpc(ctx, "alchemy_getTokenMetadata", []interface{}{contractAddress}, &metadata); err != nil {
+		return nil, err
+	}
+
+	c.metadataCache.Set(ctx, contractAddress, metadata)
+	return &metadata, nil
+}
+
+// GetCode - Получает код аккаунта на последнем блоке
+func (c *AlchemyClient) GetCode(ctx context.Context, address string) ([]byte, error) {
+	var code string
+	if err := c.rpc(ctx, "eth_getCode", []interface{}{address, "latest"}, &code); err != nil {
+		return nil, err
+	}
+	return common.FromHex(code), nil
+}
+
+// rpc - Выполняет JSON-RPC запрос к Alchemy и декодирует поле result в out
+func (c *AlchemyClient) rpc(ctx context.Context, method string, params []interface{}, out interface{}) error {
+	urlStr := fmt.Sprintf("%s/%s", c.baseURL, c.apiKey)
+
+	reqData, err := json.Marshal(map[string]interface{}{
+		"id":      1,
+		"jsonrpc": "2.0",
+		"method":  method,
+		"params":  params,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, urlStr, bytes.NewBuffer(reqData))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("Alchemy API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return fmt.Errorf("Alchemy API error %d: %s", response.Error.Code, response.Error.Message)
+	}
+
+	return json.Unmarshal(response.Result, out)
+}
+
 // AlchemyNFT - Структура для NFT из Alchemy API
 type AlchemyNFT struct {
 	ContractAddress string `json:"contractAddress"`
@@ -281,7 +421,7 @@ func (c *AlchemyClient) GetNFTs(ctx context.Context, address string) ([]*Alchemy
 	var nfts []*AlchemyNFT
 	seen := make(map[string]bool)
 	for _, nft := range response.OwnedNfts {
-		if util.IsTrusted(nft.Contract.Address) {
+		if c.registry.IsAllowed(nft.Contract.Address) {
 			continue
 		}
 		// Проверяем, что у NFT есть контракт и токен ID
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"bytes"
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// delegationPrefix - Префикс кода EOA с делегированием EIP-7702 (0xef0100 || address)
+var delegationPrefix = []byte{0xef, 0x01, 0x00}
+
+// CodeProvider - Источник кода аккаунта (eth_getCode на последнем блоке)
+type CodeProvider interface {
+	GetCode(ctx context.Context, address string) ([]byte, error)
+}
+
+// ParseDelegation - Адрес делегата из кода аккаунта, если код - указатель делегирования EIP-7702
+func ParseDelegation(code []byte) (common.Address, bool) {
+	if len(code) != len(delegationPrefix)+common.AddressLength || !bytes.HasPrefix(code, delegationPrefix) {
+		return common.Address{}, false
+	}
+	return common.BytesToAddress(code[len(delegationPrefix):]), true
+}
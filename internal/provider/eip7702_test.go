@@ -0,0 +1,25 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDelegation(t *testing.T) {
+	delegate := common.HexToAddress("0x63c0c19a282a1b52b07dd5a65b58948a07dae32b")
+
+	target, ok := ParseDelegation(append([]byte{0xef, 0x01, 0x00}, delegate.Bytes()...))
+	assert.True(t, ok)
+	assert.Equal(t, delegate, target)
+
+	_, ok = ParseDelegation(nil)
+	assert.False(t, ok, "EOA without code")
+
+	_, ok = ParseDelegation(common.FromHex("0x6080604052348015600f57600080fd5b50"))
+	assert.False(t, ok, "regular contract")
+
+	_, ok = ParseDelegation(append([]byte{0xef, 0x01, 0x00}, delegate.Bytes()[:19]...))
+	assert.False(t, ok, "truncated designator")
+}
@@ -3,36 +3,85 @@ package provider
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
+	"io"
+	"math/big"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"alpha-hygiene-backend/config"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/sirupsen/logrus"
 )
 
-// EtherscanClient - Клиент для Etherscan API
+const (
+	// defaultEtherscanURL - Базовый URL Etherscan API
+	defaultEtherscanURL = "https://api.etherscan.io"
+	// defaultEtherscanChainID - Сеть по умолчанию для Etherscan V2 (Ethereum Mainnet)
+	defaultEtherscanChainID = 1
+	// defaultEtherscanPageSize - Размер страницы для списковых методов
+	defaultEtherscanPageSize = 1000
+	// defaultEtherscanMaxResults - Максимум записей, возвращаемых одним списковым методом
+	defaultEtherscanMaxResults = 10000
+	// etherscanResultWindow - Etherscan отдает не более page*offset = 10000 записей на один диапазон блоков
+	etherscanResultWindow = 10000
+	// etherscanCreationBatchSize - Максимум адресов в одном запросе getcontractcreation
+	etherscanCreationBatchSize = 5
+	// etherscanRateLimitRetries - Количество повторов при ответе "Max rate limit reached"
+	etherscanRateLimitRetries = 3
+)
+
+// ErrHistoryTruncated - История адреса длиннее etherscan.max_results (или окна Etherscan в одном блоке).
+// Списковые методы возвращают вместе с ней самые новые записи; по такой истории нельзя восстанавливать
+// состояние (балансы, allowances), но можно искать недавние события.
+var ErrHistoryTruncated = errors.New("history is truncated")
+
+// EtherscanClient - Клиент для Etherscan API (V2, мультичейн через chainid)
 type EtherscanClient struct {
-	apiKey  string
-	baseURL string
-	client  *http.Client
-	log     *logrus.Entry
+	apiKey     string
+	baseURL    string
+	chainID    int64
+	pageSize   int
+	maxResults int
+	// resultWindow - Предел page*offset на один диапазон блоков
+	resultWindow int
+	client       *http.Client
+	log          *logrus.Entry
 }
 
 // NewEtherscanClient - Создает новый клиент Etherscan
 func NewEtherscanClient(cfg *config.Config, log *logrus.Entry) *EtherscanClient {
-	baseURL := cfg.Etherscan.URL
+	baseURL := strings.TrimRight(cfg.Etherscan.URL, "/")
 	if baseURL == "" {
-		baseURL = "https://api.etherscan.io"
+		baseURL = defaultEtherscanURL
+	}
+	chainID := cfg.Etherscan.ChainID
+	if chainID <= 0 {
+		chainID = defaultEtherscanChainID
+	}
+	pageSize := cfg.Etherscan.PageSize
+	if pageSize <= 0 || pageSize > etherscanResultWindow {
+		pageSize = defaultEtherscanPageSize
+	}
+	maxResults := cfg.Etherscan.MaxResults
+	if maxResults <= 0 {
+		maxResults = defaultEtherscanMaxResults
 	}
 	logger := log.WithFields(logrus.Fields{"component": "etherscan"})
 	return &EtherscanClient{
-		apiKey:  cfg.Etherscan.ApiKey,
-		baseURL: baseURL,
+		apiKey:       cfg.Etherscan.ApiKey,
+		baseURL:      baseURL,
+		chainID:      chainID,
+		pageSize:     pageSize,
+		maxResults:   maxResults,
+		resultWindow: etherscanResultWindow,
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
@@ -40,7 +89,56 @@ func NewEtherscanClient(cfg *config.Config, log *logrus.Entry) *EtherscanClient
 	}
 }
 
-// TokenTransaction - Структура для транзакции с токеном
+// EtherscanError - Ошибка, возвращенная Etherscan в конверте status/message/result
+type EtherscanError struct {
+	Message string
+	Result  string
+}
+
+func (e *EtherscanError) Error() string {
+	if e.Result != "" {
+		return fmt.Sprintf("Etherscan API error: %s: %s", e.Message, e.Result)
+	}
+	return fmt.Sprintf("Etherscan API error: %s", e.Message)
+}
+
+// isRateLimit - Ошибка превышения лимита запросов
+func (e *EtherscanError) isRateLimit() bool {
+	return strings.Contains(strings.ToLower(e.Result), "rate limit")
+}
+
+// etherscanEnvelope - Общая обертка ответа Etherscan API
+type etherscanEnvelope struct {
+	Status  string          `json:"status"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// Transaction - Обычная транзакция (txlist)
+type Transaction struct {
+	BlockNumber       string `json:"blockNumber"`
+	TimeStamp         string `json:"timeStamp"`
+	Hash              string `json:"hash"`
+	Nonce             string `json:"nonce"`
+	BlockHash         string `json:"blockHash"`
+	TransactionIndex  string `json:"transactionIndex"`
+	From              string `json:"from"`
+	To                string `json:"to"`
+	Value             string `json:"value"`
+	Gas               string `json:"gas"`
+	GasPrice          string `json:"gasPrice"`
+	IsError           string `json:"isError"`
+	TxReceiptStatus   string `json:"txreceipt_status"`
+	Input             string `json:"input"`
+	ContractAddress   string `json:"contractAddress"`
+	CumulativeGasUsed string `json:"cumulativeGasUsed"`
+	GasUsed           string `json:"gasUsed"`
+	Confirmations     string `json:"confirmations"`
+	MethodID          string `json:"methodId"`
+	FunctionName      string `json:"functionName"`
+}
+
+// TokenTransaction - Структура для транзакции с токеном (tokentx)
 type TokenTransaction struct {
 	BlockNumber       string `json:"blockNumber"`
 	TimeStamp         string `json:"timeStamp"`
@@ -63,6 +161,123 @@ type TokenTransaction struct {
 	Confirmations     string `json:"confirmations"`
 }
 
+// NFTTransfer - Перевод ERC-721 токена (tokennfttx)
+type NFTTransfer struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	Nonce           string `json:"nonce"`
+	BlockHash       string `json:"blockHash"`
+	From            string `json:"from"`
+	ContractAddress string `json:"contractAddress"`
+	To              string `json:"to"`
+	TokenID         string `json:"tokenID"`
+	TokenName       string `json:"tokenName"`
+	TokenSymbol     string `json:"tokenSymbol"`
+	TokenDecimal    string `json:"tokenDecimal"`
+	Confirmations   string `json:"confirmations"`
+}
+
+// InternalTransaction - Внутренняя транзакция (txlistinternal)
+type InternalTransaction struct {
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timeStamp"`
+	Hash            string `json:"hash"`
+	From            string `json:"from"`
+	To              string `json:"to"`
+	Value           string `json:"value"`
+	ContractAddress string `json:"contractAddress"`
+	Input           string `json:"input"`
+	Type            string `json:"type"`
+	Gas             string `json:"gas"`
+	GasUsed         string `json:"gasUsed"`
+	TraceID         string `json:"traceId"`
+	IsError         string `json:"isError"`
+	ErrCode         string `json:"errCode"`
+}
+
+// ContractSource - Метаданные исходного кода контракта (getsourcecode)
+type ContractSource struct {
+	SourceCode           string `json:"SourceCode"`
+	ABI                  string `json:"ABI"`
+	ContractName         string `json:"ContractName"`
+	CompilerVersion      string `json:"CompilerVersion"`
+	OptimizationUsed     string `json:"OptimizationUsed"`
+	Runs                 string `json:"Runs"`
+	ConstructorArguments string `json:"ConstructorArguments"`
+	EVMVersion           string `json:"EVMVersion"`
+	Library              string `json:"Library"`
+	LicenseType          string `json:"LicenseType"`
+	Proxy                string `json:"Proxy"`
+	Implementation       string `json:"Implementation"`
+	SwarmSource          string `json:"SwarmSource"`
+}
+
+// IsVerified - Опубликован ли исходный код контракта
+func (s *ContractSource) IsVerified() bool {
+	return s.SourceCode != "" && s.ABI != "Contract source code not verified"
+}
+
+// IsProxy - Является ли контракт прокси
+func (s *ContractSource) IsProxy() bool {
+	return s.Proxy == "1"
+}
+
+// ContractCreation - Создатель и транзакция создания контракта (getcontractcreation)
+type ContractCreation struct {
+	ContractAddress string `json:"contractAddress"`
+	ContractCreator string `json:"contractCreator"`
+	TxHash          string `json:"txHash"`
+	BlockNumber     string `json:"blockNumber"`
+	TimeStamp       string `json:"timestamp"`
+}
+
+// CreatedAt - Время создания контракта (нулевое, если Etherscan его не вернул)
+func (c *ContractCreation) CreatedAt() time.Time {
+	ts, err := strconv.ParseInt(c.TimeStamp, 10, 64)
+	if err != nil || ts <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(ts, 0).UTC()
+}
+
+// EventLog - Событие из ответа getLogs (числовые поля в hex)
+type EventLog struct {
+	Address         string   `json:"address"`
+	Topics          []string `json:"topics"`
+	Data            string   `json:"data"`
+	BlockNumber     string   `json:"blockNumber"`
+	TimeStamp       string   `json:"timeStamp"`
+	LogIndex        string   `json:"logIndex"`
+	TransactionHash string   `json:"transactionHash"`
+}
+
+// Block - Номер блока события
+func (l *EventLog) Block() uint64 {
+	return parseHexUint(l.BlockNumber)
+}
+
+// Time - Timestamp блока события
+func (l *EventLog) Time() int64 {
+	return int64(parseHexUint(l.TimeStamp))
+}
+
+// ToLog - Событие в формате go-ethereum
+func (l *EventLog) ToLog() types.Log {
+	topics := make([]common.Hash, len(l.Topics))
+	for i, topic := range l.Topics {
+		topics[i] = common.HexToHash(topic)
+	}
+	return types.Log{
+		Address:     common.HexToAddress(l.Address),
+		Topics:      topics,
+		Data:        common.FromHex(l.Data),
+		BlockNumber: l.Block(),
+		TxHash:      common.HexToHash(l.TransactionHash),
+		Index:       uint(parseHexUint(l.LogIndex)),
+	}
+}
+
 // TokenBalance - Структура для баланса токена
 type TokenBalance struct {
 	Account         string `json:"account"`
@@ -74,12 +289,242 @@ type TokenBalance struct {
 	Balance         string `json:"balance"`
 }
 
-// GetERC20Tokens - Получает список ERC20 токенов для адреса
+// GetTransactions - Получает обычные транзакции адреса в диапазоне блоков (endBlock <= 0 - до последнего блока)
+func (c *EtherscanClient) GetTransactions(ctx context.Context, address string, startBlock, endBlock int64) ([]Transaction, error) {
+	return fetchPaged(ctx, c, "txlist", address, startBlock, endBlock,
+		func(tx Transaction) string { return tx.BlockNumber },
+		func(tx Transaction) string { return tx.Hash },
+	)
+}
+
+// GetTokenTransfers - Получает ERC-20 переводы адреса в диапазоне блоков
+func (c *EtherscanClient) GetTokenTransfers(ctx context.Context, address string, startBlock, endBlock int64) ([]TokenTransaction, error) {
+	return fetchPaged(ctx, c, "tokentx", address, startBlock, endBlock,
+		func(tx TokenTransaction) string { return tx.BlockNumber },
+		func(tx TokenTransaction) string {
+			return strings.Join([]string{tx.Hash, tx.ContractAddress, tx.From, tx.To, tx.Value}, ":")
+		},
+	)
+}
+
+// GetNFTTransfers - Получает ERC-721 переводы адреса в диапазоне блоков
+func (c *EtherscanClient) GetNFTTransfers(ctx context.Context, address string, startBlock, endBlock int64) ([]NFTTransfer, error) {
+	return fetchPaged(ctx, c, "tokennfttx", address, startBlock, endBlock,
+		func(tx NFTTransfer) string { return tx.BlockNumber },
+		func(tx NFTTransfer) string {
+			return strings.Join([]string{tx.Hash, tx.ContractAddress, tx.TokenID, tx.From, tx.To}, ":")
+		},
+	)
+}
+
+// GetInternalTransactions - Получает внутренние транзакции адреса в диапазоне блоков
+func (c *EtherscanClient) GetInternalTransactions(ctx context.Context, address string, startBlock, endBlock int64) ([]InternalTransaction, error) {
+	return fetchPaged(ctx, c, "txlistinternal", address, startBlock, endBlock,
+		func(tx InternalTransaction) string { return tx.BlockNumber },
+		func(tx InternalTransaction) string { return tx.Hash + ":" + tx.TraceID },
+	)
+}
+
+// GetRecentTransactions - Получает не более limit последних транзакций адреса (одна страница, новые первыми)
+func (c *EtherscanClient) GetRecentTransactions(ctx context.Context, address string, limit int) ([]Transaction, error) {
+	return fetchRecent[Transaction](ctx, c, "txlist", address, limit)
+}
+
+// GetRecentInternalTransactions - Получает не более limit последних внутренних транзакций адреса
+func (c *EtherscanClient) GetRecentInternalTransactions(ctx context.Context, address string, limit int) ([]InternalTransaction, error) {
+	return fetchRecent[InternalTransaction](ctx, c, "txlistinternal", address, limit)
+}
+
+// GetContractSource - Получает исходный код и метаданные контракта
+func (c *EtherscanClient) GetContractSource(ctx context.Context, address string) (*ContractSource, error) {
+	params := url.Values{}
+	params.Set("module", "contract")
+	params.Set("action", "getsourcecode")
+	params.Set("address", address)
+
+	var result []ContractSource
+	if err := c.call(ctx, params, &result); err != nil {
+		return nil, err
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("Etherscan returned no source data for %s", address)
+	}
+
+	return &result[0], nil
+}
+
+// GetContractCreations - Получает данные о создании контрактов по адресу в нижнем регистре.
+// Адресов, которые не являются контрактами, в результате нет.
+func (c *EtherscanClient) GetContractCreations(ctx context.Context, addresses []string) (map[string]*ContractCreation, error) {
+	creations := make(map[string]*ContractCreation, len(addresses))
+	for _, batch := range chunkStrings(uniqueLower(addresses), etherscanCreationBatchSize) {
+		params := url.Values{}
+		params.Set("module", "contract")
+		params.Set("action", "getcontractcreation")
+		params.Set("contractaddresses", strings.Join(batch, ","))
+
+		var result []ContractCreation
+		if err := c.call(ctx, params, &result); err != nil {
+			return nil, err
+		}
+		for i := range result {
+			creations[strings.ToLower(result[i].ContractAddress)] = &result[i]
+		}
+	}
+
+	return creations, nil
+}
+
+// GetLogs - Получает события контракта начиная с блока fromBlock. topics[i] - фильтр по i-му топику
+// (пустая строка - любой), условия объединяются через AND. Как и у списковых методов, выдача ограничена
+// окном в 10000 записей, поэтому при его исчерпании fromBlock сдвигается на блок последнего события.
+// Если событий больше etherscan.max_results, возвращается ErrHistoryTruncated.
+func (c *EtherscanClient) GetLogs(ctx context.Context, address string, fromBlock int64, topics ...string) ([]EventLog, error) {
+	var all []EventLog
+	seen := make(map[string]struct{})
+	page := 1
+
+	for {
+		params := url.Values{}
+		params.Set("module", "logs")
+		params.Set("action", "getLogs")
+		params.Set("address", address)
+		params.Set("fromBlock", strconv.FormatInt(fromBlock, 10))
+		params.Set("toBlock", "latest")
+		prev := -1
+		for i, topic := range topics {
+			if topic == "" {
+				continue
+			}
+			params.Set(fmt.Sprintf("topic%d", i), topic)
+			if prev >= 0 {
+				params.Set(fmt.Sprintf("topic%d_%d_opr", prev, i), "and")
+			}
+			prev = i
+		}
+		params.Set("page", strconv.Itoa(page))
+		params.Set("offset", strconv.Itoa(c.pageSize))
+
+		var items []EventLog
+		if err := c.call(ctx, params, &items); err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			key := item.TransactionHash + ":" + item.LogIndex
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			all = append(all, item)
+		}
+
+		if len(all) > c.maxResults {
+			return nil, fmt.Errorf("etherscan getLogs: more than %d events: %w", c.maxResults, ErrHistoryTruncated)
+		}
+		if len(items) < c.pageSize {
+			return all, nil
+		}
+
+		if page*c.pageSize < c.resultWindow {
+			page++
+			continue
+		}
+
+		// Окно исчерпано - продолжаем с блока последнего события
+		lastBlock := int64(items[len(items)-1].Block())
+		if lastBlock <= fromBlock {
+			return nil, fmt.Errorf("etherscan getLogs: more than %d events in block %d: %w", c.resultWindow, lastBlock, ErrHistoryTruncated)
+		}
+		fromBlock = lastBlock
+		page = 1
+	}
+}
+
+// GetPermit2Allowances - Восстанавливает allowance владельца внутри Permit2 по событиям Approval, Permit и Lockdown.
+// Остаток не сверяется с контрактом: списания через transferFrom событий Permit2 не порождают,
+// поэтому Amount - верхняя оценка (точные значения дает источник node).
+func (c *EtherscanClient) GetPermit2Allowances(ctx context.Context, owner string) ([]Permit2Allowance, error) {
+	ownerTopic := common.BytesToHash(common.HexToAddress(owner).Bytes()).Hex()
+
+	var logs []types.Log
+	blockTimes := make(map[uint64]int64)
+	for _, topic := range []common.Hash{permit2ApprovalTopic, permit2PermitTopic, permit2LockdownTopic} {
+		events, err := c.GetLogs(ctx, Permit2Address, 0, topic.Hex(), ownerTopic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Permit2 logs: %w", err)
+		}
+		for i := range events {
+			logs = append(logs, events[i].ToLog())
+			blockTimes[events[i].Block()] = events[i].Time()
+		}
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	return reconstructPermit2Allowances(logs, func(number uint64) int64 { return blockTimes[number] }), nil
+}
+
+// GetERC20Tokens - Получает список ERC20 токенов для адреса.
+// Балансы восстанавливаются по истории tokentx (входящие минус исходящие переводы),
+// поэтому для rebasing токенов и токенов с комиссией за перевод они приблизительны.
+// По обрезанной истории балансы неверны, поэтому ErrHistoryTruncated возвращается как ошибка.
 func (c *EtherscanClient) GetERC20Tokens(ctx context.Context, address string) ([]*TokenBalance, error) {
-	// NOTE: Этот метод использует неправильный endpoint!
-	// Для получения токенов на кошельке нужно использовать Moralis API
-	c.log.Warnf("Etherscan API does not support getting all ERC20 tokens for address. Use Moralis API instead.")
-	return nil, fmt.Errorf("Etherscan API does not support getting all ERC20 tokens for address")
+	transfers, err := c.GetTokenTransfers(ctx, address, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	owner := strings.ToLower(address)
+	balances := make(map[string]*big.Int)
+	tokens := make(map[string]*TokenBalance)
+	var order []string
+
+	for _, tx := range transfers {
+		contract := strings.ToLower(tx.ContractAddress)
+		value, ok := new(big.Int).SetString(tx.Value, 10)
+		if !ok {
+			c.log.Warnf("Failed to parse transfer value %q in tx %s", tx.Value, tx.Hash)
+			continue
+		}
+
+		if _, exists := tokens[contract]; !exists {
+			tokens[contract] = &TokenBalance{
+				Account:         address,
+				ContractAddress: contract,
+				TokenName:       tx.TokenName,
+				TokenSymbol:     tx.TokenSymbol,
+				TokenDecimal:    tx.TokenDecimal,
+			}
+			balances[contract] = new(big.Int)
+			order = append(order, contract)
+		}
+
+		if strings.ToLower(tx.To) == owner {
+			balances[contract].Add(balances[contract], value)
+		}
+		if strings.ToLower(tx.From) == owner {
+			balances[contract].Sub(balances[contract], value)
+		}
+	}
+
+	var result []*TokenBalance
+	for _, contract := range order {
+		if balances[contract].Sign() <= 0 {
+			continue
+		}
+		token := tokens[contract]
+		token.Balance = balances[contract].String()
+		result = append(result, token)
+	}
+
+	c.log.Debugf("Found %d ERC20 tokens with positive balance from %d transfers", len(result), len(transfers))
+	return result, nil
 }
 
 // GetETHBalance - Получает баланс ETH для адреса
@@ -89,70 +534,177 @@ func (c *EtherscanClient) GetETHBalance(ctx context.Context, address string) (fl
 	params.Set("action", "balance")
 	params.Set("address", address)
 	params.Set("tag", "latest")
-	params.Set("apikey", c.apiKey)
 
-	urlStr := fmt.Sprintf("%s/api?%s", c.baseURL, params.Encode())
-
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
-	if err != nil {
+	var result string
+	if err := c.call(ctx, params, &result); err != nil {
 		return 0, err
 	}
 
-	resp, err := c.client.Do(req)
-	if err != nil {
-		c.log.Errorf("Etherscan API request failed: %v", err)
-		return 0, err
+	wei, ok := new(big.Int).SetString(result, 10)
+	if !ok {
+		c.log.Errorf("Failed to parse balance: %s", result)
+		return 0, fmt.Errorf("failed to parse balance: %s", result)
 	}
-	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		c.log.Errorf("Failed to read response body: %v", err)
-		return 0, err
-	}
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return eth, nil
+}
 
-	var result struct {
-		Status  string `json:"status"`
-		Message string `json:"message"`
-		Result  string `json:"result"`
+// parseHexUint - Разбирает hex число из ответа getLogs ("0x" - ноль)
+func parseHexUint(value string) uint64 {
+	value = strings.TrimPrefix(value, "0x")
+	if value == "" {
+		return 0
 	}
+	n, _ := strconv.ParseUint(value, 16, 64)
+	return n
+}
 
-	err = json.Unmarshal(body, &result)
-	if err != nil {
-		c.log.Errorf("Failed to unmarshal response: %v", err)
-		return 0, err
+// fetchPaged - Постранично выгружает списковый метод модуля account от новых записей к старым
+// и возвращает их в порядке блоков. Etherscan ограничивает выдачу окном в 10000 записей, поэтому
+// при его исчерпании endblock сдвигается на блок последней записи, а дубликаты на границе отбрасываются по ключу.
+// Если записей больше etherscan.max_results, возвращаются самые новые вместе с ErrHistoryTruncated.
+func fetchPaged[T any](ctx context.Context, c *EtherscanClient, action, address string, startBlock, endBlock int64, blockOf, keyOf func(T) string) ([]T, error) {
+	if endBlock <= 0 {
+		endBlock = 99999999
 	}
 
-	if result.Status != "1" {
-		c.log.Errorf("Etherscan API error: %s", result.Message)
-		return 0, fmt.Errorf("Etherscan API error: %s", result.Message)
-	}
+	var all []T
+	seen := make(map[string]struct{})
+	page := 1
 
-	wei, err := strconv.ParseUint(result.Result, 10, 64)
-	if err != nil {
-		c.log.Errorf("Failed to parse balance: %v", err)
-		return 0, err
+	for {
+		params := url.Values{}
+		params.Set("module", "account")
+		params.Set("action", action)
+		params.Set("address", address)
+		params.Set("startblock", strconv.FormatInt(startBlock, 10))
+		params.Set("endblock", strconv.FormatInt(endBlock, 10))
+		params.Set("page", strconv.Itoa(page))
+		params.Set("offset", strconv.Itoa(c.pageSize))
+		params.Set("sort", "desc")
+
+		var items []T
+		if err := c.call(ctx, params, &items); err != nil {
+			return nil, err
+		}
+
+		for _, item := range items {
+			key := keyOf(item)
+			if _, dup := seen[key]; dup {
+				continue
+			}
+			seen[key] = struct{}{}
+			all = append(all, item)
+		}
+
+		if len(all) > c.maxResults {
+			return oldestFirst(all[:c.maxResults]), fmt.Errorf("etherscan %s: kept the newest %d records: %w", action, c.maxResults, ErrHistoryTruncated)
+		}
+		if len(items) < c.pageSize {
+			return oldestFirst(all), nil
+		}
+
+		if page*c.pageSize < c.resultWindow {
+			page++
+			continue
+		}
+
+		// Окно исчерпано - продолжаем с блока последней (самой старой) записи
+		lastBlock, err := strconv.ParseInt(blockOf(items[len(items)-1]), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse block number: %w", err)
+		}
+		if lastBlock >= endBlock {
+			return oldestFirst(all), fmt.Errorf("etherscan %s: more than %d records in block %d: %w", action, c.resultWindow, lastBlock, ErrHistoryTruncated)
+		}
+		endBlock = lastBlock
+		page = 1
 	}
+}
 
-	eth := float64(wei) / 1e18
-	return eth, nil
+// oldestFirst - Разворачивает записи, выгруженные от новых к старым
+func oldestFirst[T any](items []T) []T {
+	for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+		items[i], items[j] = items[j], items[i]
+	}
+	return items
 }
 
-// GetInternalTransactions - Получает внутренние транзакции для адреса
-func (c *EtherscanClient) GetInternalTransactions(ctx context.Context, address string, startBlock, endBlock int) ([]map[string]interface{}, error) {
+// fetchRecent - Одна страница спискового метода модуля account с самыми новыми записями.
+// limit ограничивается размером страницы клиента.
+func fetchRecent[T any](ctx context.Context, c *EtherscanClient, action, address string, limit int) ([]T, error) {
+	if limit <= 0 || limit > c.pageSize {
+		limit = c.pageSize
+	}
+
 	params := url.Values{}
 	params.Set("module", "account")
-	params.Set("action", "txlistinternal")
+	params.Set("action", action)
 	params.Set("address", address)
-	params.Set("startblock", strconv.Itoa(startBlock))
-	params.Set("endblock", strconv.Itoa(endBlock))
+	params.Set("startblock", "0")
+	params.Set("endblock", "99999999")
 	params.Set("page", "1")
-	params.Set("offset", "100")
-	params.Set("sort", "asc")
+	params.Set("offset", strconv.Itoa(limit))
+	params.Set("sort", "desc")
+
+	var items []T
+	if err := c.call(ctx, params, &items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// call - Выполняет запрос к Etherscan V2 API и декодирует поле result в out.
+// Ответы "No transactions found" считаются пустым результатом, при превышении лимита запрос повторяется.
+func (c *EtherscanClient) call(ctx context.Context, params url.Values, out interface{}) error {
+	params.Set("chainid", strconv.FormatInt(c.chainID, 10))
 	params.Set("apikey", c.apiKey)
+	urlStr := fmt.Sprintf("%s/v2/api?%s", c.baseURL, params.Encode())
+
+	for attempt := 0; ; attempt++ {
+		envelope, err := c.do(ctx, urlStr)
+		if err != nil {
+			return err
+		}
 
-	urlStr := fmt.Sprintf("%s/api?%s", c.baseURL, params.Encode())
-	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
+		if envelope.Status == "1" {
+			if err := json.Unmarshal(envelope.Result, out); err != nil {
+				c.log.Errorf("Failed to unmarshal response: %v", err)
+				return err
+			}
+			return nil
+		}
+
+		// Пустой результат приходит со status=0 и пустым массивом
+		if strings.HasPrefix(envelope.Message, "No transactions found") ||
+			strings.HasPrefix(envelope.Message, "No records found") ||
+			strings.HasPrefix(envelope.Message, "No data found") ||
+			string(envelope.Result) == "[]" {
+			return nil
+		}
+
+		apiErr := &EtherscanError{Message: envelope.Message}
+		_ = json.Unmarshal(envelope.Result, &apiErr.Result)
+
+		if apiErr.isRateLimit() && attempt < etherscanRateLimitRetries {
+			c.log.Warnf("Etherscan rate limit reached, retrying (attempt %d)", attempt+1)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt+1) * time.Second):
+			}
+			continue
+		}
+
+		c.log.Errorf("%v", apiErr)
+		return apiErr
+	}
+}
+
+// do - Выполняет HTTP запрос и разбирает конверт ответа
+func (c *EtherscanClient) do(ctx context.Context, urlStr string) (*etherscanEnvelope, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -164,28 +716,21 @@ func (c *EtherscanClient) GetInternalTransactions(ctx context.Context, address s
 	}
 	defer resp.Body.Close()
 
-	body, err := ioutil.ReadAll(resp.Body)
+	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		c.log.Errorf("Failed to read response body: %v", err)
 		return nil, err
 	}
 
-	var result struct {
-		Status  string                   `json:"status"`
-		Message string                   `json:"message"`
-		Result  []map[string]interface{} `json:"result"`
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Etherscan API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	err = json.Unmarshal(body, &result)
-	if err != nil {
+	var envelope etherscanEnvelope
+	if err := json.Unmarshal(body, &envelope); err != nil {
 		c.log.Errorf("Failed to unmarshal response: %v", err)
 		return nil, err
 	}
 
-	if result.Status != "1" {
-		c.log.Errorf("Etherscan API error: %s", result.Message)
-		return nil, fmt.Errorf("Etherscan API error: %s", result.Message)
-	}
-
-	return result.Result, nil
+	return &envelope, nil
 }
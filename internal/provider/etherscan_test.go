@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEtherscanClient(t *testing.T, handler http.HandlerFunc) *EtherscanClient {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Etherscan.URL = srv.URL
+	cfg.Etherscan.ApiKey = "test-key"
+	cfg.Etherscan.ChainID = 8453
+
+	return NewEtherscanClient(cfg, log.WithContext(context.Background()))
+}
+
+func writeEtherscan(w http.ResponseWriter, status, message string, result interface{}) {
+	data, _ := json.Marshal(result)
+	_ = json.NewEncoder(w).Encode(etherscanEnvelope{Status: status, Message: message, Result: data})
+}
+
+// newTxlistClient - Клиент с сервером txlist, который отдает транзакции в блоках blocks
+// от новых к старым с учетом endblock и страниц
+func newTxlistClient(t *testing.T, blocks []int64, requests *[]string) *EtherscanClient {
+	return newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "/v2/api", r.URL.Path)
+		assert.Equal(t, "8453", q.Get("chainid"))
+		assert.Equal(t, "txlist", q.Get("action"))
+		assert.Equal(t, "desc", q.Get("sort"))
+		*requests = append(*requests, q.Get("endblock")+"/"+q.Get("page"))
+
+		end, _ := strconv.ParseInt(q.Get("endblock"), 10, 64)
+		page, _ := strconv.Atoi(q.Get("page"))
+		offset, _ := strconv.Atoi(q.Get("offset"))
+
+		var matching []Transaction
+		for i := len(blocks) - 1; i >= 0; i-- {
+			if blocks[i] <= end {
+				matching = append(matching, Transaction{Hash: fmt.Sprintf("0x%02d", i), BlockNumber: strconv.FormatInt(blocks[i], 10)})
+			}
+		}
+		from := (page - 1) * offset
+		if from >= len(matching) {
+			writeEtherscan(w, "0", "No transactions found", []Transaction{})
+			return
+		}
+		to := min(from+offset, len(matching))
+		writeEtherscan(w, "1", "OK", matching[from:to])
+	})
+}
+
+func TestEtherscanClient_GetTransactionsPagination(t *testing.T) {
+	// 7 транзакций: две в блоке 1, остальные по одной в блоках 2..6
+	blocks := []int64{1, 1, 2, 3, 4, 5, 6}
+	var requests []string
+
+	client := newTxlistClient(t, blocks, &requests)
+	client.pageSize = 2
+	client.resultWindow = 4
+
+	txs, err := client.GetTransactions(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc", 0, 0)
+	require.NoError(t, err)
+
+	require.Len(t, txs, len(blocks))
+	for i, tx := range txs {
+		assert.Equal(t, fmt.Sprintf("0x%02d", i), tx.Hash)
+	}
+	// После исчерпания окна (2 страницы по 2) запросы продолжаются с блока последней (самой старой) записи
+	assert.Equal(t, []string{"99999999/1", "99999999/2", "3/1", "3/2", "1/1", "1/2"}, requests)
+}
+
+func TestEtherscanClient_TruncatedHistoryKeepsNewestRecords(t *testing.T) {
+	blocks := []int64{1, 2, 3, 4, 5, 6}
+	var requests []string
+
+	client := newTxlistClient(t, blocks, &requests)
+	client.pageSize = 2
+	client.maxResults = 3
+
+	txs, err := client.GetTransactions(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc", 0, 0)
+	require.ErrorIs(t, err, ErrHistoryTruncated)
+	require.Len(t, txs, 3)
+	assert.Equal(t, []string{"0x03", "0x04", "0x05"}, []string{txs[0].Hash, txs[1].Hash, txs[2].Hash})
+}
+
+func TestEtherscanClient_ErrorEnvelope(t *testing.T) {
+	client := newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEtherscan(w, "0", "NOTOK", "Invalid API Key")
+	})
+
+	_, err := client.GetInternalTransactions(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc", 0, 0)
+	require.Error(t, err)
+
+	var apiErr *EtherscanError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "Invalid API Key", apiErr.Result)
+}
+
+func TestEtherscanClient_GetERC20Tokens(t *testing.T) {
+	owner := "0x742d35cc6634c0532925a3b88650d7241eff5cbc"
+	client := newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeEtherscan(w, "1", "OK", []TokenTransaction{
+			{Hash: "0x1", BlockNumber: "1", ContractAddress: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", From: "0xdead", To: owner, Value: "5000000", TokenSymbol: "USDC", TokenDecimal: "6"},
+			{Hash: "0x2", BlockNumber: "2", ContractAddress: "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", From: owner, To: "0xbeef", Value: "2000000", TokenSymbol: "USDC", TokenDecimal: "6"},
+			{Hash: "0x3", BlockNumber: "3", ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", From: "0xdead", To: owner, Value: "10", TokenSymbol: "USDT", TokenDecimal: "6"},
+			{Hash: "0x4", BlockNumber: "4", ContractAddress: "0xdac17f958d2ee523a2206206994597c13d831ec7", From: owner, To: "0xbeef", Value: "10", TokenSymbol: "USDT", TokenDecimal: "6"},
+		})
+	})
+
+	tokens, err := client.GetERC20Tokens(context.Background(), owner)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+	assert.Equal(t, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", tokens[0].ContractAddress)
+	assert.Equal(t, "3000000", tokens[0].Balance)
+
+	// По обрезанной истории балансы не восстанавливаются
+	client.maxResults = 3
+	tokens, err = client.GetERC20Tokens(context.Background(), owner)
+	assert.ErrorIs(t, err, ErrHistoryTruncated)
+	assert.Nil(t, tokens)
+}
+
+func TestEtherscanClient_GetContractSource(t *testing.T) {
+	client := newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "getsourcecode", r.URL.Query().Get("action"))
+		writeEtherscan(w, "1", "OK", []ContractSource{{
+			SourceCode:   "contract SwapRouter02 {}",
+			ABI:          "[]",
+			ContractName: "SwapRouter02",
+			Proxy:        "0",
+		}})
+	})
+
+	src, err := client.GetContractSource(context.Background(), "0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45")
+	require.NoError(t, err)
+	assert.Equal(t, "SwapRouter02", src.ContractName)
+	assert.True(t, src.IsVerified())
+	assert.False(t, src.IsProxy())
+}
@@ -1,41 +1,120 @@
 package provider
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
 
 	"github.com/sirupsen/logrus"
+	"golang.org/x/sync/errgroup"
+)
+
+const (
+	// defaultGoPlusURL - Базовый URL GoPlus Security API
+	defaultGoPlusURL = "https://api.gopluslabs.io"
+	// goPlusTokenRefreshMargin - Запас времени до истечения access token, после которого он обновляется
+	goPlusTokenRefreshMargin = 60 * time.Second
+	// goPlusCodeOK - Код успешного ответа GoPlus API
+	goPlusCodeOK = 1
+	// defaultTokenSecurityBatchSize - Количество адресов в одном запросе token_security по умолчанию
+	defaultTokenSecurityBatchSize = 50
+	// defaultTokenSecurityConcurrency - Количество параллельных запросов token_security по умолчанию
+	defaultTokenSecurityConcurrency = 4
+	// defaultSecurityCacheTTL - Срок кэширования вердиктов GoPlus по контрактам по умолчанию
+	defaultSecurityCacheTTL = 24 * time.Hour
 )
 
 // GoPlusClient - Клиент для GoPlus Security API
 type GoPlusClient struct {
 	apiKey    string
 	apiSecret string
+	baseURL   string
 	client    *http.Client
 	log       *logrus.Entry
+
+	tokenSecurityBatchSize   int
+	tokenSecurityConcurrency int
+
+	// Кэши вердиктов по контрактам (nil - кэш не подключен)
+	tokenSecurityTTL     time.Duration
+	nftSecurityTTL       time.Duration
+	addressSecurityTTL   time.Duration
+	tokenSecurityCache   *cache.Typed[TokenSecurity]
+	nftSecurityCache     *cache.Typed[NFTSecurity]
+	addressSecurityCache *cache.Typed[AddressSecurity]
+
+	// Access token, полученный по app key/secret, и время его истечения
+	tokenMu     sync.Mutex
+	accessToken string
+	tokenExpiry time.Time
+	now         func() time.Time
 }
 
 // NewGoPlusClient - Создает новый клиент GoPlus
 func NewGoPlusClient(cfg *config.Config, log *logrus.Entry) *GoPlusClient {
+	baseURL := strings.TrimRight(cfg.GoPlus.URL, "/")
+	if baseURL == "" {
+		baseURL = defaultGoPlusURL
+	}
+	batchSize := cfg.GoPlus.TokenSecurityBatchSize
+	if batchSize <= 0 {
+		batchSize = defaultTokenSecurityBatchSize
+	}
+	concurrency := cfg.GoPlus.TokenSecurityConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultTokenSecurityConcurrency
+	}
 	logger := log.WithFields(logrus.Fields{"component": "goplus"})
 	return &GoPlusClient{
 		apiKey:    cfg.GoPlus.ApiKey,
 		apiSecret: cfg.GoPlus.ApiSecret,
+		baseURL:   baseURL,
 		client: &http.Client{
 			Timeout: time.Duration(cfg.App.TimeoutSec) * time.Second,
 		},
-		log: logger,
+		log:                      logger,
+		tokenSecurityBatchSize:   batchSize,
+		tokenSecurityConcurrency: concurrency,
+		tokenSecurityTTL:         secondsOrDefault(cfg.Cache.TokenSecurityTTLSeconds, defaultSecurityCacheTTL),
+		nftSecurityTTL:           secondsOrDefault(cfg.Cache.NFTSecurityTTLSeconds, defaultSecurityCacheTTL),
+		addressSecurityTTL:       secondsOrDefault(cfg.Cache.AddressSecurityTTLSeconds, defaultSecurityCacheTTL),
+		now:                      time.Now,
 	}
 }
 
+// UseCache - Подключает кэш вердиктов token_security, nft_security и address_security по адресам
+func (c *GoPlusClient) UseCache(store cache.Store) {
+	c.tokenSecurityCache = cache.NewTyped[TokenSecurity](store, cache.NamespaceTokenSecurity, c.tokenSecurityTTL, c.log)
+	c.nftSecurityCache = cache.NewTyped[NFTSecurity](store, cache.NamespaceNFTSecurity, c.nftSecurityTTL, c.log)
+	c.addressSecurityCache = cache.NewTyped[AddressSecurity](store, cache.NamespaceAddressSecurity, c.addressSecurityTTL, c.log)
+}
+
+// goPlusEnvelope - Общая обертка ответа GoPlus API
+type goPlusEnvelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// AccessTokenResponse - Ответ GoPlus API на запрос access token
+type AccessTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int64  `json:"expires_in"`
+}
+
 // GoPlusResponse — корневая структура ответа
 type TokenApprovalResponse struct {
 	Code    int             `json:"code"`
@@ -83,79 +162,416 @@ type AddressInfo struct {
 
 // TokenSecurityResponse - Ответ API GoPlus для токен security
 type TokenSecurityResponse struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Result  map[string]struct {
-		BuyTax         string `json:"buy_tax"`
-		CannotBuy      string `json:"cannot_buy"`
-		CreatorAddress string `json:"creator_address"`
-		CreatorBalance string `json:"creator_balance"`
-		CreatorPercent string `json:"creator_percent"`
-		HolderCount    string `json:"holder_count"`
-		Holders        []struct {
-			Address    string `json:"address"`
-			Tag        string `json:"tag"`
-			IsContract int    `json:"is_contract"`
-			Balance    string `json:"balance"`
-			Percent    string `json:"percent"`
-			IsLocked   int    `json:"is_locked"`
-		} `json:"holders"`
-		HoneypotWithSameCreator string `json:"honeypot_with_same_creator"`
-		IsInDex                 string `json:"is_in_dex"`
-		IsOpenSource            string `json:"is_open_source"`
-		IsProxy                 string `json:"is_proxy"`
-		OwnerAddress            string `json:"owner_address"`
-		SellTax                 string `json:"sell_tax"`
-		TokenName               string `json:"token_name"`
-		TokenSymbol             string `json:"token_symbol"`
-		TotalSupply             string `json:"total_supply"`
-	} `json:"result"`
+	Code    int                      `json:"code"`
+	Message string                   `json:"message"`
+	Result  map[string]TokenSecurity `json:"result"`
+	// Failed - Токены, которые не удалось проверить, с текстом ошибки (адрес в нижнем регистре)
+	Failed map[string]string `json:"-"`
+}
+
+// TokenSecurity - Данные GoPlus о безопасности одного токена (полная схема token_security).
+// Флаги приходят строками "1"/"0", пустая строка - GoPlus не смог определить значение.
+// Налоги и доли владения - доли единицы ("0.1" - 10%).
+type TokenSecurity struct {
+	TokenName   string `json:"token_name"`
+	TokenSymbol string `json:"token_symbol"`
+	TotalSupply string `json:"total_supply"`
+	HolderCount string `json:"holder_count"`
+
+	// Контракт
+	IsOpenSource         string `json:"is_open_source"`
+	IsProxy              string `json:"is_proxy"`
+	IsMintable           string `json:"is_mintable"`
+	CanTakeBackOwnership string `json:"can_take_back_ownership"`
+	OwnerChangeBalance   string `json:"owner_change_balance"`
+	HiddenOwner          string `json:"hidden_owner"`
+	SelfDestruct         string `json:"selfdestruct"`
+	ExternalCall         string `json:"external_call"`
+
+	// Торговля
+	IsHoneypot                 string     `json:"is_honeypot"`
+	HoneypotWithSameCreator    string     `json:"honeypot_with_same_creator"`
+	BuyTax                     string     `json:"buy_tax"`
+	SellTax                    string     `json:"sell_tax"`
+	TransferTax                string     `json:"transfer_tax"`
+	CannotBuy                  string     `json:"cannot_buy"`
+	CannotSellAll              string     `json:"cannot_sell_all"`
+	SlippageModifiable         string     `json:"slippage_modifiable"`
+	PersonalSlippageModifiable string     `json:"personal_slippage_modifiable"`
+	TransferPausable           string     `json:"transfer_pausable"`
+	TradingCooldown            string     `json:"trading_cooldown"`
+	IsBlacklisted              string     `json:"is_blacklisted"`
+	IsWhitelisted              string     `json:"is_whitelisted"`
+	IsAntiWhale                string     `json:"is_anti_whale"`
+	AntiWhaleModifiable        string     `json:"anti_whale_modifiable"`
+	IsInDex                    string     `json:"is_in_dex"`
+	Dex                        []TokenDex `json:"dex"`
+
+	// Владельцы и держатели
+	CreatorAddress string           `json:"creator_address"`
+	CreatorBalance string           `json:"creator_balance"`
+	CreatorPercent string           `json:"creator_percent"`
+	OwnerAddress   string           `json:"owner_address"`
+	OwnerBalance   string           `json:"owner_balance"`
+	OwnerPercent   string           `json:"owner_percent"`
+	Holders        []TokenHolder    `json:"holders"`
+	LPHolderCount  string           `json:"lp_holder_count"`
+	LPTotalSupply  string           `json:"lp_total_supply"`
+	LPHolders      []TokenHolder    `json:"lp_holders"`
+	FakeToken      *TokenFakeInfo   `json:"fake_token,omitempty"`
+	IsInCEX        *TokenCEXListing `json:"is_in_cex,omitempty"`
+
+	// Прочее
+	IsAirdropScam       string `json:"is_airdrop_scam"`
+	IsTrueToken         string `json:"is_true_token"`
+	TrustList           string `json:"trust_list"`
+	OtherPotentialRisks string `json:"other_potential_risks"`
+	Note                string `json:"note"`
+}
+
+// TokenHolder - Крупный держатель токена или LP токена
+type TokenHolder struct {
+	Address    string `json:"address"`
+	Tag        string `json:"tag"`
+	IsContract int    `json:"is_contract"`
+	Balance    string `json:"balance"`
+	Percent    string `json:"percent"`
+	IsLocked   int    `json:"is_locked"`
+}
+
+// TokenDex - Пул токена на DEX
+type TokenDex struct {
+	Name          string `json:"name"`
+	LiquidityType string `json:"liquidity_type"`
+	Liquidity     string `json:"liquidity"`
+	Pair          string `json:"pair"`
+}
+
+// TokenFakeInfo - Токен выдает себя за известный (value = 1)
+type TokenFakeInfo struct {
+	TrueTokenAddress string `json:"true_token_address"`
+	Value            int    `json:"value"`
+}
+
+// TokenCEXListing - Листинг токена на централизованных биржах
+type TokenCEXListing struct {
+	Listed  string   `json:"listed"`
+	CEXList []string `json:"cex_list"`
 }
 
 // GetTokenApprovals - Получает информацию о токен approvals
 func (c *GoPlusClient) GetTokenApprovals(ctx context.Context, address string) (*TokenApprovalResponse, error) {
-	url := fmt.Sprintf("https://api.gopluslabs.io/api/v2/token_approval_security/1?addresses=%s", address)
+	params := url.Values{}
+	params.Set("addresses", address)
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
-	if err != nil {
+	var result TokenApprovalResponse
+	if err := c.get(ctx, "/api/v2/token_approval_security/1", params, &result); err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("API-Key", c.apiKey)
+	return &result, nil
+}
 
-	resp, err := c.client.Do(req)
+// GetTokenSecurity - Получает информацию о безопасности токенов.
+// Вердикты, найденные в кэше, не запрашиваются повторно. Остальные адреса разбиваются на батчи по tokenSecurityBatchSize, батчи запрашиваются параллельно
+// (не более tokenSecurityConcurrency одновременно) и результаты объединяются.
+// Токены из неудавшихся батчей попадают в Failed; ошибка возвращается, только если не удался ни один батч.
+func (c *GoPlusClient) GetTokenSecurity(ctx context.Context, tokenAddresses []string) (*TokenSecurityResponse, error) {
+	cached, missing := c.tokenSecurityCache.GetMany(ctx, uniqueLower(tokenAddresses))
+	fromCache := len(cached)
+	batches := chunkStrings(missing, c.tokenSecurityBatchSize)
 
-	if err != nil {
-		return nil, err
+	merged := &TokenSecurityResponse{
+		Code:    goPlusCodeOK,
+		Result:  cached,
+		Failed:  make(map[string]string),
+		Message: "OK",
+	}
+	if len(batches) == 0 {
+		return merged, nil
 	}
-	defer resp.Body.Close()
 
-	bodyBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
+	var mu sync.Mutex
+	var lastErr error
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(c.tokenSecurityConcurrency)
+
+	for _, b := range batches {
+		batch := b
+		g.Go(func() error {
+			resp, err := c.getTokenSecurityBatch(gctx, batch)
+			if err == nil {
+				for addr, info := range resp.Result {
+					c.tokenSecurityCache.Set(gctx, addr, info)
+				}
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				c.log.Warnf("Token security batch of %d tokens failed: %v", len(batch), err)
+				lastErr = err
+				for _, addr := range batch {
+					merged.Failed[addr] = err.Error()
+				}
+				return nil
+			}
+
+			for addr, info := range resp.Result {
+				merged.Result[strings.ToLower(addr)] = info
+			}
+			// GoPlus молча пропускает неизвестные ему контракты
+			for _, addr := range batch {
+				if _, ok := resp.Result[addr]; !ok {
+					merged.Failed[addr] = "no data returned by GoPlus"
+				}
+			}
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	if len(merged.Result) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	c.log.Debugf("Token security fetched for %d tokens (%d from cache) in %d batches, %d failed", len(merged.Result), fromCache, len(batches), len(merged.Failed))
+	return merged, nil
+}
+
+// NFTSecurity - Данные GoPlus о безопасности NFT коллекции
+type NFTSecurity struct {
+	NFTName              string  `json:"nft_name"`
+	NFTSymbol            string  `json:"nft_symbol"`
+	NFTVerified          int     `json:"nft_verified"`
+	NFTOpenSource        int     `json:"nft_open_source"`
+	TrustList            int     `json:"trust_list"`
+	MaliciousNFTContract int     `json:"malicious_nft_contract"`
+	NFTItems             int     `json:"nft_items"`
+	NFTOwnerNumber       int     `json:"nft_owner_number"`
+	TradedVolume24h      float64 `json:"traded_volume_24h"`
+	TotalVolume          float64 `json:"total_volume"`
+	HighestPrice         float64 `json:"highest_price"`
+}
+
+// GetNFTSecurity - Получает вердикт GoPlus по NFT коллекции (с кэшированием по контракту)
+func (c *GoPlusClient) GetNFTSecurity(ctx context.Context, contractAddress string) (*NFTSecurity, error) {
+	contractAddress = strings.ToLower(contractAddress)
+	if cached, ok := c.nftSecurityCache.Get(ctx, contractAddress); ok {
+		return &cached, nil
+	}
+
+	params := url.Values{}
+	params.Set("contract_addresses", contractAddress)
+
+	var result struct {
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Result  NFTSecurity `json:"result"`
+	}
+	if err := c.get(ctx, "/api/v1/nft_security/1", params, &result); err != nil {
 		return nil, err
 	}
 
-	var result TokenApprovalResponse
-	if err := json.Unmarshal(bodyBytes, &result); err != nil {
+	c.nftSecurityCache.Set(ctx, contractAddress, result.Result)
+	return &result.Result, nil
+}
+
+// AddressSecurity - Вердикт GoPlus по адресу (флаги "1"/"0")
+type AddressSecurity struct {
+	Cybercrime                 string `json:"cybercrime"`
+	MoneyLaundering            string `json:"money_laundering"`
+	FinancialCrime             string `json:"financial_crime"`
+	DarkwebTransactions        string `json:"darkweb_transactions"`
+	PhishingActivities         string `json:"phishing_activities"`
+	FakeKYC                    string `json:"fake_kyc"`
+	BlacklistDoubt             string `json:"blacklist_doubt"`
+	FakeStandardInterface      string `json:"fake_standard_interface"`
+	StealingAttack             string `json:"stealing_attack"`
+	BlackmailActivities        string `json:"blackmail_activities"`
+	Sanctioned                 string `json:"sanctioned"`
+	MaliciousMiningActivities  string `json:"malicious_mining_activities"`
+	Mixer                      string `json:"mixer"`
+	FakeToken                  string `json:"fake_token"`
+	HoneypotRelatedAddress     string `json:"honeypot_related_address"`
+	NumberOfMaliciousContracts string `json:"number_of_malicious_contracts_created"`
+	ContractAddress            string `json:"contract_address"`
+	DataSource                 string `json:"data_source"`
+}
+
+// Flags - Названия выставленных флагов риска (в порядке полей ответа)
+func (s *AddressSecurity) Flags() []string {
+	fields := []struct {
+		name  string
+		value string
+	}{
+		{"cybercrime", s.Cybercrime},
+		{"money_laundering", s.MoneyLaundering},
+		{"financial_crime", s.FinancialCrime},
+		{"darkweb_transactions", s.DarkwebTransactions},
+		{"phishing_activities", s.PhishingActivities},
+		{"fake_kyc", s.FakeKYC},
+		{"blacklist_doubt", s.BlacklistDoubt},
+		{"fake_standard_interface", s.FakeStandardInterface},
+		{"stealing_attack", s.StealingAttack},
+		{"blackmail_activities", s.BlackmailActivities},
+		{"sanctioned", s.Sanctioned},
+		{"malicious_mining_activities", s.MaliciousMiningActivities},
+		{"mixer", s.Mixer},
+		{"fake_token", s.FakeToken},
+		{"honeypot_related_address", s.HoneypotRelatedAddress},
+	}
+
+	var flags []string
+	for _, f := range fields {
+		if f.value == "1" {
+			flags = append(flags, f.name)
+		}
+	}
+	if n := strings.TrimSpace(s.NumberOfMaliciousContracts); n != "" && n != "0" {
+		flags = append(flags, "malicious_contracts_created")
+	}
+	return flags
+}
+
+// GetAddressSecurity - Получает вердикт GoPlus по адресу (с кэшированием по адресу)
+func (c *GoPlusClient) GetAddressSecurity(ctx context.Context, address string) (*AddressSecurity, error) {
+	address = strings.ToLower(address)
+	if cached, ok := c.addressSecurityCache.Get(ctx, address); ok {
+		return &cached, nil
+	}
+
+	params := url.Values{}
+	params.Set("chain_id", "1")
+
+	var result struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Result  AddressSecurity `json:"result"`
+	}
+	if err := c.get(ctx, "/api/v1/address_security/"+address, params, &result); err != nil {
 		return nil, err
 	}
 
-	if result.Code != 1 {
-		return nil, fmt.Errorf("GoPlus API error: %s", result.Message)
+	c.addressSecurityCache.Set(ctx, address, result.Result)
+	return &result.Result, nil
+}
+
+// getTokenSecurityBatch - Запрашивает token security для одного батча адресов
+func (c *GoPlusClient) getTokenSecurityBatch(ctx context.Context, tokenAddresses []string) (*TokenSecurityResponse, error) {
+	params := url.Values{}
+	params.Set("contract_addresses", strings.Join(tokenAddresses, ","))
+
+	var result TokenSecurityResponse
+	if err := c.get(ctx, "/api/v1/token_security/1", params, &result); err != nil {
+		return nil, err
 	}
 
 	return &result, nil
 }
 
-// GetTokenSecurity - Получает информацию о безопасности токенов
-func (c *GoPlusClient) GetTokenSecurity(ctx context.Context, tokenAddresses []string) (*TokenSecurityResponse, error) {
-	url := fmt.Sprintf("https://api.gopluslabs.io/api/v1/token_security/1?contract_addresses=%s", strings.Join(tokenAddresses, ","))
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+// get - Выполняет GET запрос к GoPlus API и декодирует ответ в out.
+// При ответе 401 access token сбрасывается и запрос повторяется один раз.
+func (c *GoPlusClient) get(ctx context.Context, path string, params url.Values, out interface{}) error {
+	urlStr := fmt.Sprintf("%s%s?%s", c.baseURL, path, params.Encode())
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+		if err != nil {
+			return err
+		}
+
+		token, err := c.getAccessToken(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get GoPlus access token: %w", err)
+		}
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		resp, err := c.client.Do(req)
+		if err != nil {
+			c.log.Errorf("GoPlus API request failed: %v", err)
+			return err
+		}
+
+		bodyBytes, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			c.log.Errorf("Failed to read response body: %v", err)
+			return err
+		}
+
+		if resp.StatusCode == http.StatusUnauthorized && token != "" && attempt == 0 {
+			c.log.Warn("GoPlus access token rejected, refreshing")
+			c.invalidateAccessToken()
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("GoPlus API returned status %d: %s", resp.StatusCode, string(bodyBytes))
+		}
+
+		var envelope goPlusEnvelope
+		if err := json.Unmarshal(bodyBytes, &envelope); err != nil {
+			c.log.Errorf("Failed to unmarshal response: %v", err)
+			return err
+		}
+
+		if envelope.Code != goPlusCodeOK {
+			return fmt.Errorf("GoPlus API error: %s", envelope.Message)
+		}
+
+		return json.Unmarshal(bodyBytes, out)
+	}
+}
+
+// getAccessToken - Возвращает действующий access token, при необходимости запрашивая новый.
+// Если app key/secret не заданы, возвращает пустую строку - запросы выполняются анонимно.
+func (c *GoPlusClient) getAccessToken(ctx context.Context) (string, error) {
+	if !c.hasCredentials() {
+		return "", nil
+	}
+
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+
+	if c.accessToken != "" && c.now().Before(c.tokenExpiry.Add(-goPlusTokenRefreshMargin)) {
+		return c.accessToken, nil
+	}
+
+	token, err := c.requestAccessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	c.accessToken = token.AccessToken
+	c.tokenExpiry = c.now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	c.log.Debugf("GoPlus access token refreshed, expires in %ds", token.ExpiresIn)
+
+	return c.accessToken, nil
+}
+
+// requestAccessToken - Запрашивает access token по app key/secret.
+// Подпись: sha1(app_key + time + app_secret) в hex.
+func (c *GoPlusClient) requestAccessToken(ctx context.Context) (*AccessTokenResponse, error) {
+	ts := c.now().Unix()
+	sum := sha1.Sum([]byte(fmt.Sprintf("%s%d%s", c.apiKey, ts, c.apiSecret)))
+
+	reqData, err := json.Marshal(map[string]interface{}{
+		"app_key": c.apiKey,
+		"sign":    hex.EncodeToString(sum[:]),
+		"time":    ts,
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	req.Header.Set("API-Key", c.apiKey)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/api/v1/token", bytes.NewBuffer(reqData))
+	if err != nil {
+		return nil, err
+	}
 	req.Header.Set("Content-Type", "application/json")
 
 	resp, err := c.client.Do(req)
@@ -164,16 +580,87 @@ func (c *GoPlusClient) GetTokenSecurity(ctx context.Context, tokenAddresses []st
 	}
 	defer resp.Body.Close()
 
-	var result TokenSecurityResponse
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
 		return nil, err
 	}
 
-	if result.Code != 1 {
-		return nil, fmt.Errorf("GoPlus API error: %s", result.Message)
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GoPlus token API returned status %d: %s", resp.StatusCode, string(bodyBytes))
 	}
 
-	return &result, nil
+	var envelope goPlusEnvelope
+	if err := json.Unmarshal(bodyBytes, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Code != goPlusCodeOK {
+		return nil, fmt.Errorf("GoPlus token API error: %s", envelope.Message)
+	}
+
+	var token AccessTokenResponse
+	if err := json.Unmarshal(envelope.Result, &token); err != nil {
+		return nil, err
+	}
+	if token.AccessToken == "" {
+		return nil, fmt.Errorf("GoPlus token API returned empty access token")
+	}
+
+	return &token, nil
+}
+
+// invalidateAccessToken - Сбрасывает сохраненный access token
+func (c *GoPlusClient) invalidateAccessToken() {
+	c.tokenMu.Lock()
+	defer c.tokenMu.Unlock()
+	c.accessToken = ""
+	c.tokenExpiry = time.Time{}
+}
+
+// hasCredentials - Проверяет, заданы ли app key/secret (плейсхолдеры из config.yaml не считаются)
+func (c *GoPlusClient) hasCredentials() bool {
+	if c.apiKey == "" || c.apiSecret == "" {
+		return false
+	}
+	return !strings.HasPrefix(c.apiKey, "USE-") && !strings.HasPrefix(c.apiSecret, "USE-")
+}
+
+// uniqueLower - Приводит адреса к нижнему регистру и удаляет дубликаты и пустые значения
+func uniqueLower(addresses []string) []string {
+	seen := make(map[string]struct{}, len(addresses))
+	result := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addr = strings.ToLower(strings.TrimSpace(addr))
+		if addr == "" {
+			continue
+		}
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		result = append(result, addr)
+	}
+	return result
+}
+
+// secondsOrDefault - Переводит значение конфига в секундах в Duration; 0 - значение по умолчанию
+func secondsOrDefault(seconds int, def time.Duration) time.Duration {
+	if seconds <= 0 {
+		return def
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// chunkStrings - Разбивает срез на части не длиннее size
+func chunkStrings(items []string, size int) [][]string {
+	var chunks [][]string
+	for size < len(items) {
+		chunks = append(chunks, items[:size:size])
+		items = items[size:]
+	}
+	if len(items) > 0 {
+		chunks = append(chunks, items)
+	}
+	return chunks
 }
 
 // getEnv - Получает значение переменной окружения
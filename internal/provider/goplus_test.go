@@ -0,0 +1,323 @@
+package provider
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// goPlusFixtureServer - Тестовый сервер, отдающий записанные ответы GoPlus API из testdata/goplus
+type goPlusFixtureServer struct {
+	*httptest.Server
+	t            *testing.T
+	tokenCalls   atomic.Int32
+	lastAuth     atomic.Value
+	rejectTokens atomic.Int32
+	routes       map[string]string
+}
+
+func newGoPlusFixtureServer(t *testing.T, routes map[string]string) *goPlusFixtureServer {
+	s := &goPlusFixtureServer{t: t, routes: routes}
+	s.lastAuth.Store("")
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+func (s *goPlusFixtureServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/api/v1/token" {
+		s.tokenCalls.Add(1)
+		var body struct {
+			AppKey string `json:"app_key"`
+			Sign   string `json:"sign"`
+			Time   int64  `json:"time"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		sum := sha1.Sum([]byte(fmt.Sprintf("%s%d%s", body.AppKey, body.Time, "test-secret")))
+		if body.AppKey != "test-key" || body.Sign != hex.EncodeToString(sum[:]) {
+			_, _ = w.Write([]byte(`{"code":4012,"message":"signature verification failure","result":null}`))
+			return
+		}
+		s.writeFixture(w, "access_token.json")
+		return
+	}
+
+	auth := r.Header.Get("Authorization")
+	s.lastAuth.Store(auth)
+	if s.rejectTokens.Load() > 0 {
+		s.rejectTokens.Add(-1)
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	fixture, ok := s.routes[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.writeFixture(w, fixture)
+}
+
+func (s *goPlusFixtureServer) writeFixture(w http.ResponseWriter, name string) {
+	data, err := os.ReadFile(filepath.Join("testdata", "goplus", name))
+	require.NoError(s.t, err)
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data)
+}
+
+func newTestGoPlusClient(t *testing.T, baseURL, key, secret string) *GoPlusClient {
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.App.TimeoutSec = 5
+	cfg.GoPlus.URL = baseURL
+	cfg.GoPlus.ApiKey = key
+	cfg.GoPlus.ApiSecret = secret
+
+	return NewGoPlusClient(cfg, log.WithContext(context.Background()))
+}
+
+func TestGoPlusClient_GetTokenApprovals(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v2/token_approval_security/1": "token_approval_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "test-key", "test-secret")
+
+	resp, err := client.GetTokenApprovals(context.Background(), "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	require.NoError(t, err)
+	require.Len(t, resp.Result, 2)
+
+	usdt := resp.Result[0]
+	assert.Equal(t, "USDT", usdt.TokenSymbol)
+	assert.Equal(t, 6, usdt.Decimals)
+	require.Len(t, usdt.ApprovedList, 1)
+	assert.Equal(t, "Unlimited", usdt.ApprovedList[0].ApprovedAmount)
+	assert.Equal(t, int64(1650000000), usdt.ApprovedList[0].InitialApprovalTime)
+	assert.Nil(t, usdt.ApprovedList[0].AddressInfo.Tag)
+
+	phishing := resp.Result[1].ApprovedList[0].AddressInfo
+	require.NotNil(t, phishing.Tag)
+	assert.Equal(t, "Fake_Phishing", *phishing.Tag)
+	assert.Equal(t, 1, phishing.DoubtList)
+	assert.Len(t, phishing.MaliciousBehavior, 1)
+
+	assert.Equal(t, "Bearer fixture-access-token", srv.lastAuth.Load())
+}
+
+func TestGoPlusClient_GetTokenSecurity(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "token_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "test-key", "test-secret")
+
+	resp, err := client.GetTokenSecurity(context.Background(), []string{
+		"0xaff8ed5415b68ab81786200e3bfd74d7c37df31e",
+		"0xdac17f958d2ee523a2206206994597c13d831ec7",
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Result, 2)
+
+	dopp := resp.Result["0xaff8ed5415b68ab81786200e3bfd74d7c37df31e"]
+	assert.Equal(t, "1", dopp.CannotBuy)
+	assert.Equal(t, "1", dopp.HoneypotWithSameCreator)
+	assert.Len(t, dopp.Holders, 1)
+	assert.Equal(t, "1", dopp.IsHoneypot)
+	assert.Equal(t, "1", dopp.IsMintable)
+
+	usdt := resp.Result["0xdac17f958d2ee523a2206206994597c13d831ec7"]
+	assert.Equal(t, "1", usdt.TransferPausable)
+	assert.Equal(t, "0xc6cde7c39eb2f0f0095f41570af89efc2c1ea828", usdt.OwnerAddress)
+	require.Len(t, usdt.Dex, 1)
+	assert.Equal(t, "UniswapV3", usdt.Dex[0].Name)
+	require.NotNil(t, usdt.IsInCEX)
+	assert.Equal(t, []string{"Binance", "Coinbase"}, usdt.IsInCEX.CEXList)
+}
+
+func TestGoPlusClient_GetAddressSecurity(t *testing.T) {
+	const drainer = "0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220"
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/address_security/" + drainer: "address_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "test-key", "test-secret")
+
+	security, err := client.GetAddressSecurity(context.Background(), "0x4EE879F39CCE3C4CA80E2EE90F9DF5AFEEAEB220")
+	require.NoError(t, err)
+	assert.Equal(t, "SlowMist", security.DataSource)
+	assert.Equal(t, []string{"phishing_activities", "stealing_attack"}, security.Flags())
+}
+
+func TestGoPlusClient_APIError(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "error.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "", "")
+
+	_, err := client.GetTokenSecurity(context.Background(), []string{"0xdac17f958d2ee523a2206206994597c13d831ec7"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "request limit reached")
+}
+
+func TestGoPlusClient_AnonymousWithoutCredentials(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "token_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "USE-KEY-FROM-.env", "USE-SECRET-FROM-.env")
+
+	_, err := client.GetTokenSecurity(context.Background(), []string{"0xdac17f958d2ee523a2206206994597c13d831ec7"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), srv.tokenCalls.Load())
+	assert.Equal(t, "", srv.lastAuth.Load())
+}
+
+func TestGoPlusClient_AccessTokenRefresh(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "token_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "test-key", "test-secret")
+
+	now := time.Unix(1700000000, 0)
+	client.now = func() time.Time { return now }
+
+	ctx := context.Background()
+	tokens := []string{"0xdac17f958d2ee523a2206206994597c13d831ec7"}
+
+	// Токен запрашивается один раз и переиспользуется до истечения
+	_, err := client.GetTokenSecurity(ctx, tokens)
+	require.NoError(t, err)
+	_, err = client.GetTokenSecurity(ctx, tokens)
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), srv.tokenCalls.Load())
+
+	// За минуту до истечения (expires_in = 3600) токен обновляется
+	now = now.Add(3600*time.Second - goPlusTokenRefreshMargin)
+	_, err = client.GetTokenSecurity(ctx, tokens)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), srv.tokenCalls.Load())
+
+	// Отклоненный токен сбрасывается, запрос повторяется с новым
+	srv.rejectTokens.Store(1)
+	_, err = client.GetTokenSecurity(ctx, tokens)
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), srv.tokenCalls.Load())
+}
+
+func TestGoPlusClient_InvalidCredentials(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "token_security.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "test-key", "wrong-secret")
+
+	_, err := client.GetTokenSecurity(context.Background(), []string{"0xdac17f958d2ee523a2206206994597c13d831ec7"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "signature verification failure")
+}
+
+func TestGoPlusClient_GetTokenSecurityBatches(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		addrs := strings.Split(r.URL.Query().Get("contract_addresses"), ",")
+		assert.LessOrEqual(t, len(addrs), 2)
+
+		// Батч с этим адресом отвечает ошибкой API
+		if addrs[0] == "0x0000000000000000000000000000000000000003" {
+			_, _ = w.Write([]byte(`{"code":4029,"message":"request limit reached","result":null}`))
+			return
+		}
+
+		result := make(map[string]TokenSecurity)
+		for _, addr := range addrs {
+			result[addr] = TokenSecurity{TokenSymbol: "T" + addr[len(addr)-1:]}
+		}
+		_ = json.NewEncoder(w).Encode(TokenSecurityResponse{Code: 1, Message: "OK", Result: result})
+	}))
+	t.Cleanup(srv.Close)
+
+	client := newTestGoPlusClient(t, srv.URL, "", "")
+	client.tokenSecurityBatchSize = 2
+	client.tokenSecurityConcurrency = 2
+
+	resp, err := client.GetTokenSecurity(context.Background(), []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+		"0x0000000000000000000000000000000000000003",
+		"0x0000000000000000000000000000000000000004",
+		"0x0000000000000000000000000000000000000005",
+		"0x0000000000000000000000000000000000000001",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, int32(3), calls.Load())
+	assert.Len(t, resp.Result, 3)
+	assert.Equal(t, "T5", resp.Result["0x0000000000000000000000000000000000000005"].TokenSymbol)
+	assert.Len(t, resp.Failed, 2)
+	assert.Contains(t, resp.Failed["0x0000000000000000000000000000000000000004"], "request limit reached")
+}
+
+func TestGoPlusClient_GetTokenSecurityAllBatchesFailed(t *testing.T) {
+	srv := newGoPlusFixtureServer(t, map[string]string{
+		"/api/v1/token_security/1": "error.json",
+	})
+	client := newTestGoPlusClient(t, srv.URL, "", "")
+	client.tokenSecurityBatchSize = 1
+
+	_, err := client.GetTokenSecurity(context.Background(), []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+	})
+	require.Error(t, err)
+}
+
+func TestGoPlusClient_GetTokenSecurityCached(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		result := make(map[string]TokenSecurity)
+		for _, addr := range strings.Split(r.URL.Query().Get("contract_addresses"), ",") {
+			result[addr] = TokenSecurity{TokenSymbol: "T" + addr[len(addr)-1:]}
+		}
+		_ = json.NewEncoder(w).Encode(TokenSecurityResponse{Code: 1, Message: "OK", Result: result})
+	}))
+	t.Cleanup(srv.Close)
+
+	client := newTestGoPlusClient(t, srv.URL, "", "")
+	client.UseCache(cache.NewMemoryStore(&config.Config{}, client.log))
+
+	_, err := client.GetTokenSecurity(context.Background(), []string{"0x0000000000000000000000000000000000000001"})
+	require.NoError(t, err)
+
+	// Второй запрос берет первый токен из кэша и запрашивает только новый
+	resp, err := client.GetTokenSecurity(context.Background(), []string{
+		"0x0000000000000000000000000000000000000001",
+		"0x0000000000000000000000000000000000000002",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+	assert.Len(t, resp.Result, 2)
+	assert.Equal(t, "T1", resp.Result["0x0000000000000000000000000000000000000001"].TokenSymbol)
+
+	_, err = client.GetTokenSecurity(context.Background(), []string{"0x0000000000000000000000000000000000000002"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
@@ -0,0 +1,22 @@
+package provider
+
+import "context"
+
+// Источники данных, которые можно выбрать в config.yaml (секция sources)
+const (
+	SourceAlchemy   = "alchemy"
+	SourceEtherscan = "etherscan"
+	SourceGoPlus    = "goplus"
+	SourceNode      = "node"
+)
+
+// BalanceProvider - Источник балансов ETH и ERC-20 токенов кошелька
+type BalanceProvider interface {
+	GetETHBalance(ctx context.Context, address string) (float64, error)
+	GetERC20Tokens(ctx context.Context, address string) ([]*TokenBalance, error)
+}
+
+// ApprovalProvider - Источник выданных кошельком ERC-20 approvals
+type ApprovalProvider interface {
+	GetTokenApprovals(ctx context.Context, address string) (*TokenApprovalResponse, error)
+}
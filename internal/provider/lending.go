@@ -0,0 +1,312 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Протоколы кредитования, позиции которых читает GetLendingPositions
+	LendingProtocolAaveV3     = "aave_v3"
+	LendingProtocolCompoundV3 = "compound_v3"
+
+	// lendingPriceScale - Точность base currency Aave (USD, 8 знаков) и цен оракулов Comet
+	lendingPriceScale = 1e8
+	// lendingWadScale - Точность health factor Aave и коэффициентов залога Comet
+	lendingWadScale = 1e18
+	// aaveBasisPoints - Точность порога ликвидации Aave
+	aaveBasisPoints = 1e4
+)
+
+// lendingABI - View методы Aave v3 Pool и Compound v3 Comet, которые читает проверка позиций
+const lendingABI = `[
+	{"inputs":[{"name":"user","type":"address"}],"name":"getUserAccountData","outputs":[
+		{"name":"totalCollateralBase","type":"uint256"},{"name":"totalDebtBase","type":"uint256"},{"name":"availableBorrowsBase","type":"uint256"},
+		{"name":"currentLiquidationThreshold","type":"uint256"},{"name":"ltv","type":"uint256"},{"name":"healthFactor","type":"uint256"}],
+	 "stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"account","type":"address"}],"name":"borrowBalanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"account","type":"address"}],"name":"isLiquidatable","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"numAssets","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"baseToken","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"baseTokenPriceFeed","outputs":[{"name":"","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"baseScale","outputs":[{"name":"","type":"uint64"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"priceFeed","type":"address"}],"name":"getPrice","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"i","type":"uint8"}],"name":"getAssetInfo","outputs":[{"components":[
+		{"name":"offset","type":"uint8"},{"name":"asset","type":"address"},{"name":"priceFeed","type":"address"},{"name":"scale","type":"uint64"},
+		{"name":"borrowCollateralFactor","type":"uint64"},{"name":"liquidateCollateralFactor","type":"uint64"},
+		{"name":"liquidationFactor","type":"uint64"},{"name":"supplyCap","type":"uint128"}],"name":"","type":"tuple"}],
+	 "stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"account","type":"address"},{"name":"asset","type":"address"}],"name":"collateralBalanceOf","outputs":[{"name":"","type":"uint128"}],"stateMutability":"view","type":"function"}
+]`
+
+var lendingCalls = mustParseABI(lendingABI)
+
+// LendingPosition - Заемная позиция кошелька в одном рынке протокола кредитования
+type LendingPosition struct {
+	// Protocol - aave_v3 или compound_v3
+	Protocol string
+	// Market - Адрес Aave Pool или Comet
+	Market common.Address
+	// BaseToken - Базовый актив рынка Comet, в котором выражены Collateral и Debt.
+	// Оракулы Comet возвращают цены в USD или в ETH в зависимости от рынка, поэтому стоимость
+	// переводится в базовый актив. Нулевой адрес - значения в USD (Aave).
+	BaseToken  common.Address
+	Collateral float64
+	Debt       float64
+	// HealthFactor - Отношение залога, взвешенного по порогам ликвидации, к долгу (< 1 - позиция ликвидируема).
+	// 0 - не удалось оценить залог, остается признак Liquidatable от самого протокола.
+	HealthFactor float64
+	// LiquidationThreshold - Средневзвешенный порог ликвидации залога, доля от 0 до 1
+	LiquidationThreshold float64
+	Liquidatable         bool
+}
+
+// cometAssetInfo - Параметры залогового актива Comet (getAssetInfo)
+type cometAssetInfo struct {
+	Offset                    uint8
+	Asset                     common.Address
+	PriceFeed                 common.Address
+	Scale                     uint64
+	BorrowCollateralFactor    uint64
+	LiquidateCollateralFactor uint64
+	LiquidationFactor         uint64
+	SupplyCap                 *big.Int
+}
+
+// cometMarket - Состояние заемной позиции в одном Comet между этапами чтения
+type cometMarket struct {
+	address      common.Address
+	debt         *big.Int
+	liquidatable bool
+	numAssets    uint8
+	baseToken    common.Address
+	baseFeed     common.Address
+	baseScale    uint64
+	assets       []cometAssetInfo
+	basePrice    *big.Int
+}
+
+// GetLendingPositions - Читает заемные позиции кошелька в пулах Aave v3 и рынках Compound v3 через Multicall.
+// Позиции без долга не возвращаются: им не грозит ликвидация. Рынки, которые не ответили, пропускаются.
+func (c *MulticallClient) GetLendingPositions(ctx context.Context, account common.Address, aavePools, comets []common.Address) ([]LendingPosition, error) {
+	positions, err := c.aavePositions(ctx, account, aavePools)
+	if err != nil {
+		return nil, err
+	}
+	cometPositions, err := c.cometPositions(ctx, account, comets)
+	if err != nil {
+		return nil, err
+	}
+	return append(positions, cometPositions...), nil
+}
+
+// aavePositions - Читает getUserAccountData во всех пулах Aave v3 одним batch
+func (c *MulticallClient) aavePositions(ctx context.Context, account common.Address, pools []common.Address) ([]LendingPosition, error) {
+	if len(pools) == 0 {
+		return nil, nil
+	}
+	calls := make([]Call, len(pools))
+	for i, pool := range pools {
+		calls[i] = Call{Target: pool, CallData: packLending("getUserAccountData", account)}
+	}
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Aave account data: %w", err)
+	}
+
+	var positions []LendingPosition
+	for i, pool := range pools {
+		if !results[i].Success {
+			c.log.Debugf("getUserAccountData call for pool %s failed", pool.Hex())
+			continue
+		}
+		out, err := lendingCalls.Unpack("getUserAccountData", results[i].Data)
+		if err != nil || len(out) != 6 {
+			c.log.Debugf("Unexpected getUserAccountData result for pool %s: %v", pool.Hex(), err)
+			continue
+		}
+		collateral, debt := out[0].(*big.Int), out[1].(*big.Int)
+		threshold, healthFactor := out[3].(*big.Int), out[5].(*big.Int)
+		if debt.Sign() == 0 {
+			continue
+		}
+		hf := scaleDown(healthFactor, lendingWadScale)
+		positions = append(positions, LendingPosition{
+			Protocol:             LendingProtocolAaveV3,
+			Market:               pool,
+			Collateral:           scaleDown(collateral, lendingPriceScale),
+			Debt:                 scaleDown(debt, lendingPriceScale),
+			HealthFactor:         hf,
+			LiquidationThreshold: scaleDown(threshold, aaveBasisPoints),
+			Liquidatable:         hf < 1,
+		})
+	}
+	return positions, nil
+}
+
+// cometPositions - Читает позиции Compound v3 в три batch: долг и параметры рынков,
+// цены базового актива и параметры залогов, балансы залогов и их цены.
+// Стоимость залогов переводится в базовый актив по его цене в том же оракуле.
+// Health factor считается так же, как в Aave: сумма залогов с liquidateCollateralFactor, деленная на долг.
+func (c *MulticallClient) cometPositions(ctx context.Context, account common.Address, comets []common.Address) ([]LendingPosition, error) {
+	if len(comets) == 0 {
+		return nil, nil
+	}
+
+	const marketCalls = 6
+	calls := make([]Call, 0, len(comets)*marketCalls)
+	for _, comet := range comets {
+		calls = append(calls,
+			Call{Target: comet, CallData: packLending("borrowBalanceOf", account)},
+			Call{Target: comet, CallData: packLending("isLiquidatable", account)},
+			Call{Target: comet, CallData: packLending("numAssets")},
+			Call{Target: comet, CallData: packLending("baseToken")},
+			Call{Target: comet, CallData: packLending("baseTokenPriceFeed")},
+			Call{Target: comet, CallData: packLending("baseScale")},
+		)
+	}
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Comet markets: %w", err)
+	}
+
+	var markets []*cometMarket
+	for i, comet := range comets {
+		r := results[i*marketCalls : (i+1)*marketCalls]
+		debt, err := unpackLending[*big.Int](r[0], "borrowBalanceOf")
+		if err != nil {
+			c.log.Debugf("borrowBalanceOf call for Comet %s failed: %v", comet.Hex(), err)
+			continue
+		}
+		if debt.Sign() == 0 {
+			continue
+		}
+		market := &cometMarket{address: comet, debt: debt}
+		market.liquidatable, _ = unpackLending[bool](r[1], "isLiquidatable")
+		market.numAssets, _ = unpackLending[uint8](r[2], "numAssets")
+		market.baseToken, _ = unpackLending[common.Address](r[3], "baseToken")
+		market.baseFeed, _ = unpackLending[common.Address](r[4], "baseTokenPriceFeed")
+		market.baseScale, _ = unpackLending[uint64](r[5], "baseScale")
+		if market.baseScale == 0 {
+			c.log.Debugf("Comet %s returned no base scale", comet.Hex())
+			continue
+		}
+		markets = append(markets, market)
+	}
+	if len(markets) == 0 {
+		return nil, nil
+	}
+
+	// Цена базового актива и параметры залогов
+	calls = calls[:0]
+	for _, market := range markets {
+		calls = append(calls, Call{Target: market.address, CallData: packLending("getPrice", market.baseFeed)})
+		for i := uint8(0); i < market.numAssets; i++ {
+			calls = append(calls, Call{Target: market.address, CallData: packLending("getAssetInfo", i)})
+		}
+	}
+	results, err = c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Comet assets: %w", err)
+	}
+	next := 0
+	for _, market := range markets {
+		market.basePrice, _ = unpackLending[*big.Int](results[next], "getPrice")
+		next++
+		for i := uint8(0); i < market.numAssets; i++ {
+			if asset, err := unpackLending[cometAssetInfo](results[next], "getAssetInfo"); err == nil {
+				market.assets = append(market.assets, asset)
+			}
+			next++
+		}
+	}
+
+	// Балансы залогов и их цены
+	calls = calls[:0]
+	for _, market := range markets {
+		for _, asset := range market.assets {
+			calls = append(calls,
+				Call{Target: market.address, CallData: packLending("collateralBalanceOf", account, asset.Asset)},
+				Call{Target: market.address, CallData: packLending("getPrice", asset.PriceFeed)},
+			)
+		}
+	}
+	results, err = c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Comet collateral: %w", err)
+	}
+
+	positions := make([]LendingPosition, 0, len(markets))
+	next = 0
+	for _, market := range markets {
+		position := LendingPosition{
+			Protocol:     LendingProtocolCompoundV3,
+			Market:       market.address,
+			BaseToken:    market.baseToken,
+			Liquidatable: market.liquidatable,
+			Debt:         scaleDown(market.debt, float64(market.baseScale)),
+		}
+		if market.basePrice == nil || market.basePrice.Sign() == 0 {
+			// Залог не оценить без цены базового актива, остается признак isLiquidatable
+			c.log.Debugf("No base token price for Comet %s", market.address.Hex())
+			next += 2 * len(market.assets)
+			positions = append(positions, position)
+			continue
+		}
+		basePrice := scaleDown(market.basePrice, lendingPriceScale)
+
+		var liquidationValue float64
+		for _, asset := range market.assets {
+			balance, balanceErr := unpackLending[*big.Int](results[next], "collateralBalanceOf")
+			price, priceErr := unpackLending[*big.Int](results[next+1], "getPrice")
+			next += 2
+			if balanceErr != nil || priceErr != nil || balance.Sign() == 0 || asset.Scale == 0 {
+				continue
+			}
+			value := scaleDown(balance, float64(asset.Scale)) * scaleDown(price, lendingPriceScale) / basePrice
+			position.Collateral += value
+			liquidationValue += value * float64(asset.LiquidateCollateralFactor) / lendingWadScale
+		}
+		if position.Collateral > 0 {
+			position.LiquidationThreshold = liquidationValue / position.Collateral
+		}
+		if position.Debt > 0 && position.Collateral > 0 {
+			position.HealthFactor = liquidationValue / position.Debt
+			position.Liquidatable = position.Liquidatable || position.HealthFactor < 1
+		}
+		positions = append(positions, position)
+	}
+	return positions, nil
+}
+
+// packLending - Кодирует вызов метода Aave Pool или Comet
+func packLending(method string, args ...interface{}) []byte {
+	data, _ := lendingCalls.Pack(method, args...)
+	return data
+}
+
+// unpackLending - Декодирует единственное значение результата метода Aave Pool или Comet
+func unpackLending[T any](result CallResult, method string) (T, error) {
+	var zero T
+	if !result.Success || len(result.Data) == 0 {
+		return zero, fmt.Errorf("%s call failed", method)
+	}
+	out, err := lendingCalls.Unpack(method, result.Data)
+	if err != nil {
+		return zero, err
+	}
+	if len(out) != 1 {
+		return zero, fmt.Errorf("unexpected %s result", method)
+	}
+	return *abi.ConvertType(out[0], new(T)).(*T), nil
+}
+
+// scaleDown - Переводит целое значение с фиксированной точностью в float64
+func scaleDown(v *big.Int, scale float64) float64 {
+	f, _ := new(big.Float).Quo(new(big.Float).SetInt(v), big.NewFloat(scale)).Float64()
+	return f
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/testutil/evmtest"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// wei - value * 10^decimals
+func wei(value float64, decimals int) common.Hash {
+	f := new(big.Float).Mul(big.NewFloat(value), new(big.Float).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)))
+	v, _ := f.Int(nil)
+	return evmtest.Word(v)
+}
+
+func TestGetLendingPositions(t *testing.T) {
+	var (
+		aavePool   = common.HexToAddress("0x00000000000000000000000000000000000000a1")
+		aaveIdle   = common.HexToAddress("0x00000000000000000000000000000000000000a2")
+		comet      = common.HexToAddress("0x00000000000000000000000000000000000000c1")
+		cometIdle  = common.HexToAddress("0x00000000000000000000000000000000000000c2")
+		cometWETH  = common.HexToAddress("0x00000000000000000000000000000000000000c3")
+		usdc       = common.HexToAddress("0x00000000000000000000000000000000000000e0")
+		weth       = common.HexToAddress("0x00000000000000000000000000000000000000e1")
+		wsteth     = common.HexToAddress("0x00000000000000000000000000000000000000e2")
+		usdcFeed   = common.HexToAddress("0x00000000000000000000000000000000000000f1")
+		ethFeed    = common.HexToAddress("0x00000000000000000000000000000000000000f2")
+		wethFeed   = common.HexToAddress("0x00000000000000000000000000000000000000f3")
+		wstethFeed = common.HexToAddress("0x00000000000000000000000000000000000000f4")
+		noContract = common.HexToAddress("0x00000000000000000000000000000000000000ff")
+	)
+
+	aave := map[common.Hash]common.Hash{}
+	evmtest.SetReturn(aave, packLending("getUserAccountData", testWallet),
+		wei(10000, 8), wei(7000, 8), wei(0, 8), evmtest.Word(big.NewInt(8250)), evmtest.Word(big.NewInt(8000)), wei(1.178571428571428571, 18))
+
+	idle := map[common.Hash]common.Hash{}
+	zero := evmtest.Word(big.NewInt(0))
+	evmtest.SetReturn(idle, packLending("getUserAccountData", testWallet), zero, zero, zero, zero, zero, evmtest.Word(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))))
+
+	market := map[common.Hash]common.Hash{}
+	evmtest.SetReturn(market, packLending("borrowBalanceOf", testWallet), wei(5000, 6))
+	evmtest.SetReturn(market, packLending("isLiquidatable", testWallet), zero)
+	evmtest.SetReturn(market, packLending("numAssets"), evmtest.Word(big.NewInt(1)))
+	evmtest.SetReturn(market, packLending("baseToken"), evmtest.AddressTopic(usdc))
+	evmtest.SetReturn(market, packLending("baseTokenPriceFeed"), evmtest.AddressTopic(usdcFeed))
+	evmtest.SetReturn(market, packLending("baseScale"), wei(1, 6))
+	evmtest.SetReturn(market, packLending("getPrice", usdcFeed), wei(1, 8))
+	evmtest.SetReturn(market, packLending("getAssetInfo", uint8(0)),
+		zero, evmtest.AddressTopic(weth), evmtest.AddressTopic(ethFeed), wei(1, 18), wei(0.825, 18), wei(0.895, 18), wei(0.95, 18), zero)
+	evmtest.SetReturn(market, packLending("collateralBalanceOf", testWallet, weth), wei(2, 18))
+	evmtest.SetReturn(market, packLending("getPrice", ethFeed), wei(3000, 8))
+
+	// Рынок с базовым WETH: оракулы возвращают цены в ETH, а не в USD
+	marketWETH := map[common.Hash]common.Hash{}
+	evmtest.SetReturn(marketWETH, packLending("borrowBalanceOf", testWallet), wei(2, 18))
+	evmtest.SetReturn(marketWETH, packLending("isLiquidatable", testWallet), zero)
+	evmtest.SetReturn(marketWETH, packLending("numAssets"), evmtest.Word(big.NewInt(1)))
+	evmtest.SetReturn(marketWETH, packLending("baseToken"), evmtest.AddressTopic(weth))
+	evmtest.SetReturn(marketWETH, packLending("baseTokenPriceFeed"), evmtest.AddressTopic(wethFeed))
+	evmtest.SetReturn(marketWETH, packLending("baseScale"), wei(1, 18))
+	evmtest.SetReturn(marketWETH, packLending("getPrice", wethFeed), wei(1, 8))
+	evmtest.SetReturn(marketWETH, packLending("getAssetInfo", uint8(0)),
+		zero, evmtest.AddressTopic(wsteth), evmtest.AddressTopic(wstethFeed), wei(1, 18), wei(0.9, 18), wei(0.93, 18), wei(0.975, 18), zero)
+	evmtest.SetReturn(marketWETH, packLending("collateralBalanceOf", testWallet, wsteth), wei(3, 18))
+	evmtest.SetReturn(marketWETH, packLending("getPrice", wstethFeed), wei(1.17, 8))
+
+	marketIdle := map[common.Hash]common.Hash{}
+	evmtest.SetReturn(marketIdle, packLending("borrowBalanceOf", testWallet), zero)
+
+	chain := evmtest.NewChain(t, types.GenesisAlloc{
+		aavePool:  {Code: evmtest.MockTupleContract, Storage: aave},
+		aaveIdle:  {Code: evmtest.MockTupleContract, Storage: idle},
+		comet:     {Code: evmtest.MockTupleContract, Storage: market},
+		cometIdle: {Code: evmtest.MockTupleContract, Storage: marketIdle},
+		cometWETH: {Code: evmtest.MockTupleContract, Storage: marketWETH},
+	})
+	client := newTestMulticall(t, chain.Client, &config.Config{})
+
+	positions, err := client.GetLendingPositions(context.Background(), testWallet,
+		[]common.Address{aavePool, aaveIdle, noContract}, []common.Address{comet, cometIdle, cometWETH, noContract})
+	require.NoError(t, err)
+	require.Len(t, positions, 3)
+
+	assert.Equal(t, LendingProtocolAaveV3, positions[0].Protocol)
+	assert.Equal(t, aavePool, positions[0].Market)
+	assert.Equal(t, common.Address{}, positions[0].BaseToken)
+	assert.InDelta(t, 10000, positions[0].Collateral, 1e-6)
+	assert.InDelta(t, 7000, positions[0].Debt, 1e-6)
+	assert.InDelta(t, 1.1786, positions[0].HealthFactor, 1e-4)
+	assert.InDelta(t, 0.825, positions[0].LiquidationThreshold, 1e-9)
+	assert.False(t, positions[0].Liquidatable)
+
+	assert.Equal(t, LendingProtocolCompoundV3, positions[1].Protocol)
+	assert.Equal(t, comet, positions[1].Market)
+	assert.Equal(t, usdc, positions[1].BaseToken)
+	assert.InDelta(t, 6000, positions[1].Collateral, 1e-6)
+	assert.InDelta(t, 5000, positions[1].Debt, 1e-6)
+	assert.InDelta(t, 1.074, positions[1].HealthFactor, 1e-6)
+	assert.InDelta(t, 0.895, positions[1].LiquidationThreshold, 1e-9)
+	assert.False(t, positions[1].Liquidatable)
+
+	// Значения рынка WETH выражены в WETH
+	assert.Equal(t, cometWETH, positions[2].Market)
+	assert.Equal(t, weth, positions[2].BaseToken)
+	assert.InDelta(t, 3.51, positions[2].Collateral, 1e-6)
+	assert.InDelta(t, 2, positions[2].Debt, 1e-9)
+	assert.InDelta(t, 1.63215, positions[2].HealthFactor, 1e-6)
+	assert.InDelta(t, 0.93, positions[2].LiquidationThreshold, 1e-9)
+}
@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"strings"
 
+	"alpha-hygiene-backend/config"
+
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
@@ -13,74 +15,132 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-// MulticallClient - Клиент для работы с Multicall контрактом
+const (
+	// multicall3Address - Адрес Multicall3, одинаковый для большинства EVM сетей (CREATE2 деплой)
+	multicall3Address = "0xcA11bde05977b3631167028862bE2a173976CA11"
+	// defaultMulticallMaxCalldataBytes - Предел размера calldata одного batch
+	defaultMulticallMaxCalldataBytes = 100000
+	// defaultMulticallMaxGasPerBatch - Предел суммарной оценки газа одного batch
+	defaultMulticallMaxGasPerBatch = 50000000
+	// defaultMulticallGasPerCall - Оценка газа одного view вызова
+	defaultMulticallGasPerCall = 100000
+	// multicallCallOverhead - Накладные расходы ABI кодирования одного вызова в aggregate3, байт
+	multicallCallOverhead = 160
+)
+
+// defaultMulticallAddresses - Адреса Multicall3 по chain id
+var defaultMulticallAddresses = map[uint64]string{
+	1:        multicall3Address, // Ethereum
+	10:       multicall3Address, // Optimism
+	56:       multicall3Address, // BNB Chain
+	137:      multicall3Address, // Polygon
+	8453:     multicall3Address, // Base
+	42161:    multicall3Address, // Arbitrum One
+	11155111: multicall3Address, // Sepolia
+}
+
+// multicall3ABI - ABI методов Multicall3, которые использует клиент
+const multicall3ABI = `[
+	{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],
+	 "name":"aggregate","outputs":[{"name":"blockNumber","type":"uint256"},{"name":"returnData","type":"bytes[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"requireSuccess","type":"bool"},{"components":[{"name":"target","type":"address"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],
+	 "name":"tryAggregate","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}],"name":"calls","type":"tuple[]"}],
+	 "name":"aggregate3","outputs":[{"components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}],"name":"returnData","type":"tuple[]"}],"stateMutability":"view","type":"function"}
+]`
+
+// tokenCallsABI - Методы ERC-20/ERC-721, для которых есть хелперы кодирования и декодирования
+const tokenCallsABI = `[
+	{"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"owner","type":"address"},{"name":"operator","type":"address"}],"name":"isApprovedForAll","outputs":[{"name":"","type":"bool"}],"stateMutability":"view","type":"function"}
+]`
+
+var tokenCalls = mustParseABI(tokenCallsABI)
+
+// MulticallBackend - RPC методы, которые использует MulticallClient
+type MulticallBackend interface {
+	ethereum.ContractCaller
+	ethereum.ChainIDReader
+}
+
+// MulticallClient - Клиент для batch вызовов через Multicall3.
+// В сетях без известного адреса Multicall3 вызовы выполняются по одному через eth_call.
 type MulticallClient struct {
-	client        *ethclient.Client
-	multicallAddr common.Address
-	contractABI   abi.ABI
-	log           *logrus.Logger
+	backend          MulticallBackend
+	closer           func()
+	multicallAddr    *common.Address
+	contractABI      abi.ABI
+	maxCalldataBytes int
+	maxGasPerBatch   uint64
+	gasPerCall       uint64
+	log              *logrus.Entry
 }
 
 // NewMulticallClient - Создает новый клиент для Multicall контракта
-func NewMulticallClient(rpcURL string, log *logrus.Logger) (*MulticallClient, error) {
+func NewMulticallClient(rpcURL string, cfg *config.Config, log *logrus.Entry) (*MulticallClient, error) {
 	client, err := ethclient.Dial(rpcURL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
 	}
 
-	// Multicall контакт (MakerDAO) на Ethereum Mainnet
-	multicallAddr := common.HexToAddress("0x5e22962C96d7fC8b4e8a01D48D9ae490D309e2A7")
-
-	// ABI для Multicall контракта
-	multicallABI, err := abi.JSON(strings.NewReader(`[
-		{
-			"constant": true,
-			"inputs": [
-				{
-					"components": [
-						{
-							"name": "target",
-							"type": "address"
-						},
-						{
-							"name": "callData",
-							"type": "bytes"
-						}
-					],
-					"name": "calls",
-					"type": "tuple[]"
-				}
-			],
-			"name": "aggregate",
-			"outputs": [
-				{
-					"name": "blockNumber",
-					"type": "uint256"
-				},
-				{
-					"name": "returnData",
-					"type": "bytes[]"
-				}
-			],
-			"payable": false,
-			"stateMutability": "view",
-			"type": "function"
-		}
-	]`))
+	multicallClient, err := NewMulticallClientWithBackend(context.Background(), client, cfg, log)
 	if err != nil {
 		client.Close()
+		return nil, err
+	}
+	multicallClient.closer = client.Close
+
+	return multicallClient, nil
+}
+
+// NewMulticallClientWithBackend - Создает клиент поверх готового backend (используется в тестах и NodeClient)
+func NewMulticallClientWithBackend(ctx context.Context, backend MulticallBackend, cfg *config.Config, log *logrus.Entry) (*MulticallClient, error) {
+	logger := log.WithFields(logrus.Fields{"component": "multicall"})
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get chain id: %w", err)
+	}
+
+	multicallABI, err := abi.JSON(strings.NewReader(multicall3ABI))
+	if err != nil {
 		return nil, fmt.Errorf("failed to parse contract ABI: %w", err)
 	}
 
-	logger := log.WithFields(logrus.Fields{"component": "multicall"}).Logger
-	logger.Info("Successfully connected to Multicall contract")
+	client := &MulticallClient{
+		backend:          backend,
+		contractABI:      multicallABI,
+		maxCalldataBytes: cfg.Multicall.MaxCalldataBytes,
+		maxGasPerBatch:   cfg.Multicall.MaxGasPerBatch,
+		gasPerCall:       cfg.Multicall.GasPerCall,
+		log:              logger,
+	}
+	if client.maxCalldataBytes <= 0 {
+		client.maxCalldataBytes = defaultMulticallMaxCalldataBytes
+	}
+	if client.maxGasPerBatch == 0 {
+		client.maxGasPerBatch = defaultMulticallMaxGasPerBatch
+	}
+	if client.gasPerCall == 0 {
+		client.gasPerCall = defaultMulticallGasPerCall
+	}
+
+	// Адрес из конфига имеет приоритет над встроенной таблицей
+	addr, ok := cfg.Multicall.Addresses[chainID.Uint64()]
+	if !ok {
+		addr, ok = defaultMulticallAddresses[chainID.Uint64()]
+	}
+	if ok && addr != "" {
+		multicallAddr := common.HexToAddress(addr)
+		client.multicallAddr = &multicallAddr
+		logger.Infof("Using Multicall3 at %s on chain %d", multicallAddr.Hex(), chainID.Uint64())
+	} else {
+		logger.Warnf("No Multicall3 address for chain %d, calls will be executed one by one", chainID.Uint64())
+	}
 
-	return &MulticallClient{
-		client:        client,
-		multicallAddr: multicallAddr,
-		contractABI:   multicallABI,
-		log:           logger,
-	}, nil
+	return client, nil
 }
 
 // Call - Структура для одиночного вызова в batch
@@ -89,82 +149,187 @@ type Call struct {
 	CallData []byte         `json:"callData"`
 }
 
+// Call3 - Вызов для aggregate3 с признаком допустимости ошибки
+type Call3 struct {
+	Target       common.Address `json:"target"`
+	AllowFailure bool           `json:"allowFailure"`
+	CallData     []byte         `json:"callData"`
+}
+
 // CallResult - Результат вызова контракта
 type CallResult struct {
 	Success bool
 	Data    []byte
 }
 
-// Aggregate - Выполняет batch вызовы контрактов
+// Aggregate - Выполняет batch вызовы контрактов. Ошибка одного вызова не прерывает batch,
+// а отражается в CallResult.Success.
 func (c *MulticallClient) Aggregate(ctx context.Context, calls []Call) ([]CallResult, error) {
-	// Подготавливаем параметры для вызова
-	var args []interface{} = []interface{}{calls}
+	calls3 := make([]Call3, len(calls))
+	for i, call := range calls {
+		calls3[i] = Call3{Target: call.Target, AllowFailure: true, CallData: call.CallData}
+	}
+	return c.Aggregate3(ctx, calls3)
+}
 
-	// Кодируем данные для вызова контракта
-	data, err := c.contractABI.Pack("aggregate", args...)
+// TryAggregate - Выполняет tryAggregate: при requireSuccess ошибка любого вызова откатывает весь batch
+func (c *MulticallClient) TryAggregate(ctx context.Context, requireSuccess bool, calls []Call) ([]CallResult, error) {
+	calls3 := make([]Call3, len(calls))
+	for i, call := range calls {
+		calls3[i] = Call3{Target: call.Target, AllowFailure: !requireSuccess, CallData: call.CallData}
+	}
+
+	return c.execute(ctx, calls3, func(chunk []Call3) ([]byte, error) {
+		plain := make([]Call, len(chunk))
+		for i, call := range chunk {
+			plain[i] = Call{Target: call.Target, CallData: call.CallData}
+		}
+		return c.contractABI.Pack("tryAggregate", requireSuccess, plain)
+	}, "tryAggregate")
+}
+
+// Aggregate3 - Выполняет aggregate3; вызовы без AllowFailure при ошибке откатывают весь batch
+func (c *MulticallClient) Aggregate3(ctx context.Context, calls []Call3) ([]CallResult, error) {
+	return c.execute(ctx, calls, func(chunk []Call3) ([]byte, error) {
+		return c.contractABI.Pack("aggregate3", chunk)
+	}, "aggregate3")
+}
+
+// execute - Разбивает вызовы на batch по размеру calldata и газу и выполняет их
+func (c *MulticallClient) execute(ctx context.Context, calls []Call3, pack func([]Call3) ([]byte, error), method string) ([]CallResult, error) {
+	results := make([]CallResult, 0, len(calls))
+
+	for _, chunk := range c.chunkCalls(calls) {
+		var chunkResults []CallResult
+		var err error
+		if c.multicallAddr == nil {
+			chunkResults, err = c.executeSequential(ctx, chunk)
+		} else {
+			chunkResults, err = c.executeBatch(ctx, chunk, pack, method)
+		}
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, chunkResults...)
+	}
+
+	c.log.Debugf("Multicall executed %d calls", len(calls))
+	return results, nil
+}
+
+// executeBatch - Выполняет один batch через контракт Multicall3
+func (c *MulticallClient) executeBatch(ctx context.Context, chunk []Call3, pack func([]Call3) ([]byte, error), method string) ([]CallResult, error) {
+	data, err := pack(chunk)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode call data: %w", err)
 	}
 
-	// Выполняем статический вызов контракта
-	result, err := c.client.CallContract(ctx, ethereum.CallMsg{
-		To:   &c.multicallAddr,
+	result, err := c.backend.CallContract(ctx, ethereum.CallMsg{
+		To:   c.multicallAddr,
 		Data: data,
 	}, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to call contract: %w", err)
 	}
 
-	// Декодируем результат
-	var decodedResult struct {
-		BlockNumber *big.Int
-		ReturnData  [][]byte
-	}
-	err = c.contractABI.UnpackIntoInterface(&decodedResult, "aggregate", result)
+	out, err := c.contractABI.Unpack(method, result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to decode contract call result: %w", err)
 	}
 
-	// Преобразуем в CallResult
-	callResults := make([]CallResult, len(decodedResult.ReturnData))
-	for i, data := range decodedResult.ReturnData {
-		// Проверка на успешность вызова
-		// Для простоты считаем что все вызовы успешные, если они вернули данные
-		// В реальной реализации нужно добавить проверку на ошибки
-		success := true
-		if len(data) == 0 {
-			success = false
+	decoded := *abi.ConvertType(out[0], new([]struct {
+		Success    bool
+		ReturnData []byte
+	})).(*[]struct {
+		Success    bool
+		ReturnData []byte
+	})
+	if len(decoded) != len(chunk) {
+		return nil, fmt.Errorf("multicall returned %d results for %d calls", len(decoded), len(chunk))
+	}
+
+	results := make([]CallResult, len(decoded))
+	for i, r := range decoded {
+		results[i] = CallResult{Success: r.Success, Data: r.ReturnData}
+	}
+	return results, nil
+}
+
+// executeSequential - Выполняет вызовы по одному (сети без Multicall3)
+func (c *MulticallClient) executeSequential(ctx context.Context, chunk []Call3) ([]CallResult, error) {
+	results := make([]CallResult, len(chunk))
+	for i, call := range chunk {
+		target := call.Target
+		data, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &target, Data: call.CallData}, nil)
+		if err != nil {
+			if !call.AllowFailure {
+				return nil, fmt.Errorf("call to %s failed: %w", target.Hex(), err)
+			}
+			continue
 		}
+		results[i] = CallResult{Success: true, Data: data}
+	}
+	return results, nil
+}
 
-		callResults[i] = CallResult{
-			Success: success,
-			Data:    data,
+// chunkCalls - Разбивает вызовы на batch, не превышающие maxCalldataBytes и maxGasPerBatch
+func (c *MulticallClient) chunkCalls(calls []Call3) [][]Call3 {
+	var chunks [][]Call3
+	var current []Call3
+	var size int
+	var gas uint64
+
+	for _, call := range calls {
+		callSize := len(call.CallData) + multicallCallOverhead
+		if len(current) > 0 && (size+callSize > c.maxCalldataBytes || gas+c.gasPerCall > c.maxGasPerBatch) {
+			chunks = append(chunks, current)
+			current, size, gas = nil, 0, 0
 		}
+		current = append(current, call)
+		size += callSize
+		gas += c.gasPerCall
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, current)
 	}
 
-	c.log.Debugf("Multicall executed %d calls", len(calls))
-	return callResults, nil
+	return chunks
 }
 
-// GetAllowances - Получает allowances для нескольких токенов за один запрос
-func (c *MulticallClient) GetAllowances(ctx context.Context, owner common.Address, spender common.Address, tokenAddresses []common.Address) (map[string]*big.Int, error) {
-	// ABI для метода allowance на ERC20 токене
-	const erc20AllowanceMethod = "allowance(address,address)"
-	const erc20AllowanceMethodID = "0xdd62ed3e"
+// GetBalances - Получает balanceOf(owner) для нескольких токенов за один запрос
+func (c *MulticallClient) GetBalances(ctx context.Context, owner common.Address, tokenAddresses []common.Address) (map[common.Address]*big.Int, error) {
+	calls := make([]Call, len(tokenAddresses))
+	for i, token := range tokenAddresses {
+		calls[i] = Call{Target: token, CallData: PackBalanceOf(owner)}
+	}
 
-	// Подготавливаем вызовы для каждого токена
-	var calls []Call
-	for _, tokenAddr := range tokenAddresses {
-		// Кодируем параметры вызова allowance для каждого токена
-		data := []byte(erc20AllowanceMethodID)
-		// Кодируем owner и spender как 32-byte адреса
-		data = append(data, common.LeftPadBytes(owner.Bytes(), 32)...)
-		data = append(data, common.LeftPadBytes(spender.Bytes(), 32)...)
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute batch call: %w", err)
+	}
 
-		calls = append(calls, Call{
-			Target:   tokenAddr,
-			CallData: data,
-		})
+	balances := make(map[common.Address]*big.Int, len(tokenAddresses))
+	for i, token := range tokenAddresses {
+		if !results[i].Success {
+			c.log.Debugf("balanceOf call for token %s failed", token.Hex())
+			continue
+		}
+		balance, err := DecodeUint256(results[i].Data)
+		if err != nil {
+			c.log.Debugf("Unexpected balanceOf result for token %s: %v", token.Hex(), err)
+			continue
+		}
+		balances[token] = balance
+	}
+
+	return balances, nil
+}
+
+// GetAllowances - Получает allowances для нескольких токенов за один запрос
+func (c *MulticallClient) GetAllowances(ctx context.Context, owner common.Address, spender common.Address, tokenAddresses []common.Address) (map[string]*big.Int, error) {
+	calls := make([]Call, len(tokenAddresses))
+	for i, token := range tokenAddresses {
+		calls[i] = Call{Target: token, CallData: PackAllowance(owner, spender)}
 	}
 
 	// Выполняем batch вызов
@@ -181,13 +346,11 @@ func (c *MulticallClient) GetAllowances(ctx context.Context, owner common.Addres
 			continue
 		}
 
-		// Результат вызова должен быть uint256 (32 байта)
-		if len(results[i].Data) != 32 {
-			c.log.Warnf("Unexpected data length for token %s: %d bytes", tokenAddr.Hex(), len(results[i].Data))
+		allowance, err := DecodeUint256(results[i].Data)
+		if err != nil {
+			c.log.Warnf("Unexpected allowance result for token %s: %v", tokenAddr.Hex(), err)
 			continue
 		}
-
-		allowance := new(big.Int).SetBytes(results[i].Data)
 		allowances[tokenAddr.Hex()] = allowance
 	}
 
@@ -195,9 +358,137 @@ func (c *MulticallClient) GetAllowances(ctx context.Context, owner common.Addres
 	return allowances, nil
 }
 
+// GetDecimals - Получает decimals для нескольких токенов за один запрос
+func (c *MulticallClient) GetDecimals(ctx context.Context, tokenAddresses []common.Address) (map[common.Address]uint8, error) {
+	calls := make([]Call, len(tokenAddresses))
+	for i, token := range tokenAddresses {
+		calls[i] = Call{Target: token, CallData: PackDecimals()}
+	}
+
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute batch call: %w", err)
+	}
+
+	decimals := make(map[common.Address]uint8, len(tokenAddresses))
+	for i, token := range tokenAddresses {
+		if !results[i].Success {
+			continue
+		}
+		if d, err := DecodeDecimals(results[i].Data); err == nil {
+			decimals[token] = d
+		}
+	}
+
+	return decimals, nil
+}
+
+// OperatorApproval - Пара (коллекция, оператор) для проверки isApprovedForAll
+type OperatorApproval struct {
+	Collection common.Address
+	Operator   common.Address
+}
+
+// GetApprovalsForAll - Проверяет isApprovedForAll(owner, operator) для нескольких пар за один запрос
+func (c *MulticallClient) GetApprovalsForAll(ctx context.Context, owner common.Address, pairs []OperatorApproval) (map[OperatorApproval]bool, error) {
+	calls := make([]Call, len(pairs))
+	for i, pair := range pairs {
+		calls[i] = Call{Target: pair.Collection, CallData: PackIsApprovedForAll(owner, pair.Operator)}
+	}
+
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute batch call: %w", err)
+	}
+
+	approvals := make(map[OperatorApproval]bool, len(pairs))
+	for i, pair := range pairs {
+		if !results[i].Success {
+			continue
+		}
+		if approved, err := DecodeBool(results[i].Data); err == nil {
+			approvals[pair] = approved
+		}
+	}
+
+	return approvals, nil
+}
+
+// CallContract - Выполняет одиночный eth_call (для вызовов, которые нельзя выполнить через Multicall)
+func (c *MulticallClient) CallContract(ctx context.Context, target common.Address, data []byte) ([]byte, error) {
+	return c.backend.CallContract(ctx, ethereum.CallMsg{To: &target, Data: data}, nil)
+}
+
+// PackBalanceOf - Кодирует вызов balanceOf(owner)
+func PackBalanceOf(owner common.Address) []byte {
+	data, _ := tokenCalls.Pack("balanceOf", owner)
+	return data
+}
+
+// PackAllowance - Кодирует вызов allowance(owner, spender)
+func PackAllowance(owner, spender common.Address) []byte {
+	data, _ := tokenCalls.Pack("allowance", owner, spender)
+	return data
+}
+
+// PackDecimals - Кодирует вызов decimals()
+func PackDecimals() []byte {
+	data, _ := tokenCalls.Pack("decimals")
+	return data
+}
+
+// PackIsApprovedForAll - Кодирует вызов isApprovedForAll(owner, operator)
+func PackIsApprovedForAll(owner, operator common.Address) []byte {
+	data, _ := tokenCalls.Pack("isApprovedForAll", owner, operator)
+	return data
+}
+
+// DecodeUint256 - Декодирует uint256 результат (balanceOf, allowance)
+func DecodeUint256(data []byte) (*big.Int, error) {
+	if len(data) != 32 {
+		return nil, fmt.Errorf("unexpected data length: %d bytes", len(data))
+	}
+	return new(big.Int).SetBytes(data), nil
+}
+
+// DecodeDecimals - Декодирует результат decimals()
+func DecodeDecimals(data []byte) (uint8, error) {
+	value, err := DecodeUint256(data)
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsUint64() || value.Uint64() > 255 {
+		return 0, fmt.Errorf("decimals out of range: %s", value.String())
+	}
+	return uint8(value.Uint64()), nil
+}
+
+// DecodeBool - Декодирует bool результат (isApprovedForAll)
+func DecodeBool(data []byte) (bool, error) {
+	value, err := DecodeUint256(data)
+	if err != nil {
+		return false, err
+	}
+	if value.Cmp(big.NewInt(1)) > 0 {
+		return false, fmt.Errorf("invalid bool value: %s", value.String())
+	}
+	return value.Sign() == 1, nil
+}
+
+// mustParseABI - Разбирает встроенный ABI, паникует при ошибке в исходном коде
+func mustParseABI(definition string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(definition))
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in ABI: %v", err))
+	}
+	return parsed
+}
+
 // Close - Закрывает подключение к RPC
 func (c *MulticallClient) Close() error {
-	c.client.Close()
+	if c.closer != nil {
+		c.closer()
+	}
 	c.log.Info("Multicall RPC connection closed")
 	return nil
 }
@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/testutil/evmtest"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMulticall3 - Backend, исполняющий aggregate3 в памяти: ответы задаются по target
+type fakeMulticall3 struct {
+	t         *testing.T
+	chainID   int64
+	responses map[common.Address][]byte
+	batches   int
+}
+
+func (f *fakeMulticall3) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(f.chainID), nil
+}
+
+func (f *fakeMulticall3) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	parsed := mustParseABI(multicall3ABI)
+	method := parsed.Methods["aggregate3"]
+	require.Equal(f.t, common.HexToAddress(multicall3Address), *msg.To)
+	require.Equal(f.t, method.ID, msg.Data[:4])
+
+	args, err := method.Inputs.Unpack(msg.Data[4:])
+	require.NoError(f.t, err)
+	calls := *abi.ConvertType(args[0], new([]Call3)).(*[]Call3)
+
+	type result struct {
+		Success    bool
+		ReturnData []byte
+	}
+	results := make([]result, len(calls))
+	for i, call := range calls {
+		data, ok := f.responses[call.Target]
+		if !ok && !call.AllowFailure {
+			return nil, errors.New("execution reverted: Multicall3: call failed")
+		}
+		results[i] = result{Success: ok, ReturnData: data}
+	}
+	f.batches++
+
+	return method.Outputs.Pack(results)
+}
+
+func newTestMulticall(t *testing.T, backend MulticallBackend, cfg *config.Config) *MulticallClient {
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+
+	client, err := NewMulticallClientWithBackend(context.Background(), backend, cfg, log.WithContext(context.Background()))
+	require.NoError(t, err)
+	return client
+}
+
+func TestMulticall_Aggregate3AllowFailure(t *testing.T) {
+	good := common.HexToAddress("0x01")
+	bad := common.HexToAddress("0x02")
+	backend := &fakeMulticall3{t: t, chainID: 1, responses: map[common.Address][]byte{
+		good: evmtest.Word(big.NewInt(42)).Bytes(),
+	}}
+	client := newTestMulticall(t, backend, &config.Config{})
+
+	balances, err := client.GetBalances(context.Background(), testWallet, []common.Address{good, bad})
+	require.NoError(t, err)
+	assert.Equal(t, map[common.Address]*big.Int{good: big.NewInt(42)}, balances)
+
+	// Без allowFailure ошибка одного вызова откатывает весь batch
+	_, err = client.Aggregate3(context.Background(), []Call3{
+		{Target: good, CallData: PackDecimals()},
+		{Target: bad, CallData: PackDecimals()},
+	})
+	assert.Error(t, err)
+}
+
+func TestMulticall_Chunking(t *testing.T) {
+	backend := &fakeMulticall3{t: t, chainID: 1, responses: map[common.Address][]byte{}}
+
+	cfg := &config.Config{}
+	cfg.Multicall.GasPerCall = 100
+	cfg.Multicall.MaxGasPerBatch = 300
+	client := newTestMulticall(t, backend, cfg)
+
+	calls := make([]Call, 7)
+	for i := range calls {
+		calls[i] = Call{Target: common.BigToAddress(big.NewInt(int64(i + 1))), CallData: PackDecimals()}
+	}
+	results, err := client.Aggregate(context.Background(), calls)
+	require.NoError(t, err)
+	assert.Len(t, results, 7)
+	assert.Equal(t, 3, backend.batches)
+
+	// Предел по размеру calldata
+	cfg.Multicall.MaxGasPerBatch = 0
+	cfg.Multicall.MaxCalldataBytes = 2 * (len(PackDecimals()) + multicallCallOverhead)
+	client = newTestMulticall(t, backend, cfg)
+	assert.Len(t, client.chunkCalls(make([]Call3, 5)), 3)
+}
+
+func TestMulticall_SequentialFallback(t *testing.T) {
+	chain := evmtest.NewChain(t, types.GenesisAlloc{
+		testToken: {
+			Code: evmtest.MockContract,
+			Storage: map[common.Hash]common.Hash{
+				evmtest.StorageKey(PackBalanceOf(testWallet)):                     evmtest.Word(big.NewInt(1500000)),
+				evmtest.StorageKey(PackDecimals()):                                evmtest.Word(big.NewInt(6)),
+				evmtest.StorageKey(PackIsApprovedForAll(testWallet, testRouter)):  evmtest.Word(big.NewInt(1)),
+				evmtest.StorageKey(PackIsApprovedForAll(testWallet, testRevoked)): evmtest.Word(big.NewInt(0)),
+			},
+		},
+	})
+	// Simulated сеть (chain id 1337) отсутствует в таблице Multicall3
+	client := newTestMulticall(t, chain.Client, &config.Config{})
+	require.Nil(t, client.multicallAddr)
+
+	// Вызов адреса без кода возвращает пустой ответ и пропускается
+	eoa := common.HexToAddress("0xdead")
+	balances, err := client.GetBalances(context.Background(), testWallet, []common.Address{testToken, eoa})
+	require.NoError(t, err)
+	assert.Equal(t, map[common.Address]*big.Int{testToken: big.NewInt(1500000)}, balances)
+
+	decimals, err := client.GetDecimals(context.Background(), []common.Address{testToken})
+	require.NoError(t, err)
+	assert.Equal(t, uint8(6), decimals[testToken])
+
+	router := OperatorApproval{Collection: testToken, Operator: testRouter}
+	revoked := OperatorApproval{Collection: testToken, Operator: testRevoked}
+	approvals, err := client.GetApprovalsForAll(context.Background(), testWallet, []OperatorApproval{router, revoked})
+	require.NoError(t, err)
+	assert.True(t, approvals[router])
+	assert.False(t, approvals[revoked])
+}
+
+func TestMulticall_DecodeHelpers(t *testing.T) {
+	_, err := DecodeUint256(nil)
+	assert.Error(t, err)
+
+	_, err = DecodeDecimals(evmtest.Word(big.NewInt(256)).Bytes())
+	assert.Error(t, err)
+
+	_, err = DecodeBool(evmtest.Word(big.NewInt(2)).Bytes())
+	assert.Error(t, err)
+
+	assert.Equal(t, "dd62ed3e", common.Bytes2Hex(PackAllowance(testWallet, testRouter)[:4]))
+	assert.Equal(t, "e985e9c5", common.Bytes2Hex(PackIsApprovedForAll(testWallet, testRouter)[:4]))
+}
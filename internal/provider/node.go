@@ -0,0 +1,486 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultLogChunkSize - Размер диапазона блоков для одного eth_getLogs
+	defaultLogChunkSize = 10000
+)
+
+var (
+	// transferEventTopic - keccak256("Transfer(address,address,uint256)")
+	transferEventTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	// approvalEventTopic - keccak256("Approval(address,address,uint256)")
+	approvalEventTopic = crypto.Keccak256Hash([]byte("Approval(address,address,uint256)"))
+	// unlimitedAllowanceThreshold - Allowance от 2^255 считается неограниченным (как у GoPlus)
+	unlimitedAllowanceThreshold = new(big.Int).Lsh(big.NewInt(1), 255)
+)
+
+// erc20ABI - Минимальный ABI ERC-20 для чтения балансов, allowance и метаданных
+const erc20ABI = `[
+	{"constant":true,"inputs":[{"name":"owner","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[{"name":"owner","type":"address"},{"name":"spender","type":"address"}],"name":"allowance","outputs":[{"name":"","type":"uint256"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"decimals","outputs":[{"name":"","type":"uint8"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"symbol","outputs":[{"name":"","type":"string"}],"type":"function"},
+	{"constant":true,"inputs":[],"name":"name","outputs":[{"name":"","type":"string"}],"type":"function"}
+]`
+
+// NodeBackend - RPC методы узла, которые использует NodeClient.
+// Реализуется *ethclient.Client и simulated backend из go-ethereum.
+type NodeBackend interface {
+	ethereum.BlockNumberReader
+	ethereum.ChainIDReader
+	ethereum.ChainStateReader
+	ethereum.ContractCaller
+	ethereum.LogFilterer
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// NodeClient - Провайдер данных поверх собственного (архивного) JSON-RPC узла
+type NodeClient struct {
+	backend      NodeBackend
+	closer       func()
+	multicall    *MulticallClient
+	erc20        abi.ABI
+	startBlock   uint64
+	logChunkSize uint64
+	log          *logrus.Entry
+}
+
+// NewNodeClient - Подключается к узлу по cfg.Node.URL
+func NewNodeClient(cfg *config.Config, log *logrus.Entry) (*NodeClient, error) {
+	if cfg.Node.URL == "" {
+		return nil, fmt.Errorf("node RPC URL is not configured")
+	}
+
+	client, err := ethclient.Dial(cfg.Node.URL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to RPC: %w", err)
+	}
+
+	nodeClient, err := NewNodeClientWithBackend(client, cfg, log)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	nodeClient.closer = client.Close
+
+	return nodeClient, nil
+}
+
+// NewNodeClientWithBackend - Создает NodeClient поверх готового backend (используется в тестах)
+func NewNodeClientWithBackend(backend NodeBackend, cfg *config.Config, log *logrus.Entry) (*NodeClient, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(erc20ABI))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse ERC20 ABI: %w", err)
+	}
+
+	chunkSize := cfg.Node.LogChunkSize
+	if chunkSize == 0 {
+		chunkSize = defaultLogChunkSize
+	}
+
+	multicall, err := NewMulticallClientWithBackend(context.Background(), backend, cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	logger := log.WithFields(logrus.Fields{"component": "node"})
+	return &NodeClient{
+		backend:      backend,
+		multicall:    multicall,
+		erc20:        parsedABI,
+		startBlock:   cfg.Node.StartBlock,
+		logChunkSize: chunkSize,
+		log:          logger,
+	}, nil
+}
+
+// GetETHBalance - Получает баланс ETH для адреса (в ETH)
+func (c *NodeClient) GetETHBalance(ctx context.Context, address string) (float64, error) {
+	wei, err := c.backend.BalanceAt(ctx, common.HexToAddress(address), nil)
+	if err != nil {
+		c.log.Errorf("Failed to get balance: %v", err)
+		return 0, err
+	}
+
+	eth, _ := new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Float64()
+	return eth, nil
+}
+
+// Multicall - Multicall клиент поверх того же узла
+func (c *NodeClient) Multicall() *MulticallClient {
+	return c.multicall
+}
+
+// GetCode - Получает код аккаунта на последнем блоке
+func (c *NodeClient) GetCode(ctx context.Context, address string) ([]byte, error) {
+	return c.backend.CodeAt(ctx, common.HexToAddress(address), nil)
+}
+
+// GetERC20Tokens - Получает ERC-20 токены кошелька.
+// Контракты находятся по входящим Transfer событиям, балансы читаются через balanceOf одним Multicall3 запросом.
+func (c *NodeClient) GetERC20Tokens(ctx context.Context, address string) ([]*TokenBalance, error) {
+	owner := common.HexToAddress(address)
+
+	logs, err := c.filterLogs(ctx, nil, [][]common.Hash{
+		{transferEventTopic},
+		{},
+		{common.BytesToHash(owner.Bytes())},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Transfer logs: %w", err)
+	}
+
+	var contracts []common.Address
+	seen := make(map[common.Address]struct{})
+	for _, l := range logs {
+		// ERC-721 Transfer имеет 4 топика (tokenId индексирован)
+		if len(l.Topics) != 3 {
+			continue
+		}
+		if _, ok := seen[l.Address]; ok {
+			continue
+		}
+		seen[l.Address] = struct{}{}
+		contracts = append(contracts, l.Address)
+	}
+
+	balances, err := c.multicall.GetBalances(ctx, owner, contracts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token balances: %w", err)
+	}
+
+	var result []*TokenBalance
+	for _, token := range contracts {
+		balance, ok := balances[token]
+		if !ok || balance.Sign() == 0 {
+			continue
+		}
+
+		name, symbol, decimals := c.tokenMetadata(ctx, token)
+		result = append(result, &TokenBalance{
+			Account:         address,
+			ContractAddress: strings.ToLower(token.Hex()),
+			TokenName:       name,
+			TokenSymbol:     symbol,
+			TokenDecimal:    fmt.Sprintf("%d", decimals),
+			Balance:         balance.String(),
+		})
+	}
+
+	c.log.Debugf("Found %d ERC20 tokens with positive balance out of %d discovered contracts", len(result), len(contracts))
+	return result, nil
+}
+
+// GetTokenApprovals - Восстанавливает действующие ERC-20 approvals по событиям Approval.
+// Для каждой пары (токен, spender) берется последнее событие, затем текущее значение
+// сверяется с allowance(owner, spender) - его могли частично израсходовать через transferFrom.
+// Ответ имеет ту же форму, что и у GoPlus, но без сведений о spender (AddressInfo).
+func (c *NodeClient) GetTokenApprovals(ctx context.Context, address string) (*TokenApprovalResponse, error) {
+	owner := common.HexToAddress(address)
+
+	logs, err := c.filterLogs(ctx, nil, [][]common.Hash{
+		{approvalEventTopic},
+		{common.BytesToHash(owner.Bytes())},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Approval logs: %w", err)
+	}
+
+	type approvalKey struct {
+		token   common.Address
+		spender common.Address
+	}
+	type approvalState struct {
+		firstBlock uint64
+		lastBlock  uint64
+		firstTx    common.Hash
+		lastTx     common.Hash
+	}
+
+	// Логи отсортированы по блоку и индексу - последнее событие перезаписывает предыдущие
+	states := make(map[approvalKey]*approvalState)
+	var order []approvalKey
+	for _, l := range logs {
+		if len(l.Topics) != 3 {
+			continue
+		}
+		key := approvalKey{token: l.Address, spender: common.BytesToAddress(l.Topics[2].Bytes())}
+		state, ok := states[key]
+		if !ok {
+			state = &approvalState{firstBlock: l.BlockNumber, firstTx: l.TxHash}
+			states[key] = state
+			order = append(order, key)
+		}
+		state.lastBlock = l.BlockNumber
+		state.lastTx = l.TxHash
+		// Отзыв (approve на 0) сбрасывает историю выдачи
+		if new(big.Int).SetBytes(l.Data).Sign() == 0 {
+			state.firstBlock = 0
+		} else if state.firstBlock == 0 {
+			state.firstBlock = l.BlockNumber
+			state.firstTx = l.TxHash
+		}
+	}
+
+	byToken := make(map[common.Address]*TokenApproval)
+	var tokens []common.Address
+	blockTimes := make(map[uint64]int64)
+
+	for _, key := range order {
+		state := states[key]
+		if state.firstBlock == 0 {
+			continue
+		}
+
+		allowance, err := c.callUint(ctx, key.token, "allowance", owner, key.spender)
+		if err != nil {
+			c.log.Warnf("Failed to get allowance for token %s: %v", key.token.Hex(), err)
+			continue
+		}
+		if allowance.Sign() == 0 {
+			continue
+		}
+
+		approval, ok := byToken[key.token]
+		if !ok {
+			name, symbol, decimals := c.tokenMetadata(ctx, key.token)
+			balance, err := c.callUint(ctx, key.token, "balanceOf", owner)
+			if err != nil {
+				balance = new(big.Int)
+			}
+			approval = &TokenApproval{
+				TokenAddress: strings.ToLower(key.token.Hex()),
+				TokenName:    name,
+				TokenSymbol:  symbol,
+				Decimals:     int(decimals),
+				Balance:      balance.String(),
+			}
+			byToken[key.token] = approval
+			tokens = append(tokens, key.token)
+		}
+
+		amount := allowance.String()
+		if allowance.Cmp(unlimitedAllowanceThreshold) >= 0 {
+			amount = "Unlimited"
+		}
+
+		approval.ApprovedList = append(approval.ApprovedList, ApprovedSpender{
+			ApprovedContract:    strings.ToLower(key.spender.Hex()),
+			ApprovedAmount:      amount,
+			ApprovedTime:        c.blockTime(ctx, state.lastBlock, blockTimes),
+			InitialApprovalTime: c.blockTime(ctx, state.firstBlock, blockTimes),
+			InitialApprovalHash: state.firstTx.Hex(),
+			Hash:                state.lastTx.Hex(),
+		})
+	}
+
+	result := &TokenApprovalResponse{Code: goPlusCodeOK, Message: "OK"}
+	for _, token := range tokens {
+		result.Result = append(result.Result, *byToken[token])
+	}
+
+	c.log.Debugf("Reconstructed %d tokens with active approvals from %d Approval events", len(result.Result), len(logs))
+	return result, nil
+}
+
+// GetPermit2Allowances - Восстанавливает allowance владельца внутри Permit2 по событиям контракта
+// и сверяет остаток и срок с allowance(owner, token, spender) одним batch через Multicall3.
+// Если сверка не удалась, возвращаются значения из событий.
+func (c *NodeClient) GetPermit2Allowances(ctx context.Context, owner string) ([]Permit2Allowance, error) {
+	ownerAddr := common.HexToAddress(owner)
+
+	logs, err := c.filterLogs(ctx, []common.Address{permit2Contract}, permit2Topics(ownerAddr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Permit2 logs: %w", err)
+	}
+
+	blockTimes := make(map[uint64]int64)
+	allowances := reconstructPermit2Allowances(logs, func(number uint64) int64 {
+		return c.blockTime(ctx, number, blockTimes)
+	})
+	if len(allowances) == 0 {
+		return nil, nil
+	}
+
+	calls := make([]Call, len(allowances))
+	for i, a := range allowances {
+		calls[i] = Call{
+			Target:   permit2Contract,
+			CallData: PackPermit2Allowance(ownerAddr, common.HexToAddress(a.Token), common.HexToAddress(a.Spender)),
+		}
+	}
+	results, err := c.multicall.Aggregate(ctx, calls)
+	if err != nil {
+		c.log.Warnf("Failed to read Permit2 allowances, using values from events: %v", err)
+		return allowances, nil
+	}
+
+	current := allowances[:0]
+	for i, a := range allowances {
+		if results[i].Success {
+			amount, expiration, nonce, err := DecodePermit2Allowance(results[i].Data)
+			if err == nil {
+				if amount.Sign() == 0 {
+					continue
+				}
+				a.Amount = formatPermit2Amount(amount)
+				a.Expiration = expiration
+				a.Nonce = nonce
+			}
+		}
+		current = append(current, a)
+	}
+
+	c.log.Debugf("Reconstructed %d Permit2 allowances from %d events", len(current), len(logs))
+	return current, nil
+}
+
+// filterLogs - Выполняет eth_getLogs по диапазону [startBlock, latest] кусками по logChunkSize блоков.
+// Пустой addresses - события любых контрактов. Если узел отклоняет диапазон, кусок уменьшается вдвое.
+func (c *NodeClient) filterLogs(ctx context.Context, addresses []common.Address, topics [][]common.Hash) ([]types.Log, error) {
+	latest, err := c.backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest block: %w", err)
+	}
+
+	var logs []types.Log
+	chunk := c.logChunkSize
+	for from := c.startBlock; from <= latest; {
+		to := min(from+chunk-1, latest)
+
+		chunkLogs, err := c.backend.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(from),
+			ToBlock:   new(big.Int).SetUint64(to),
+			Addresses: addresses,
+			Topics:    topics,
+		})
+		if err != nil {
+			if ctx.Err() != nil || chunk == 1 {
+				return nil, err
+			}
+			chunk /= 2
+			c.log.Debugf("eth_getLogs for blocks %d-%d failed (%v), retrying with chunk size %d", from, to, err, chunk)
+			continue
+		}
+
+		logs = append(logs, chunkLogs...)
+		from = to + 1
+	}
+
+	sort.SliceStable(logs, func(i, j int) bool {
+		if logs[i].BlockNumber != logs[j].BlockNumber {
+			return logs[i].BlockNumber < logs[j].BlockNumber
+		}
+		return logs[i].Index < logs[j].Index
+	})
+
+	return logs, nil
+}
+
+// callUint - Вызывает view метод ERC-20, возвращающий uint256
+func (c *NodeClient) callUint(ctx context.Context, token common.Address, method string, args ...interface{}) (*big.Int, error) {
+	out, err := c.call(ctx, token, method, args...)
+	if err != nil {
+		return nil, err
+	}
+	value, ok := out[0].(*big.Int)
+	if !ok {
+		return nil, fmt.Errorf("unexpected %s result type %T", method, out[0])
+	}
+	return value, nil
+}
+
+// call - Кодирует и выполняет eth_call метода ERC-20 и декодирует результат
+func (c *NodeClient) call(ctx context.Context, token common.Address, method string, args ...interface{}) ([]interface{}, error) {
+	data, err := c.erc20.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.erc20.Unpack(method, result)
+	if err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, fmt.Errorf("empty %s result", method)
+	}
+	return out, nil
+}
+
+// tokenMetadata - Читает name, symbol и decimals токена; ошибки не критичны.
+// Некоторые старые токены (например, MKR) возвращают bytes32 вместо string.
+func (c *NodeClient) tokenMetadata(ctx context.Context, token common.Address) (name, symbol string, decimals uint8) {
+	decimals = 18
+	if out, err := c.call(ctx, token, "decimals"); err == nil {
+		if d, ok := out[0].(uint8); ok {
+			decimals = d
+		}
+	}
+	name = c.callString(ctx, token, "name")
+	symbol = c.callString(ctx, token, "symbol")
+	return name, symbol, decimals
+}
+
+// callString - Читает строковое свойство токена с поддержкой bytes32
+func (c *NodeClient) callString(ctx context.Context, token common.Address, method string) string {
+	if out, err := c.call(ctx, token, method); err == nil {
+		if s, ok := out[0].(string); ok {
+			return s
+		}
+	}
+
+	data, err := c.erc20.Pack(method)
+	if err != nil {
+		return ""
+	}
+	raw, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &token, Data: data}, nil)
+	if err != nil || len(raw) != 32 {
+		return ""
+	}
+	return strings.TrimRight(string(raw), "\x00")
+}
+
+// blockTime - Возвращает timestamp блока с кэшированием в пределах одного запроса
+func (c *NodeClient) blockTime(ctx context.Context, number uint64, cache map[uint64]int64) int64 {
+	if ts, ok := cache[number]; ok {
+		return ts
+	}
+	header, err := c.backend.HeaderByNumber(ctx, new(big.Int).SetUint64(number))
+	if err != nil {
+		c.log.Warnf("Failed to get header of block %d: %v", number, err)
+		return 0
+	}
+	cache[number] = int64(header.Time)
+	return cache[number]
+}
+
+// Close - Закрывает подключение к RPC
+func (c *NodeClient) Close() error {
+	if c.closer != nil {
+		c.closer()
+	}
+	return nil
+}
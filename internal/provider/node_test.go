@@ -0,0 +1,133 @@
+package provider
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/testutil/evmtest"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testWallet  = common.HexToAddress("0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	testToken   = common.HexToAddress("0x00000000000000000000000000000000000a11ce")
+	testRouter  = common.HexToAddress("0x68b3465833fb72a70ecdf485e0e4c7bd8665fc45")
+	testRevoked = common.HexToAddress("0x1111111254fb6c44bac0bed2854e76f90643097d")
+)
+
+// mockTokenStorage - Ответы view методов ERC-20 для MockContract
+func mockTokenStorage(t *testing.T, values map[string]common.Hash) map[common.Hash]common.Hash {
+	parsed, err := abi.JSON(strings.NewReader(erc20ABI))
+	require.NoError(t, err)
+
+	storage := make(map[common.Hash]common.Hash)
+	for call, value := range values {
+		var data []byte
+		switch call {
+		case "balanceOf":
+			data, err = parsed.Pack("balanceOf", testWallet)
+		case "allowance:router":
+			data, err = parsed.Pack("allowance", testWallet, testRouter)
+		default:
+			data, err = parsed.Pack(call)
+		}
+		require.NoError(t, err)
+		storage[evmtest.StorageKey(data)] = value
+	}
+	return storage
+}
+
+func newTestNodeClient(t *testing.T) (*NodeClient, *evmtest.Chain) {
+	unlimited := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1))
+
+	chain := evmtest.NewChain(t, types.GenesisAlloc{
+		testWallet: {Balance: new(big.Int).Mul(big.NewInt(25), big.NewInt(1e18))},
+		testToken: {
+			Code: evmtest.MockContract,
+			Storage: mockTokenStorage(t, map[string]common.Hash{
+				"balanceOf":        evmtest.Word(big.NewInt(1500000)),
+				"allowance:router": evmtest.Word(unlimited),
+				"decimals":         evmtest.Word(big.NewInt(6)),
+				"symbol":           common.BytesToHash(common.RightPadBytes([]byte("TKN"), 32)),
+			}),
+		},
+	})
+
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+
+	cfg := &config.Config{}
+	cfg.Node.LogChunkSize = 2
+
+	client, err := NewNodeClientWithBackend(chain.Client, cfg, log.WithContext(context.Background()))
+	require.NoError(t, err)
+
+	return client, chain
+}
+
+func TestNodeClient_GetETHBalance(t *testing.T) {
+	client, _ := newTestNodeClient(t)
+
+	balance, err := client.GetETHBalance(context.Background(), testWallet.Hex())
+	require.NoError(t, err)
+	assert.InDelta(t, 25.0, balance, 1e-9)
+}
+
+func TestNodeClient_GetERC20Tokens(t *testing.T) {
+	client, chain := newTestNodeClient(t)
+	from := evmtest.AddressTopic(common.HexToAddress("0xdead"))
+	to := evmtest.AddressTopic(testWallet)
+
+	// Несколько блоков, чтобы eth_getLogs выполнялся кусками
+	chain.Emit(testToken, transferEventTopic, from, to, evmtest.Word(big.NewInt(1000000)))
+	chain.Backend.Commit()
+	chain.Emit(testToken, transferEventTopic, from, to, evmtest.Word(big.NewInt(500000)))
+	chain.Backend.Commit()
+
+	tokens, err := client.GetERC20Tokens(context.Background(), testWallet.Hex())
+	require.NoError(t, err)
+	require.Len(t, tokens, 1)
+
+	assert.Equal(t, strings.ToLower(testToken.Hex()), tokens[0].ContractAddress)
+	assert.Equal(t, "1500000", tokens[0].Balance)
+	assert.Equal(t, "6", tokens[0].TokenDecimal)
+	assert.Equal(t, "TKN", tokens[0].TokenSymbol)
+}
+
+func TestNodeClient_GetTokenApprovals(t *testing.T) {
+	client, chain := newTestNodeClient(t)
+	owner := evmtest.AddressTopic(testWallet)
+	unlimited := evmtest.Word(new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)))
+
+	chain.Emit(testToken, approvalEventTopic, owner, evmtest.AddressTopic(testRouter), unlimited)
+	// Approve и последующий отзыв - не должен попасть в результат
+	chain.Emit(testToken, approvalEventTopic, owner, evmtest.AddressTopic(testRevoked), unlimited)
+	chain.Emit(testToken, approvalEventTopic, owner, evmtest.AddressTopic(testRevoked), evmtest.Word(big.NewInt(0)))
+	// Approval чужого кошелька
+	chain.Emit(testToken, approvalEventTopic, evmtest.AddressTopic(testRouter), owner, unlimited)
+
+	resp, err := client.GetTokenApprovals(context.Background(), testWallet.Hex())
+	require.NoError(t, err)
+	require.Len(t, resp.Result, 1)
+
+	approval := resp.Result[0]
+	assert.Equal(t, strings.ToLower(testToken.Hex()), approval.TokenAddress)
+	assert.Equal(t, 6, approval.Decimals)
+	assert.Equal(t, "1500000", approval.Balance)
+	require.Len(t, approval.ApprovedList, 1)
+
+	spender := approval.ApprovedList[0]
+	assert.Equal(t, strings.ToLower(testRouter.Hex()), spender.ApprovedContract)
+	assert.Equal(t, "Unlimited", spender.ApprovedAmount)
+	assert.NotZero(t, spender.ApprovedTime)
+	assert.NotEmpty(t, spender.InitialApprovalHash)
+}
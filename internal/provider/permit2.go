@@ -0,0 +1,180 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Permit2Address - Адрес контракта Uniswap Permit2 (одинаковый во всех сетях, CREATE2 деплой)
+const Permit2Address = "0x000000000022d473030f116ddee9f6b43ac78ba3"
+
+var (
+	permit2Contract = common.HexToAddress(Permit2Address)
+
+	// permit2ApprovalTopic - keccak256("Approval(address,address,address,uint160,uint48)")
+	permit2ApprovalTopic = crypto.Keccak256Hash([]byte("Approval(address,address,address,uint160,uint48)"))
+	// permit2PermitTopic - keccak256("Permit(address,address,address,uint160,uint48,uint48)")
+	permit2PermitTopic = crypto.Keccak256Hash([]byte("Permit(address,address,address,uint160,uint48,uint48)"))
+	// permit2LockdownTopic - keccak256("Lockdown(address,address,address)"), индексирован только owner
+	permit2LockdownTopic = crypto.Keccak256Hash([]byte("Lockdown(address,address,address)"))
+
+	// unlimitedPermit2Threshold - Allowance Permit2 (uint160) от 2^159 считается неограниченным
+	unlimitedPermit2Threshold = new(big.Int).Lsh(big.NewInt(1), 159)
+)
+
+// permit2ABI - Метод чтения allowance внутри Permit2
+const permit2ABI = `[
+	{"inputs":[{"name":"owner","type":"address"},{"name":"token","type":"address"},{"name":"spender","type":"address"}],
+	 "name":"allowance","outputs":[{"name":"amount","type":"uint160"},{"name":"expiration","type":"uint48"},{"name":"nonce","type":"uint48"}],"stateMutability":"view","type":"function"}
+]`
+
+var permit2Calls = mustParseABI(permit2ABI)
+
+// Permit2Allowance - Allowance внутри Permit2: разрешение spender списывать токен владельца
+// через Permit2 (approval второго уровня поверх ERC-20 approval на сам Permit2)
+type Permit2Allowance struct {
+	// Token - Адрес токена в нижнем регистре
+	Token string
+	// Spender - Адрес spender в нижнем регистре
+	Spender string
+	// Amount - Остаток allowance (десятичная строка) или "Unlimited"
+	Amount string
+	// Expiration - Срок действия allowance (unix timestamp)
+	Expiration int64
+	// Nonce - Nonce подписей permit для пары (токен, spender)
+	Nonce uint64
+	// UpdatedAt - Время последнего события по паре (unix timestamp)
+	UpdatedAt int64
+	// TxHash - Транзакция последнего события по паре
+	TxHash string
+}
+
+// IsExpired - Истек ли срок действия allowance к моменту now (unix timestamp)
+func (a *Permit2Allowance) IsExpired(now int64) bool {
+	return a.Expiration < now
+}
+
+// Permit2Provider - Источник allowance, выданных кошельком внутри Permit2
+type Permit2Provider interface {
+	GetPermit2Allowances(ctx context.Context, owner string) ([]Permit2Allowance, error)
+}
+
+// PackPermit2Allowance - Кодирует вызов allowance(owner, token, spender) контракта Permit2
+func PackPermit2Allowance(owner, token, spender common.Address) []byte {
+	data, _ := permit2Calls.Pack("allowance", owner, token, spender)
+	return data
+}
+
+// DecodePermit2Allowance - Декодирует результат allowance(owner, token, spender) контракта Permit2
+func DecodePermit2Allowance(data []byte) (amount *big.Int, expiration int64, nonce uint64, err error) {
+	if len(data) != 96 {
+		return nil, 0, 0, fmt.Errorf("unexpected data length: %d bytes", len(data))
+	}
+	amount = new(big.Int).SetBytes(data[:32])
+	expiration = new(big.Int).SetBytes(data[32:64]).Int64()
+	nonce = new(big.Int).SetBytes(data[64:96]).Uint64()
+	return amount, expiration, nonce, nil
+}
+
+// permit2Topics - Топики событий Permit2, меняющих allowance владельца
+func permit2Topics(owner common.Address) [][]common.Hash {
+	return [][]common.Hash{
+		{permit2ApprovalTopic, permit2PermitTopic, permit2LockdownTopic},
+		{common.BytesToHash(owner.Bytes())},
+	}
+}
+
+// reconstructPermit2Allowances - Восстанавливает allowance внутри Permit2 по событиям Approval, Permit и Lockdown.
+// Логи должны быть отсортированы по блоку и индексу - последнее событие по паре (токен, spender) перезаписывает предыдущие.
+// Нулевой expiration в событии означает "до конца текущего блока", поэтому берется время блока.
+// Allowance, израсходованные через transferFrom, событий не порождают - их остаток нужно сверять с контрактом.
+// Результат содержит ненулевые allowance, в том числе истекшие.
+func reconstructPermit2Allowances(logs []types.Log, blockTime func(uint64) int64) []Permit2Allowance {
+	type allowanceKey struct {
+		token   common.Address
+		spender common.Address
+	}
+
+	states := make(map[allowanceKey]*Permit2Allowance)
+	amounts := make(map[allowanceKey]*big.Int)
+	var order []allowanceKey
+
+	for _, l := range logs {
+		if len(l.Topics) == 0 {
+			continue
+		}
+
+		var key allowanceKey
+		var amount *big.Int
+		var expiration int64
+		var nonce uint64
+		var hasNonce bool
+
+		switch {
+		case l.Topics[0] == permit2ApprovalTopic && len(l.Topics) == 4 && len(l.Data) >= 64:
+			key = allowanceKey{token: common.BytesToAddress(l.Topics[2].Bytes()), spender: common.BytesToAddress(l.Topics[3].Bytes())}
+			amount = new(big.Int).SetBytes(l.Data[:32])
+			expiration = new(big.Int).SetBytes(l.Data[32:64]).Int64()
+		case l.Topics[0] == permit2PermitTopic && len(l.Topics) == 4 && len(l.Data) >= 96:
+			key = allowanceKey{token: common.BytesToAddress(l.Topics[2].Bytes()), spender: common.BytesToAddress(l.Topics[3].Bytes())}
+			amount = new(big.Int).SetBytes(l.Data[:32])
+			expiration = new(big.Int).SetBytes(l.Data[32:64]).Int64()
+			nonce = new(big.Int).SetBytes(l.Data[64:96]).Uint64()
+			hasNonce = true
+		case l.Topics[0] == permit2LockdownTopic && len(l.Topics) == 2 && len(l.Data) >= 64:
+			// Lockdown обнуляет allowance, token и spender не индексированы
+			key = allowanceKey{token: common.BytesToAddress(l.Data[:32]), spender: common.BytesToAddress(l.Data[32:64])}
+			amount = new(big.Int)
+		default:
+			continue
+		}
+
+		state, ok := states[key]
+		if !ok {
+			state = &Permit2Allowance{
+				Token:   strings.ToLower(key.token.Hex()),
+				Spender: strings.ToLower(key.spender.Hex()),
+			}
+			states[key] = state
+			order = append(order, key)
+		}
+
+		updatedAt := blockTime(l.BlockNumber)
+		if l.Topics[0] != permit2LockdownTopic {
+			if expiration == 0 {
+				expiration = updatedAt
+			}
+			state.Expiration = expiration
+		}
+		if hasNonce {
+			state.Nonce = nonce
+		}
+		amounts[key] = amount
+		state.Amount = formatPermit2Amount(amount)
+		state.UpdatedAt = updatedAt
+		state.TxHash = l.TxHash.Hex()
+	}
+
+	var result []Permit2Allowance
+	for _, key := range order {
+		if amounts[key].Sign() == 0 {
+			continue
+		}
+		result = append(result, *states[key])
+	}
+	return result
+}
+
+// formatPermit2Amount - Остаток allowance Permit2 в том же виде, что и ApprovedAmount у ERC-20 approvals
+func formatPermit2Amount(amount *big.Int) string {
+	if amount.Cmp(unlimitedPermit2Threshold) >= 0 {
+		return "Unlimited"
+	}
+	return amount.String()
+}
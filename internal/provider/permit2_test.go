@@ -0,0 +1,122 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	permit2Owner   = common.HexToAddress("0x742d35Cc6634C0532925a3b88650D7241EfF5cbc")
+	permit2Token   = common.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	permit2Router  = common.HexToAddress("0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad")
+	permit2Drainer = common.HexToAddress("0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220")
+	permit2Revoked = common.HexToAddress("0x1111111254fb6c44bac0bed2854e76f90643097d")
+)
+
+// permit2Event - Событие Permit2 в формате ответа getLogs
+func permit2Event(topic common.Hash, block, timestamp, index uint64, indexed []common.Address, words ...*big.Int) EventLog {
+	event := EventLog{
+		Address:         Permit2Address,
+		Topics:          []string{topic.Hex(), common.BytesToHash(permit2Owner.Bytes()).Hex()},
+		BlockNumber:     fmt.Sprintf("0x%x", block),
+		TimeStamp:       fmt.Sprintf("0x%x", timestamp),
+		LogIndex:        fmt.Sprintf("0x%x", index),
+		TransactionHash: common.BigToHash(new(big.Int).SetUint64(block*100 + index)).Hex(),
+	}
+	for _, addr := range indexed {
+		event.Topics = append(event.Topics, common.BytesToHash(addr.Bytes()).Hex())
+	}
+	var data []byte
+	for _, word := range words {
+		data = append(data, common.BigToHash(word).Bytes()...)
+	}
+	event.Data = "0x" + common.Bytes2Hex(data)
+	return event
+}
+
+func TestEtherscanClient_GetPermit2Allowances(t *testing.T) {
+	maxUint160 := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 160), big.NewInt(1))
+	addressWord := func(addr common.Address) *big.Int { return new(big.Int).SetBytes(addr.Bytes()) }
+
+	events := map[common.Hash][]EventLog{
+		permit2ApprovalTopic: {
+			// Неограниченный approve роутеру
+			permit2Event(permit2ApprovalTopic, 100, 1700000000, 1, []common.Address{permit2Token, permit2Router}, maxUint160, big.NewInt(1800000000)),
+			// approve с нулевым expiration действует только в своем блоке
+			permit2Event(permit2ApprovalTopic, 120, 1700000500, 0, []common.Address{permit2Token, permit2Revoked}, big.NewInt(500), big.NewInt(0)),
+		},
+		permit2PermitTopic: {
+			permit2Event(permit2PermitTopic, 110, 1700000300, 2, []common.Address{permit2Token, permit2Drainer}, big.NewInt(1000000), big.NewInt(1900000000), big.NewInt(3)),
+			permit2Event(permit2PermitTopic, 90, 1690000000, 0, []common.Address{permit2Token, permit2Revoked}, big.NewInt(700), big.NewInt(1900000000), big.NewInt(1)),
+		},
+		permit2LockdownTopic: {
+			// Lockdown после повторной выдачи отзывает allowance
+			permit2Event(permit2LockdownTopic, 130, 1700000900, 4, nil, addressWord(permit2Token), addressWord(permit2Revoked)),
+		},
+	}
+
+	client := newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, "logs", q.Get("module"))
+		assert.Equal(t, "getLogs", q.Get("action"))
+		assert.Equal(t, Permit2Address, q.Get("address"))
+		assert.Equal(t, common.BytesToHash(permit2Owner.Bytes()).Hex(), q.Get("topic1"))
+		assert.Equal(t, "and", q.Get("topic0_1_opr"))
+
+		result := events[common.HexToHash(q.Get("topic0"))]
+		if len(result) == 0 {
+			writeEtherscan(w, "0", "No records found", []EventLog{})
+			return
+		}
+		writeEtherscan(w, "1", "OK", result)
+	})
+
+	allowances, err := client.GetPermit2Allowances(context.Background(), permit2Owner.Hex())
+	require.NoError(t, err)
+	require.Len(t, allowances, 2)
+
+	router := allowances[0]
+	assert.Equal(t, "0x3fc91a3afd70395cd496c647d5a6cc9d4b2b7fad", router.Spender)
+	assert.Equal(t, "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48", router.Token)
+	assert.Equal(t, "Unlimited", router.Amount)
+	assert.Equal(t, int64(1800000000), router.Expiration)
+	assert.Equal(t, int64(1700000000), router.UpdatedAt)
+
+	drainer := allowances[1]
+	assert.Equal(t, "0x4ee879f39cce3c4ca80e2ee90f9df5afeeaeb220", drainer.Spender)
+	assert.Equal(t, "1000000", drainer.Amount)
+	assert.Equal(t, uint64(3), drainer.Nonce)
+	assert.False(t, drainer.IsExpired(1800000000))
+	assert.True(t, drainer.IsExpired(1900000001))
+}
+
+func TestReconstructPermit2Allowances_ZeroExpirationUsesBlockTime(t *testing.T) {
+	event := permit2Event(permit2ApprovalTopic, 5, 0, 0, []common.Address{permit2Token, permit2Router}, big.NewInt(42), big.NewInt(0))
+
+	allowances := reconstructPermit2Allowances([]types.Log{event.ToLog()}, func(uint64) int64 { return 1700000000 })
+	require.Len(t, allowances, 1)
+	assert.Equal(t, "42", allowances[0].Amount)
+	assert.Equal(t, int64(1700000000), allowances[0].Expiration)
+	assert.True(t, allowances[0].IsExpired(1700000001))
+}
+
+func TestDecodePermit2Allowance(t *testing.T) {
+	data := append(append(common.BigToHash(big.NewInt(7)).Bytes(), common.BigToHash(big.NewInt(1800000000)).Bytes()...), common.BigToHash(big.NewInt(2)).Bytes()...)
+
+	amount, expiration, nonce, err := DecodePermit2Allowance(data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), amount.Int64())
+	assert.Equal(t, int64(1800000000), expiration)
+	assert.Equal(t, uint64(2), nonce)
+
+	_, _, _, err = DecodePermit2Allowance(data[:64])
+	assert.Error(t, err)
+}
@@ -0,0 +1,158 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultPricesURL - Базовый URL DefiLlama coins API
+	defaultPricesURL = "https://coins.llama.fi"
+	// defaultPricesChain - Сеть в идентификаторах DefiLlama по умолчанию
+	defaultPricesChain = "ethereum"
+	// defaultPriceCacheTTL - Срок кэширования цен по умолчанию
+	defaultPriceCacheTTL = 5 * time.Minute
+	// pricesBatchSize - Количество монет в одном запросе (ограничение длины URL)
+	pricesBatchSize = 100
+	// NativeTokenAddress - Адрес, под которым в ответах возвращается цена нативной монеты (ETH)
+	NativeTokenAddress = "0x0000000000000000000000000000000000000000"
+	// nativeCoinID - Идентификатор ETH в DefiLlama
+	nativeCoinID = "coingecko:ethereum"
+)
+
+// PriceClient - Клиент для получения USD цен токенов (DefiLlama)
+type PriceClient struct {
+	baseURL string
+	chain   string
+	client  *http.Client
+	ttl     time.Duration
+	// priceCache - Кэш цен с коротким TTL (nil - кэш не подключен)
+	priceCache *cache.Typed[float64]
+	log        *logrus.Entry
+}
+
+// NewPriceClient - Создает новый клиент цен
+func NewPriceClient(cfg *config.Config, log *logrus.Entry) *PriceClient {
+	baseURL := strings.TrimRight(cfg.Prices.URL, "/")
+	if baseURL == "" {
+		baseURL = defaultPricesURL
+	}
+	chain := cfg.Prices.Chain
+	if chain == "" {
+		chain = defaultPricesChain
+	}
+	logger := log.WithFields(logrus.Fields{"component": "prices"})
+	return &PriceClient{
+		baseURL: baseURL,
+		chain:   chain,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		ttl: secondsOrDefault(cfg.Cache.PriceTTLSeconds, defaultPriceCacheTTL),
+		log: logger,
+	}
+}
+
+// UseCache - Подключает кэш цен
+func (c *PriceClient) UseCache(store cache.Store) {
+	c.priceCache = cache.NewTyped[float64](store, cache.NamespacePrice, c.ttl, c.log)
+}
+
+// pricesResponse - Ответ DefiLlama /prices/current
+type pricesResponse struct {
+	Coins map[string]struct {
+		Price      float64 `json:"price"`
+		Symbol     string  `json:"symbol"`
+		Decimals   int     `json:"decimals"`
+		Timestamp  int64   `json:"timestamp"`
+		Confidence float64 `json:"confidence"`
+	} `json:"coins"`
+}
+
+// GetPrices - Получает USD цены токенов. Цена ETH запрашивается по NativeTokenAddress.
+// Ключи результата - адреса в нижнем регистре; токены без цены отсутствуют.
+func (c *PriceClient) GetPrices(ctx context.Context, tokenAddresses []string) (map[string]float64, error) {
+	prices, missing := c.priceCache.GetMany(ctx, uniqueLower(tokenAddresses))
+
+	var lastErr error
+	for _, batch := range chunkStrings(missing, pricesBatchSize) {
+		fetched, err := c.fetchPrices(ctx, batch)
+		if err != nil {
+			c.log.Warnf("Failed to get prices for %d tokens: %v", len(batch), err)
+			lastErr = err
+			continue
+		}
+		for addr, price := range fetched {
+			prices[addr] = price
+			c.priceCache.Set(ctx, addr, price)
+		}
+	}
+
+	if len(prices) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+
+	c.log.Debugf("Got prices for %d of %d tokens", len(prices), len(tokenAddresses))
+	return prices, nil
+}
+
+// fetchPrices - Запрашивает цены одного батча адресов
+func (c *PriceClient) fetchPrices(ctx context.Context, addresses []string) (map[string]float64, error) {
+	coinIDs := make([]string, len(addresses))
+	byCoinID := make(map[string]string, len(addresses))
+	for i, addr := range addresses {
+		coinIDs[i] = c.coinID(addr)
+		byCoinID[coinIDs[i]] = addr
+	}
+
+	urlStr := fmt.Sprintf("%s/prices/current/%s", c.baseURL, strings.Join(coinIDs, ","))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, urlStr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("prices API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var response pricesResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, err
+	}
+
+	prices := make(map[string]float64, len(response.Coins))
+	for id, coin := range response.Coins {
+		if addr, ok := byCoinID[strings.ToLower(id)]; ok && coin.Price > 0 {
+			prices[addr] = coin.Price
+		}
+	}
+	return prices, nil
+}
+
+// coinID - Идентификатор монеты DefiLlama для адреса токена
+func (c *PriceClient) coinID(address string) string {
+	if address == NativeTokenAddress {
+		return nativeCoinID
+	}
+	return c.chain + ":" + address
+}
@@ -0,0 +1,53 @@
+package provider
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+	"alpha-hygiene-backend/internal/cache"
+	"alpha-hygiene-backend/pkg/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPriceClient_GetPrices(t *testing.T) {
+	var calls atomic.Int32
+	var lastPath atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		lastPath.Store(r.URL.Path)
+		_, _ = w.Write([]byte(`{"coins":{
+			"coingecko:ethereum":{"price":3000.5,"symbol":"ETH","timestamp":1700000000,"confidence":0.99},
+			"ethereum:0xdac17f958d2ee523a2206206994597c13d831ec7":{"decimals":6,"price":1.0,"symbol":"USDT","timestamp":1700000000,"confidence":0.99}
+		}}`))
+	}))
+	t.Cleanup(srv.Close)
+
+	log, err := logger.New("debug")
+	require.NoError(t, err)
+	cfg := &config.Config{}
+	cfg.Prices.URL = srv.URL
+
+	client := NewPriceClient(cfg, log.WithContext(context.Background()))
+	client.UseCache(cache.NewMemoryStore(cfg, client.log))
+
+	tokens := []string{NativeTokenAddress, "0xdAC17F958D2ee523a2206206994597C13D831ec7", "0x0000000000000000000000000000000000000001"}
+	prices, err := client.GetPrices(context.Background(), tokens)
+	require.NoError(t, err)
+
+	assert.Equal(t, "/prices/current/coingecko:ethereum,ethereum:0xdac17f958d2ee523a2206206994597c13d831ec7,ethereum:0x0000000000000000000000000000000000000001", lastPath.Load())
+	assert.Equal(t, map[string]float64{
+		NativeTokenAddress:                           3000.5,
+		"0xdac17f958d2ee523a2206206994597c13d831ec7": 1.0,
+	}, prices)
+
+	// Известные цены берутся из кэша, запрашивается только токен без цены
+	_, err = client.GetPrices(context.Background(), tokens[:2])
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
@@ -0,0 +1,193 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+const (
+	// safeModulesPageSize - Сколько модулей Safe читается одним getModulesPaginated
+	safeModulesPageSize = 50
+
+	// События изменения владельцев и порога Safe
+	SafeEventAddedOwner       = "added_owner"
+	SafeEventRemovedOwner     = "removed_owner"
+	SafeEventChangedThreshold = "changed_threshold"
+)
+
+var (
+	// safeSentinel - Начало связного списка модулей Safe
+	safeSentinel = common.HexToAddress("0x0000000000000000000000000000000000000001")
+	// safeGuardSlot - keccak256("guard_manager.guard.address"), слот адреса guard
+	safeGuardSlot = crypto.Keccak256Hash([]byte("guard_manager.guard.address"))
+
+	safeAddedOwnerTopic       = crypto.Keccak256Hash([]byte("AddedOwner(address)"))
+	safeRemovedOwnerTopic     = crypto.Keccak256Hash([]byte("RemovedOwner(address)"))
+	safeChangedThresholdTopic = crypto.Keccak256Hash([]byte("ChangedThreshold(uint256)"))
+)
+
+// safeABI - View методы Safe (v1.1+), которые читает аудит
+const safeABI = `[
+	{"inputs":[],"name":"getOwners","outputs":[{"name":"","type":"address[]"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"getThreshold","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"start","type":"address"},{"name":"pageSize","type":"uint256"}],"name":"getModulesPaginated",
+	 "outputs":[{"name":"array","type":"address[]"},{"name":"next","type":"address"}],"stateMutability":"view","type":"function"},
+	{"inputs":[{"name":"offset","type":"uint256"},{"name":"length","type":"uint256"}],"name":"getStorageAt","outputs":[{"name":"","type":"bytes"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"VERSION","outputs":[{"name":"","type":"string"}],"stateMutability":"view","type":"function"},
+	{"inputs":[],"name":"nonce","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}
+]`
+
+var safeCalls = mustParseABI(safeABI)
+
+// SafeState - Конфигурация Safe (Gnosis Safe) multisig
+type SafeState struct {
+	Version   string
+	Owners    []common.Address
+	Threshold uint64
+	Modules   []common.Address
+	// Guard - Контракт, проверяющий каждую транзакцию Safe (нулевой адрес - guard не задан)
+	Guard common.Address
+	Nonce uint64
+	// ModulesTruncated - Модулей больше, чем вернул один запрос getModulesPaginated
+	ModulesTruncated bool
+}
+
+// SafeOwnerChange - Изменение владельцев или порога Safe
+type SafeOwnerChange struct {
+	// Event - added_owner, removed_owner или changed_threshold
+	Event     string
+	Owner     string
+	Threshold uint64
+	Block     uint64
+	Timestamp int64
+	TxHash    string
+}
+
+// GetSafeState - Читает конфигурацию Safe одним batch: владельцев, порог, модули, guard, версию и nonce.
+// Если адрес не отвечает как Safe (EOA или другой контракт), возвращает nil без ошибки.
+func (c *MulticallClient) GetSafeState(ctx context.Context, safe common.Address) (*SafeState, error) {
+	pack := func(method string, args ...interface{}) []byte {
+		data, _ := safeCalls.Pack(method, args...)
+		return data
+	}
+	calls := []Call{
+		{Target: safe, CallData: pack("getOwners")},
+		{Target: safe, CallData: pack("getThreshold")},
+		{Target: safe, CallData: pack("getModulesPaginated", safeSentinel, big.NewInt(safeModulesPageSize))},
+		{Target: safe, CallData: pack("getStorageAt", new(big.Int).SetBytes(safeGuardSlot.Bytes()), big.NewInt(1))},
+		{Target: safe, CallData: pack("VERSION")},
+		{Target: safe, CallData: pack("nonce")},
+	}
+
+	results, err := c.Aggregate(ctx, calls)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Safe state: %w", err)
+	}
+
+	// Safe определяется по ответам getOwners и getThreshold
+	owners, err := unpackSafe[[]common.Address](results[0], "getOwners")
+	if err != nil {
+		return nil, nil
+	}
+	threshold, err := unpackSafe[*big.Int](results[1], "getThreshold")
+	if err != nil || threshold.Sign() == 0 || len(owners) == 0 {
+		return nil, nil
+	}
+
+	state := &SafeState{Owners: owners, Threshold: threshold.Uint64()}
+	if results[2].Success {
+		if out, err := safeCalls.Unpack("getModulesPaginated", results[2].Data); err == nil && len(out) == 2 {
+			state.Modules = *abi.ConvertType(out[0], new([]common.Address)).(*[]common.Address)
+			next := *abi.ConvertType(out[1], new(common.Address)).(*common.Address)
+			state.ModulesTruncated = next != safeSentinel && next != (common.Address{})
+		}
+	}
+	if guard, err := unpackSafe[[]byte](results[3], "getStorageAt"); err == nil && len(guard) == 32 {
+		state.Guard = common.BytesToAddress(guard)
+	}
+	if version, err := unpackSafe[string](results[4], "VERSION"); err == nil {
+		state.Version = version
+	}
+	if nonce, err := unpackSafe[*big.Int](results[5], "nonce"); err == nil {
+		state.Nonce = nonce.Uint64()
+	}
+
+	return state, nil
+}
+
+// unpackSafe - Декодирует единственное значение результата метода Safe
+func unpackSafe[T any](result CallResult, method string) (T, error) {
+	var zero T
+	if !result.Success || len(result.Data) == 0 {
+		return zero, fmt.Errorf("%s call failed", method)
+	}
+	out, err := safeCalls.Unpack(method, result.Data)
+	if err != nil {
+		return zero, err
+	}
+	if len(out) != 1 {
+		return zero, fmt.Errorf("unexpected %s result", method)
+	}
+	return *abi.ConvertType(out[0], new(T)).(*T), nil
+}
+
+// GetSafeOwnerChanges - Изменения владельцев и порога Safe по событиям AddedOwner, RemovedOwner и ChangedThreshold
+// (в порядке блоков). Начальная конфигурация (SafeSetup) изменением не считается.
+func (c *EtherscanClient) GetSafeOwnerChanges(ctx context.Context, safe string) ([]SafeOwnerChange, error) {
+	var changes []SafeOwnerChange
+	for _, topic := range []common.Hash{safeAddedOwnerTopic, safeRemovedOwnerTopic, safeChangedThresholdTopic} {
+		events, err := c.GetLogs(ctx, safe, 0, topic.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Safe logs: %w", err)
+		}
+		for i := range events {
+			if change, ok := parseSafeOwnerChange(&events[i]); ok {
+				changes = append(changes, change)
+			}
+		}
+	}
+
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].Block < changes[j].Block })
+	return changes, nil
+}
+
+// parseSafeOwnerChange - Разбирает событие Safe. Владелец индексирован начиная с v1.4.0, в более ранних версиях он в данных.
+func parseSafeOwnerChange(event *EventLog) (SafeOwnerChange, bool) {
+	l := event.ToLog()
+	if len(l.Topics) == 0 {
+		return SafeOwnerChange{}, false
+	}
+
+	change := SafeOwnerChange{Block: l.BlockNumber, Timestamp: event.Time(), TxHash: event.TransactionHash}
+	var word []byte
+	switch {
+	case len(l.Topics) > 1:
+		word = l.Topics[1].Bytes()
+	case len(l.Data) >= 32:
+		word = l.Data[:32]
+	default:
+		return SafeOwnerChange{}, false
+	}
+
+	switch l.Topics[0] {
+	case safeAddedOwnerTopic:
+		change.Event = SafeEventAddedOwner
+		change.Owner = strings.ToLower(common.BytesToAddress(word).Hex())
+	case safeRemovedOwnerTopic:
+		change.Event = SafeEventRemovedOwner
+		change.Owner = strings.ToLower(common.BytesToAddress(word).Hex())
+	case safeChangedThresholdTopic:
+		change.Event = SafeEventChangedThreshold
+		change.Threshold = new(big.Int).SetBytes(word).Uint64()
+	default:
+		return SafeOwnerChange{}, false
+	}
+	return change, true
+}
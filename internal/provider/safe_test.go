@@ -0,0 +1,125 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"net/http"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSafe - Backend сети без Multicall3: вызовы исполняются по одному, ответы задаются по методу Safe
+type fakeSafe struct {
+	responses map[string][]byte
+}
+
+func (f *fakeSafe) ChainID(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(31337), nil
+}
+
+func (f *fakeSafe) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	method, err := safeCalls.MethodById(msg.Data[:4])
+	if err != nil {
+		return nil, err
+	}
+	data, ok := f.responses[method.Name]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return data, nil
+}
+
+func packSafeOutput(t *testing.T, method string, values ...interface{}) []byte {
+	data, err := safeCalls.Methods[method].Outputs.Pack(values...)
+	require.NoError(t, err)
+	return data
+}
+
+func TestMulticallClient_GetSafeState(t *testing.T) {
+	owners := []common.Address{
+		common.HexToAddress("0x1111111111111111111111111111111111111111"),
+		common.HexToAddress("0x2222222222222222222222222222222222222222"),
+	}
+	module := common.HexToAddress("0xcfbfac74c26f8647cbdb8c5caf80bb5b32e43134")
+	guard := common.HexToAddress("0x3333333333333333333333333333333333333333")
+
+	backend := &fakeSafe{responses: map[string][]byte{
+		"getOwners":           packSafeOutput(t, "getOwners", owners),
+		"getThreshold":        packSafeOutput(t, "getThreshold", big.NewInt(2)),
+		"getModulesPaginated": packSafeOutput(t, "getModulesPaginated", []common.Address{module}, safeSentinel),
+		"getStorageAt":        packSafeOutput(t, "getStorageAt", common.BytesToHash(guard.Bytes()).Bytes()),
+		"VERSION":             packSafeOutput(t, "VERSION", "1.3.0"),
+		"nonce":               packSafeOutput(t, "nonce", big.NewInt(42)),
+	}}
+	client := newTestMulticall(t, backend, &config.Config{})
+
+	state, err := client.GetSafeState(context.Background(), common.HexToAddress("0x4444444444444444444444444444444444444444"))
+	require.NoError(t, err)
+	require.NotNil(t, state)
+	assert.Equal(t, owners, state.Owners)
+	assert.Equal(t, uint64(2), state.Threshold)
+	assert.Equal(t, []common.Address{module}, state.Modules)
+	assert.False(t, state.ModulesTruncated)
+	assert.Equal(t, guard, state.Guard)
+	assert.Equal(t, "1.3.0", state.Version)
+	assert.Equal(t, uint64(42), state.Nonce)
+}
+
+func TestMulticallClient_GetSafeStateNotSafe(t *testing.T) {
+	client := newTestMulticall(t, &fakeSafe{responses: map[string][]byte{}}, &config.Config{})
+
+	state, err := client.GetSafeState(context.Background(), common.HexToAddress("0x4444444444444444444444444444444444444444"))
+	require.NoError(t, err)
+	assert.Nil(t, state)
+}
+
+func TestEtherscanClient_GetSafeOwnerChanges(t *testing.T) {
+	safe := "0x4444444444444444444444444444444444444444"
+	owner := common.HexToAddress("0x5555555555555555555555555555555555555555")
+
+	events := map[common.Hash][]EventLog{
+		// Safe v1.4.1: владелец индексирован
+		safeAddedOwnerTopic: {{
+			Topics:      []string{safeAddedOwnerTopic.Hex(), common.BytesToHash(owner.Bytes()).Hex()},
+			Data:        "0x",
+			BlockNumber: "0x20", TimeStamp: "0x6553f100", TransactionHash: "0xadd",
+		}},
+		// Safe v1.3.0: владелец в данных события
+		safeRemovedOwnerTopic: {{
+			Topics:      []string{safeRemovedOwnerTopic.Hex()},
+			Data:        common.BytesToHash(owner.Bytes()).Hex(),
+			BlockNumber: "0x30", TimeStamp: "0x6553f200", TransactionHash: "0xremove",
+		}},
+		safeChangedThresholdTopic: {{
+			Topics:      []string{safeChangedThresholdTopic.Hex()},
+			Data:        common.BigToHash(big.NewInt(1)).Hex(),
+			BlockNumber: "0x10", TimeStamp: "0x6553f000", TransactionHash: "0xthreshold",
+		}},
+	}
+
+	client := newTestEtherscanClient(t, func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		assert.Equal(t, safe, q.Get("address"))
+		writeEtherscan(w, "1", "OK", events[common.HexToHash(q.Get("topic0"))])
+	})
+
+	changes, err := client.GetSafeOwnerChanges(context.Background(), safe)
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, SafeEventChangedThreshold, changes[0].Event)
+	assert.Equal(t, uint64(1), changes[0].Threshold)
+	assert.Equal(t, SafeEventAddedOwner, changes[1].Event)
+	assert.Equal(t, "0x5555555555555555555555555555555555555555", changes[1].Owner)
+	assert.Equal(t, int64(0x6553f100), changes[1].Timestamp)
+	assert.Equal(t, SafeEventRemovedOwner, changes[2].Event)
+	assert.Equal(t, "0x5555555555555555555555555555555555555555", changes[2].Owner)
+	assert.Equal(t, "0xremove", changes[2].TxHash)
+}
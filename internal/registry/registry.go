@@ -0,0 +1,328 @@
+package registry
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+// List - Тип списка: разрешенные или заблокированные адреса
+type List string
+
+const (
+	ListAllow List = "allow"
+	ListBlock List = "block"
+)
+
+// Category - Категория адреса в реестре
+type Category string
+
+const (
+	CategoryStablecoin     Category = "stablecoin"
+	CategoryInfrastructure Category = "infrastructure"
+	CategoryDexRouter      Category = "dex_router"
+	CategoryNFTMarketplace Category = "nft_marketplace"
+	// CategoryAccountDelegate - Реализации смарт-аккаунтов, на которые EOA делегирует код (EIP-7702)
+	CategoryAccountDelegate Category = "account_delegate"
+	// CategorySafeModule - Проверенные модули и guard контракты Safe multisig
+	CategorySafeModule Category = "safe_module"
+	CategoryCustom     Category = "custom"
+	CategoryDrainer    Category = "drainer"
+	CategoryPhishing   Category = "phishing"
+	CategoryHoneypot   Category = "honeypot"
+	CategoryScam       Category = "scam"
+	// CategorySanctioned - Адреса из санкционных списков (OFAC SDN и др.)
+	CategorySanctioned Category = "sanctioned"
+	// CategoryMixer - Пулы и роутеры миксеров (Tornado Cash и др.)
+	CategoryMixer Category = "mixer"
+)
+
+const (
+	// builtinSource - Имя встроенного источника
+	builtinSource = "builtin"
+	// configSource - Имя источника для whitelist.contracts из конфигурации
+	configSource = "config"
+)
+
+//go:embed builtin.yaml
+var builtinFile []byte
+
+// addressPattern - EVM адрес
+var addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+
+// Entry - Запись реестра
+type Entry struct {
+	Address   string   `json:"address" yaml:"address"`
+	List      List     `json:"list" yaml:"list"`
+	Category  Category `json:"category" yaml:"category"`
+	Label     string   `json:"label,omitempty" yaml:"label,omitempty"`
+	Reference string   `json:"reference,omitempty" yaml:"reference,omitempty"`
+	// Source - Имя файла реестра, из которого взята запись
+	Source string `json:"source" yaml:"-"`
+}
+
+// File - Версионированный файл реестра (YAML или JSON)
+type File struct {
+	Version int     `json:"version" yaml:"version"`
+	Name    string  `json:"name" yaml:"name"`
+	Entries []Entry `json:"entries" yaml:"entries"`
+}
+
+// SourceInfo - Загруженный источник реестра
+type SourceInfo struct {
+	Name    string `json:"name"`
+	Path    string `json:"path,omitempty"`
+	Version int    `json:"version"`
+	Entries int    `json:"entries"`
+}
+
+// snapshot - Неизменяемое состояние реестра; заменяется целиком при перезагрузке
+type snapshot struct {
+	entries map[string]Entry
+	sources []SourceInfo
+}
+
+// Registry - Реестр доверенных и заблокированных адресов.
+// Источники объединяются по порядку: встроенный список, файлы из конфигурации, whitelist.contracts.
+// Для одного адреса более поздний источник заменяет запись, но разрешение не перекрывает блокировку.
+type Registry struct {
+	files []string
+	// extra - Адреса whitelist.contracts из конфигурации
+	extra   []string
+	current atomic.Pointer[snapshot]
+	// mu - Сериализует перезагрузки
+	mu       sync.Mutex
+	modTimes map[string]time.Time
+	log      *logrus.Entry
+}
+
+// New - Создает реестр и загружает источники из конфигурации
+func New(cfg *config.Config, log *logrus.Entry) (*Registry, error) {
+	r := &Registry{
+		files:    cfg.Registry.Files,
+		extra:    cfg.WhitelistContracts(),
+		modTimes: make(map[string]time.Time),
+		log:      log.WithFields(logrus.Fields{"component": "registry"}),
+	}
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// SetExtra - Заменяет адреса whitelist.contracts (применяется при следующем Reload)
+func (r *Registry) SetExtra(addresses []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.extra = append([]string(nil), addresses...)
+}
+
+// Reload - Перечитывает все источники. При ошибке остается предыдущее состояние.
+func (r *Registry) Reload() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	builtin, err := parseFile(builtinFile, ".yaml")
+	if err != nil {
+		return fmt.Errorf("invalid builtin registry: %w", err)
+	}
+
+	next := &snapshot{entries: make(map[string]Entry)}
+	next.merge(builtinSource, "", builtin)
+
+	modTimes := make(map[string]time.Time, len(r.files))
+	for _, path := range r.files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read registry %s: %w", path, err)
+		}
+		file, err := parseFile(data, filepath.Ext(path))
+		if err != nil {
+			return fmt.Errorf("invalid registry %s: %w", path, err)
+		}
+		name := file.Name
+		if name == "" {
+			name = filepath.Base(path)
+		}
+		next.merge(name, path, file)
+		if info, err := os.Stat(path); err == nil {
+			modTimes[path] = info.ModTime()
+		}
+	}
+
+	extra := &File{Version: 1}
+	for _, addr := range r.extra {
+		extra.Entries = append(extra.Entries, Entry{Address: addr, List: ListAllow, Category: CategoryCustom})
+	}
+	if err := extra.validate(); err != nil {
+		return fmt.Errorf("invalid whitelist.contracts: %w", err)
+	}
+	next.merge(configSource, "", extra)
+
+	r.current.Store(next)
+	r.modTimes = modTimes
+	r.log.Infof("Registry loaded: %d addresses from %d sources", len(next.entries), len(next.sources))
+	return nil
+}
+
+// Run - Перечитывает реестр при изменении файлов до отмены контекста
+func (r *Registry) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 || len(r.files) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if r.filesChanged() {
+				if err := r.Reload(); err != nil {
+					r.log.Errorf("Registry reload failed, keeping current registry: %v", err)
+				}
+			}
+		}
+	}
+}
+
+// Lookup - Запись реестра для адреса
+func (r *Registry) Lookup(address string) (Entry, bool) {
+	if r == nil {
+		return Entry{}, false
+	}
+	entry, ok := r.current.Load().entries[strings.ToLower(address)]
+	return entry, ok
+}
+
+// IsAllowed - Адрес в списке доверенных
+func (r *Registry) IsAllowed(address string) bool {
+	entry, ok := r.Lookup(address)
+	return ok && entry.List == ListAllow
+}
+
+// IsBlocked - Адрес в списке заблокированных
+func (r *Registry) IsBlocked(address string) bool {
+	entry, ok := r.Lookup(address)
+	return ok && entry.List == ListBlock
+}
+
+// HasCategory - Адрес относится к одной из категорий
+func (r *Registry) HasCategory(address string, categories ...Category) bool {
+	entry, ok := r.Lookup(address)
+	if !ok {
+		return false
+	}
+	for _, category := range categories {
+		if entry.Category == category {
+			return true
+		}
+	}
+	return false
+}
+
+// Entries - Записи реестра указанных категорий
+func (r *Registry) Entries(categories ...Category) []Entry {
+	if r == nil {
+		return nil
+	}
+	var entries []Entry
+	for _, entry := range r.current.Load().entries {
+		for _, category := range categories {
+			if entry.Category == category {
+				entries = append(entries, entry)
+				break
+			}
+		}
+	}
+	return entries
+}
+
+// Sources - Загруженные источники с версиями
+func (r *Registry) Sources() []SourceInfo {
+	if r == nil {
+		return nil
+	}
+	return append([]SourceInfo(nil), r.current.Load().sources...)
+}
+
+// merge - Добавляет записи источника поверх уже загруженных
+func (s *snapshot) merge(name, path string, file *File) {
+	for _, entry := range file.Entries {
+		entry.Address = strings.ToLower(entry.Address)
+		entry.Source = name
+		if existing, ok := s.entries[entry.Address]; ok && existing.List == ListBlock && entry.List == ListAllow {
+			continue
+		}
+		s.entries[entry.Address] = entry
+	}
+	s.sources = append(s.sources, SourceInfo{Name: name, Path: path, Version: file.Version, Entries: len(file.Entries)})
+}
+
+// filesChanged - Изменился ли какой-либо файл реестра
+func (r *Registry) filesChanged() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, path := range r.files {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.ModTime().Equal(r.modTimes[path]) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFile - Разбирает и проверяет файл реестра (.json - JSON, иначе YAML)
+func parseFile(data []byte, ext string) (*File, error) {
+	var file File
+	var err error
+	if strings.EqualFold(ext, ".json") {
+		err = json.Unmarshal(data, &file)
+	} else {
+		err = yaml.Unmarshal(data, &file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if file.Version <= 0 {
+		return nil, fmt.Errorf("version must be > 0")
+	}
+	if err := file.validate(); err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// validate - Проверяет записи файла
+func (f *File) validate() error {
+	for i, entry := range f.Entries {
+		if !addressPattern.MatchString(entry.Address) {
+			return fmt.Errorf("entry %d: invalid address %q", i, entry.Address)
+		}
+		if entry.List != ListAllow && entry.List != ListBlock {
+			return fmt.Errorf("entry %d (%s): list must be allow or block", i, entry.Address)
+		}
+		if entry.Category == "" {
+			return fmt.Errorf("entry %d (%s): category is required", i, entry.Address)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"alpha-hygiene-backend/config"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+}
+
+func newTestRegistry(t *testing.T, files []string, whitelist []string) *Registry {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Registry.Files = files
+	cfg.Whitelist.Contracts = whitelist
+	reg, err := New(cfg, logrus.NewEntry(logrus.New()))
+	require.NoError(t, err)
+	return reg
+}
+
+func TestRegistry_BuiltinLookupIsCaseInsensitive(t *testing.T) {
+	reg := newTestRegistry(t, nil, nil)
+
+	// Адреса со смешанным регистром (раньше не находились в белом списке)
+	assert.True(t, reg.HasCategory("0x1456688345527bE1f37E9e627DA0837D6f08C925", CategoryStablecoin))
+	assert.True(t, reg.HasCategory("0x57Ab1ec28D129707052df4dF418D58a2D46d5f51", CategoryStablecoin))
+	assert.True(t, reg.HasCategory("0x0000000000085d4780B73119b644AE5ecd22b376", CategoryStablecoin))
+
+	// DEX роутер доверенный, но не стейблкоин
+	assert.True(t, reg.IsAllowed("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"))
+	assert.False(t, reg.HasCategory("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", CategoryStablecoin))
+
+	assert.True(t, reg.IsBlocked("0xAFF8ED5415B68AB81786200E3BFD74D7C37DF31E"))
+	assert.False(t, reg.IsAllowed("0xAFF8ED5415B68AB81786200E3BFD74D7C37DF31E"))
+
+	assert.Len(t, reg.Entries(CategoryStablecoin), 7)
+	assert.Len(t, reg.Entries(CategoryStablecoin, CategoryInfrastructure), 9)
+}
+
+func TestRegistry_MergesFilesAndWhitelist(t *testing.T) {
+	dir := t.TempDir()
+	yamlPath := filepath.Join(dir, "team.yaml")
+	jsonPath := filepath.Join(dir, "feed.json")
+
+	writeFile(t, yamlPath, `
+version: 3
+name: team
+entries:
+  - { address: "0x1111111111111111111111111111111111111111", list: block, category: drainer, label: "Drainer" }
+  - { address: "0x2222222222222222222222222222222222222222", list: allow, category: custom }
+`)
+	writeFile(t, jsonPath, `{"version": 2, "entries": [
+  {"address": "0x1111111111111111111111111111111111111111", "list": "allow", "category": "custom"},
+  {"address": "0x2222222222222222222222222222222222222222", "list": "block", "category": "phishing"}
+]}`)
+
+	reg := newTestRegistry(t, []string{yamlPath, jsonPath}, []string{"0x3333333333333333333333333333333333333333"})
+
+	// Разрешение не перекрывает блокировку
+	entry, ok := reg.Lookup("0x1111111111111111111111111111111111111111")
+	require.True(t, ok)
+	assert.Equal(t, ListBlock, entry.List)
+	assert.Equal(t, "team", entry.Source)
+
+	// Блокировка перекрывает разрешение
+	entry, ok = reg.Lookup("0x2222222222222222222222222222222222222222")
+	require.True(t, ok)
+	assert.Equal(t, ListBlock, entry.List)
+	assert.Equal(t, "feed.json", entry.Source)
+
+	assert.True(t, reg.HasCategory("0x3333333333333333333333333333333333333333", CategoryCustom))
+
+	sources := reg.Sources()
+	require.Len(t, sources, 4)
+	assert.Equal(t, "builtin", sources[0].Name)
+	assert.Equal(t, 3, sources[1].Version)
+	assert.Equal(t, 2, sources[2].Version)
+	assert.Equal(t, "config", sources[3].Name)
+}
+
+func TestRegistry_ReloadKeepsStateOnInvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "registry.yaml")
+	writeFile(t, path, "version: 1\nentries: []\n")
+	reg := newTestRegistry(t, []string{path}, nil)
+
+	addr := "0x4444444444444444444444444444444444444444"
+	assert.False(t, reg.IsBlocked(addr))
+
+	writeFile(t, path, `
+version: 2
+entries:
+  - { address: "0x4444444444444444444444444444444444444444", list: block, category: scam }
+`)
+	require.NoError(t, reg.Reload())
+	assert.True(t, reg.IsBlocked(addr))
+
+	writeFile(t, path, "version: 3\nentries:\n  - { address: \"0x44\", list: block, category: scam }\n")
+	assert.Error(t, reg.Reload())
+	assert.True(t, reg.IsBlocked(addr))
+
+	writeFile(t, path, "entries: []\n")
+	assert.ErrorContains(t, reg.Reload(), "version")
+
+	writeFile(t, path, "version: 4\nentries: []\n")
+	reg.SetExtra([]string{"not-an-address"})
+	assert.ErrorContains(t, reg.Reload(), "whitelist.contracts")
+	assert.True(t, reg.IsBlocked(addr))
+
+	reg.SetExtra(nil)
+	require.NoError(t, reg.Reload())
+	assert.False(t, reg.IsBlocked(addr))
+}
+
+func TestRegistry_NilIsSafe(t *testing.T) {
+	var reg *Registry
+	assert.False(t, reg.IsAllowed("0xdac17f958d2ee523a2206206994597c13d831ec7"))
+	assert.False(t, reg.IsBlocked("0xdac17f958d2ee523a2206206994597c13d831ec7"))
+	assert.False(t, reg.HasCategory("0xdac17f958d2ee523a2206206994597c13d831ec7", CategoryStablecoin))
+	assert.Nil(t, reg.Sources())
+	assert.Nil(t, reg.Entries(CategoryStablecoin))
+}
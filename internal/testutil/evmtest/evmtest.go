@@ -0,0 +1,143 @@
+// Package evmtest - Вспомогательные средства для тестов поверх simulated backend go-ethereum.
+//
+// Вместо скомпилированных контрактов используется один крошечный контракт MockContract:
+//   - вызов с calldata ровно 128 байт (t0, t1, t2, data) порождает событие LOG3(t0, t1, t2) с данными data;
+//   - любой другой вызов возвращает слово из storage по ключу keccak256(calldata),
+//     поэтому ответы view методов задаются заранее через StorageKey в genesis alloc.
+//
+// Для методов, возвращающих несколько слов (кортежи), используется MockTupleContract и SetReturn.
+package evmtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// MockContract - Runtime байткод тестового контракта (см. описание пакета)
+var MockContract = common.FromHex(
+	"0x36608014601a57" + // CALLDATASIZE == 128 ? jump emit
+		"3660006000373660002054" + // CALLDATACOPY(0, 0, size); SLOAD(SHA3(0, size))
+		"60005260206000f3" + // MSTORE(0); RETURN(0, 32)
+		"5b60603560005260403560203560003560206000a300", // emit: LOG3(0, 32, t0, t1, t2); STOP
+)
+
+// MockTupleContract - Runtime байткод контракта, возвращающего несколько слов: по ключу keccak256(calldata)
+// хранится число слов n, сами слова - по ключам key+1..key+n (см. SetReturn). Без заданного ответа возвращает пустые данные.
+var MockTupleContract = common.FromHex(
+	"0x3660006000373660002080546000" + // CALLDATACOPY(0, 0, size); key = SHA3(0, size); n = SLOAD(key); i = 0
+		"5b81811015602857806001018301548160200252600101600e56" + // loop: while i < n { MSTORE(32*i, SLOAD(key+i+1)); i++ }
+		"5b6020026000f3", // RETURN(0, 32*n)
+)
+
+// Chain - Simulated блокчейн с профинансированным аккаунтом для отправки транзакций
+type Chain struct {
+	Backend *simulated.Backend
+	Client  simulated.Client
+	Sender  common.Address
+	key     *ecdsa.PrivateKey
+	t       *testing.T
+}
+
+// NewChain - Создает simulated блокчейн с заданными аккаунтами и контрактами
+func NewChain(t *testing.T, alloc types.GenesisAlloc) *Chain {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	sender := crypto.PubkeyToAddress(key.PublicKey)
+
+	if alloc == nil {
+		alloc = types.GenesisAlloc{}
+	}
+	alloc[sender] = types.Account{Balance: new(big.Int).Mul(big.NewInt(1000), big.NewInt(1e18))}
+
+	backend := simulated.NewBackend(alloc)
+	t.Cleanup(func() { _ = backend.Close() })
+
+	return &Chain{
+		Backend: backend,
+		Client:  backend.Client(),
+		Sender:  sender,
+		key:     key,
+		t:       t,
+	}
+}
+
+// StorageKey - Ключ storage MockContract, под которым хранится ответ на данный calldata
+func StorageKey(calldata []byte) common.Hash {
+	return crypto.Keccak256Hash(calldata)
+}
+
+// SetReturn - Записывает в storage MockTupleContract ответ из нескольких слов на данный calldata
+func SetReturn(storage map[common.Hash]common.Hash, calldata []byte, words ...common.Hash) {
+	key := StorageKey(calldata).Big()
+	storage[common.BigToHash(key)] = Word(big.NewInt(int64(len(words))))
+	for i, word := range words {
+		storage[common.BigToHash(new(big.Int).Add(key, big.NewInt(int64(i+1))))] = word
+	}
+}
+
+// Word - Кодирует uint256 в 32-байтное слово
+func Word(v *big.Int) common.Hash {
+	return common.BigToHash(v)
+}
+
+// AddressTopic - Индексированный адрес в виде топика события
+func AddressTopic(addr common.Address) common.Hash {
+	return common.BytesToHash(addr.Bytes())
+}
+
+// Emit - Отправляет транзакцию в MockContract, порождающую событие с тремя топиками, и майнит блок
+func (c *Chain) Emit(contract common.Address, t0, t1, t2 common.Hash, data common.Hash) {
+	c.t.Helper()
+
+	calldata := make([]byte, 0, 128)
+	calldata = append(calldata, t0.Bytes()...)
+	calldata = append(calldata, t1.Bytes()...)
+	calldata = append(calldata, t2.Bytes()...)
+	calldata = append(calldata, data.Bytes()...)
+
+	c.SendTx(contract, calldata)
+	c.Backend.Commit()
+}
+
+// SendTx - Отправляет транзакцию от Sender без майнинга блока
+func (c *Chain) SendTx(to common.Address, data []byte) {
+	c.t.Helper()
+	ctx := context.Background()
+
+	chainID, err := c.Client.ChainID(ctx)
+	if err != nil {
+		c.t.Fatalf("failed to get chain id: %v", err)
+	}
+	nonce, err := c.Client.PendingNonceAt(ctx, c.Sender)
+	if err != nil {
+		c.t.Fatalf("failed to get nonce: %v", err)
+	}
+
+	tx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(100e9),
+		Gas:       200000,
+		To:        &to,
+		Data:      data,
+	})
+	signed, err := types.SignTx(tx, types.LatestSignerForChainID(chainID), c.key)
+	if err != nil {
+		c.t.Fatalf("failed to sign tx: %v", err)
+	}
+	if err := c.Client.SendTransaction(ctx, signed); err != nil {
+		c.t.Fatalf("failed to send tx: %v", err)
+	}
+}
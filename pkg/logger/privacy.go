@@ -0,0 +1,145 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Режимы обработки адресов и хэшей транзакций в логах
+const (
+	// PrivacyOff - Логи пишутся как есть
+	PrivacyOff = "off"
+	// PrivacyRedact - Адреса заменяются на 0xUserWallet, хэши транзакций - на 0xTxHash
+	PrivacyRedact = "redact"
+	// PrivacyHash - Адреса и хэши заменяются на HMAC-псевдонимы: один адрес - один псевдоним,
+	// поэтому записи одного кошелька можно связать, не раскрывая сам адрес
+	PrivacyHash = "hash"
+)
+
+const (
+	redactedAddress = "0xUserWallet"
+	redactedTxHash  = "0xTxHash"
+	// pseudonymBytes - Длина псевдонима (байт HMAC)
+	pseudonymBytes = 6
+)
+
+// sensitivePattern - Хэш транзакции (32 байта) или EVM адрес (20 байт)
+var sensitivePattern = regexp.MustCompile(`\b0x(?:[0-9a-fA-F]{64}|[0-9a-fA-F]{40})\b`)
+
+// PrivacyPolicy - Настройки обработки адресов в логах
+type PrivacyPolicy struct {
+	// Mode - PrivacyOff, PrivacyRedact или PrivacyHash
+	Mode string
+	// Secret - Ключ HMAC для PrivacyHash
+	Secret string
+	// Allowed - Адреса, которые можно писать как есть (известные контракты)
+	Allowed func(address string) bool
+}
+
+// PrivacyHook - Logrus hook, убирающий адреса кошельков и хэши транзакций
+// из сообщения и строковых полей записи
+type PrivacyHook struct {
+	policy PrivacyPolicy
+}
+
+// NewPrivacyHook - Создает hook для политики
+func NewPrivacyHook(policy PrivacyPolicy) (*PrivacyHook, error) {
+	switch policy.Mode {
+	case PrivacyOff, PrivacyRedact:
+	case PrivacyHash:
+		if policy.Secret == "" {
+			return nil, fmt.Errorf("privacy mode %q requires a secret", PrivacyHash)
+		}
+	default:
+		return nil, fmt.Errorf("unknown privacy mode: %q", policy.Mode)
+	}
+	return &PrivacyHook{policy: policy}, nil
+}
+
+// UsePrivacy - Подключает hook к логгеру (для PrivacyOff ничего не делает)
+func UsePrivacy(log *logrus.Logger, policy PrivacyPolicy) (*PrivacyHook, error) {
+	hook, err := NewPrivacyHook(policy)
+	if err != nil {
+		return nil, err
+	}
+	if policy.Mode != PrivacyOff {
+		log.AddHook(hook)
+	}
+	return hook, nil
+}
+
+// Levels - Hook применяется ко всем уровням
+func (h *PrivacyHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire - Обрабатывает сообщение и поля записи (logrus передает hook копию записи)
+func (h *PrivacyHook) Fire(entry *logrus.Entry) error {
+	entry.Message = h.Sanitize(entry.Message)
+	for key, value := range entry.Data {
+		if sanitized, changed := h.sanitizeValue(value); changed {
+			entry.Data[key] = sanitized
+		}
+	}
+	return nil
+}
+
+// Sanitize - Заменяет адреса и хэши транзакций в строке согласно политике
+func (h *PrivacyHook) Sanitize(s string) string {
+	if h.policy.Mode == PrivacyOff || !strings.Contains(s, "0x") {
+		return s
+	}
+	return sensitivePattern.ReplaceAllStringFunc(s, h.replace)
+}
+
+// sanitizeValue - Обрабатывает значение поля; нестроковые значения (кроме ошибок) не меняются
+func (h *PrivacyHook) sanitizeValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		sanitized := h.Sanitize(v)
+		return sanitized, sanitized != v
+	case error:
+		msg := v.Error()
+		sanitized := h.Sanitize(msg)
+		return sanitized, sanitized != msg
+	case []string:
+		out := make([]string, len(v))
+		changed := false
+		for i, s := range v {
+			out[i] = h.Sanitize(s)
+			changed = changed || out[i] != s
+		}
+		return out, changed
+	default:
+		return value, false
+	}
+}
+
+// replace - Замена одного найденного адреса или хэша
+func (h *PrivacyHook) replace(match string) string {
+	isAddress := len(match) == 42
+	if isAddress && h.policy.Allowed != nil && h.policy.Allowed(match) {
+		return match
+	}
+
+	if h.policy.Mode == PrivacyHash {
+		mac := hmac.New(sha256.New, []byte(h.policy.Secret))
+		mac.Write([]byte(strings.ToLower(match)))
+		pseudonym := hex.EncodeToString(mac.Sum(nil)[:pseudonymBytes])
+		if isAddress {
+			return "wallet:" + pseudonym
+		}
+		return "tx:" + pseudonym
+	}
+
+	if isAddress {
+		return redactedAddress
+	}
+	return redactedTxHash
+}
@@ -0,0 +1,75 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testWallet = "0x742d35Cc6634C0532925a3b88650D7241EfF5cbc"
+	testUSDT   = "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	testTxHash = "0x88df016429689c079f3b2f6ad39fa052532c56795b733da78a91ebe6a713944b"
+)
+
+func captureLog(t *testing.T, policy PrivacyPolicy, write func(log *logrus.Logger)) map[string]interface{} {
+	log, err := New("debug")
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	_, err = UsePrivacy(log, policy)
+	require.NoError(t, err)
+
+	write(log)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	return record
+}
+
+func TestPrivacyHook_RedactsMessageAndFields(t *testing.T) {
+	policy := PrivacyPolicy{
+		Mode:    PrivacyRedact,
+		Allowed: func(address string) bool { return strings.EqualFold(address, testUSDT) },
+	}
+
+	record := captureLog(t, policy, func(log *logrus.Logger) {
+		log.WithFields(logrus.Fields{
+			"address": testWallet,
+			"error":   errors.New("failed tx " + testTxHash),
+			"blocks":  uint64(42),
+		}).Infof("Wallet %s approved %s", testWallet, testUSDT)
+	})
+
+	assert.Equal(t, "Wallet 0xUserWallet approved "+testUSDT, record["msg"])
+	assert.Equal(t, "0xUserWallet", record["address"])
+	assert.Equal(t, "failed tx 0xTxHash", record["error"])
+	assert.Equal(t, 42.0, record["blocks"])
+}
+
+func TestPrivacyHook_HashesWithStablePseudonyms(t *testing.T) {
+	_, err := NewPrivacyHook(PrivacyPolicy{Mode: PrivacyHash})
+	assert.Error(t, err)
+
+	hook, err := NewPrivacyHook(PrivacyPolicy{Mode: PrivacyHash, Secret: "secret"})
+	require.NoError(t, err)
+
+	pseudonym := hook.Sanitize(testWallet)
+	assert.True(t, strings.HasPrefix(pseudonym, "wallet:"))
+	assert.Equal(t, pseudonym, hook.Sanitize(strings.ToLower(testWallet)))
+	assert.NotContains(t, hook.Sanitize("tx "+testTxHash), testTxHash[2:])
+
+	other, err := NewPrivacyHook(PrivacyPolicy{Mode: PrivacyHash, Secret: "other"})
+	require.NoError(t, err)
+	assert.NotEqual(t, pseudonym, other.Sanitize(testWallet))
+
+	// Длинные hex строки (calldata) не являются адресами
+	calldata := "0x" + strings.Repeat("ab", 36)
+	assert.Equal(t, calldata, hook.Sanitize(calldata))
+}